@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"netschool-proxy/api/api/internal/config"
+	"netschool-proxy/api/api/internal/infrastructure/database"
+	"netschool-proxy/api/api/internal/infrastructure/database/migrations"
+)
+
+var (
+	configFile = flag.String("config", "config/dev.yaml", "Path to config file")
+	steps      = flag.Int("steps", 1, "Number of migrations rolled back by down")
+)
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: migrate [flags] up|down|status|version\n\n")
+	flag.PrintDefaults()
+}
+
+
+
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadConfig(*configFile)
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	dbManager := database.NewConnectionManager(database.DatabaseConfig{
+		Type:       cfg.Database.Type,
+		Host:       cfg.Database.Host,
+		Port:       cfg.Database.Port,
+		Name:       cfg.Database.Name,
+		User:       cfg.Database.User,
+		Password:   cfg.Database.Password,
+		SSLMode:    cfg.Database.SSLMode,
+		URL:        cfg.Database.URL,
+		SQLitePath: cfg.Database.SQLitePath,
+	})
+	db, err := dbManager.Connect()
+	if err != nil {
+		fmt.Printf("Failed to connect to database: %v\n", err)
+		os.Exit(1)
+	}
+
+	migrator, err := migrations.NewMigrator(db, cfg.Database.Type)
+	if err != nil {
+		fmt.Printf("Failed to load migrations: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	switch command := flag.Arg(0); command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("Applied %03d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Printf("Migration failed: %v\n", err)
+			os.Exit(1)
+		}
+		if len(applied) == 0 {
+			fmt.Println("Database is up to date")
+		}
+	case "down":
+		rolledBack, err := migrator.Down(ctx, *steps)
+		for _, migration := range rolledBack {
+			fmt.Printf("Rolled back %03d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Printf("Rollback failed: %v\n", err)
+			os.Exit(1)
+		}
+		if len(rolledBack) == 0 {
+			fmt.Println("No migrations to roll back")
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			fmt.Printf("Failed to read migration status: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%-8s %-8s %s\n", "Version", "Applied", "Name")
+		for _, status := range statuses {
+			applied := "no"
+			if status.Applied {
+				applied = "yes"
+			}
+			fmt.Printf("%-8s %-8s %s\n", fmt.Sprintf("%03d", status.Version), applied, status.Name)
+		}
+	case "version":
+		version, err := migrator.Version(ctx)
+		if err != nil {
+			fmt.Printf("Failed to read migration version: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%d\n", version)
+	default:
+		fmt.Printf("Unknown command %q\n", command)
+		usage()
+		os.Exit(2)
+	}
+}
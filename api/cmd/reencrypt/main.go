@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"netschool-proxy/api/api/internal/config"
+	"netschool-proxy/api/api/internal/infrastructure/database"
+	"netschool-proxy/api/api/internal/pkg/security"
+)
+
+var (
+	configFile = flag.String("config", "config/dev.yaml", "Path to config file")
+	batchSize  = flag.Int("batch", 500, "Number of sessions processed per batch")
+	dryRun     = flag.Bool("dry-run", false, "Only count sessions that need re-encryption")
+)
+
+
+
+
+func main() {
+	flag.Parse()
+
+	cfg, err := config.LoadConfig(*configFile)
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !cfg.Encryption.Enabled() {
+		fmt.Println("Encryption is not configured: set encryption.active_key_id and encryption keys")
+		os.Exit(1)
+	}
+
+	keys, err := security.LoadKeys(cfg.Encryption.Keys, cfg.Encryption.KeyFile)
+	if err != nil {
+		fmt.Printf("Failed to load encryption keys: %v\n", err)
+		os.Exit(1)
+	}
+
+	tokenCipher, err := security.NewTokenCipher(cfg.Encryption.ActiveKeyID, keys)
+	if err != nil {
+		fmt.Printf("Failed to initialize token encryption: %v\n", err)
+		os.Exit(1)
+	}
+
+	dbManager := database.NewConnectionManager(database.DatabaseConfig{
+		Type:       cfg.Database.Type,
+		Host:       cfg.Database.Host,
+		Port:       cfg.Database.Port,
+		Name:       cfg.Database.Name,
+		User:       cfg.Database.User,
+		Password:   cfg.Database.Password,
+		SSLMode:    cfg.Database.SSLMode,
+		URL:        cfg.Database.URL,
+		SQLitePath: cfg.Database.SQLitePath,
+	})
+	db, err := dbManager.Connect()
+	if err != nil {
+		fmt.Printf("Failed to connect to database: %v\n", err)
+		os.Exit(1)
+	}
+
+	updated, err := database.ReencryptSessionTokens(context.Background(), db, tokenCipher, *batchSize, *dryRun)
+	if err != nil {
+		fmt.Printf("Re-encryption stopped after %d sessions: %v\n", updated, err)
+		os.Exit(1)
+	}
+
+	if *dryRun {
+		fmt.Printf("%d sessions need re-encryption with key %s\n", updated, tokenCipher.ActiveKeyID())
+		return
+	}
+	fmt.Printf("Re-encrypted %d sessions with key %s\n", updated, tokenCipher.ActiveKeyID())
+}
@@ -0,0 +1,178 @@
+package api_types
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+
+
+
+type capabilityClient struct {
+	APIClientInterface
+	provider *Provider
+}
+
+func newCapabilityClient(client APIClientInterface, provider *Provider) *capabilityClient {
+	return &capabilityClient{APIClientInterface: client, provider: provider}
+}
+
+
+func (c *capabilityClient) Unwrap() APIClientInterface {
+	return c.APIClientInterface
+}
+
+func (c *capabilityClient) require(capability Capability) error {
+	if c.provider.Supports(capability) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s does not support %s", ErrCapabilityNotSupported, c.provider.Mode, capability)
+}
+
+func (c *capabilityClient) GetStudentInfo(ctx context.Context, userID, instanceURL string) (*MySettings, error) {
+	if err := c.require(CapabilityStudentInfo); err != nil {
+		return nil, err
+	}
+	return c.APIClientInterface.GetStudentInfo(ctx, userID, instanceURL)
+}
+
+func (c *capabilityClient) GetStudents(ctx context.Context, userID, instanceURL string) (*StudentList, error) {
+	if err := c.require(CapabilityStudents); err != nil {
+		return nil, err
+	}
+	return c.APIClientInterface.GetStudents(ctx, userID, instanceURL)
+}
+
+func (c *capabilityClient) GetGrades(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time) ([]Grade, error) {
+	if err := c.require(CapabilityGrades); err != nil {
+		return nil, err
+	}
+	return c.APIClientInterface.GetGrades(ctx, userID, studentID, instanceURL, start, end)
+}
+
+func (c *capabilityClient) GetSchedule(ctx context.Context, userID, studentID, instanceURL string, weekStart time.Time) (*Diary, error) {
+	if err := c.require(CapabilitySchedule); err != nil {
+		return nil, err
+	}
+	return c.APIClientInterface.GetSchedule(ctx, userID, studentID, instanceURL, weekStart)
+}
+
+func (c *capabilityClient) GetSchoolInfo(ctx context.Context, userID string, schoolID int, instanceURL string) (*SchoolInfo, error) {
+	if err := c.require(CapabilitySchoolInfo); err != nil {
+		return nil, err
+	}
+	return c.APIClientInterface.GetSchoolInfo(ctx, userID, schoolID, instanceURL)
+}
+
+func (c *capabilityClient) GetClasses(ctx context.Context, userID, instanceURL string) ([]Class, error) {
+	if err := c.require(CapabilityClasses); err != nil {
+		return nil, err
+	}
+	return c.APIClientInterface.GetClasses(ctx, userID, instanceURL)
+}
+
+func (c *capabilityClient) GetDiary(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time) (*Diary, error) {
+	if err := c.require(CapabilityDiary); err != nil {
+		return nil, err
+	}
+	return c.APIClientInterface.GetDiary(ctx, userID, studentID, instanceURL, start, end)
+}
+
+func (c *capabilityClient) GetAssignment(ctx context.Context, userID, studentID, assignmentID, instanceURL string) (*AssignmentDetails, error) {
+	if err := c.require(CapabilityAssignments); err != nil {
+		return nil, err
+	}
+	return c.APIClientInterface.GetAssignment(ctx, userID, studentID, assignmentID, instanceURL)
+}
+
+func (c *capabilityClient) GetAssignmentTypes(ctx context.Context, userID, instanceURL string) ([]AssignmentType, error) {
+	if err := c.require(CapabilityAssignmentTypes); err != nil {
+		return nil, err
+	}
+	return c.APIClientInterface.GetAssignmentTypes(ctx, userID, instanceURL)
+}
+
+func (c *capabilityClient) GetDownloadFile(ctx context.Context, userID, studentID, assignmentID, fileID, instanceURL string) (interface{}, error) {
+	if err := c.require(CapabilityFiles); err != nil {
+		return nil, err
+	}
+	return c.APIClientInterface.GetDownloadFile(ctx, userID, studentID, assignmentID, fileID, instanceURL)
+}
+
+func (c *capabilityClient) GetReportFile(ctx context.Context, userID, instanceURL, reportURL string, filters map[string]interface{}, yearID int, timeout int, transport *int) (interface{}, error) {
+	if err := c.require(CapabilityReports); err != nil {
+		return nil, err
+	}
+	return c.APIClientInterface.GetReportFile(ctx, userID, instanceURL, reportURL, filters, yearID, timeout, transport)
+}
+
+func (c *capabilityClient) GetJournal(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time, termID, classID int, transport *int) (interface{}, error) {
+	if err := c.require(CapabilityJournal); err != nil {
+		return nil, err
+	}
+	return c.APIClientInterface.GetJournal(ctx, userID, studentID, instanceURL, start, end, termID, classID, transport)
+}
+
+func (c *capabilityClient) GetFullJournal(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time, termID, classID int, transport *int) (interface{}, error) {
+	if err := c.require(CapabilityJournal); err != nil {
+		return nil, err
+	}
+	return c.APIClientInterface.GetFullJournal(ctx, userID, studentID, instanceURL, start, end, termID, classID, transport)
+}
+
+func (c *capabilityClient) GetGradesForSubject(ctx context.Context, userID, studentID, subjectID, instanceURL string, start, end time.Time, termID, classID int, transport *int) ([]Grade, error) {
+	if err := c.require(CapabilityGrades); err != nil {
+		return nil, err
+	}
+	return c.APIClientInterface.GetGradesForSubject(ctx, userID, studentID, subjectID, instanceURL, start, end, termID, classID, transport)
+}
+
+func (c *capabilityClient) GetInfo(ctx context.Context, userID, instanceURL string) (*MySettings, error) {
+	if err := c.require(CapabilityInfo); err != nil {
+		return nil, err
+	}
+	return c.APIClientInterface.GetInfo(ctx, userID, instanceURL)
+}
+
+func (c *capabilityClient) GetCurrentYear(ctx context.Context, userID, instanceURL string) (*SchoolYear, error) {
+	if err := c.require(CapabilityInfo); err != nil {
+		return nil, err
+	}
+	return c.APIClientInterface.GetCurrentYear(ctx, userID, instanceURL)
+}
+
+func (c *capabilityClient) GetYears(ctx context.Context, userID, instanceURL string) ([]SchoolYear, error) {
+	if err := c.require(CapabilityInfo); err != nil {
+		return nil, err
+	}
+	return c.APIClientInterface.GetYears(ctx, userID, instanceURL)
+}
+
+func (c *capabilityClient) GetTerms(ctx context.Context, userID, instanceURL string, yearID int) ([]Term, error) {
+	if err := c.require(CapabilityInfo); err != nil {
+		return nil, err
+	}
+	return c.APIClientInterface.GetTerms(ctx, userID, instanceURL, yearID)
+}
+
+func (c *capabilityClient) GetPhoto(ctx context.Context, userID, studentID, instanceURL string) (interface{}, error) {
+	if err := c.require(CapabilityPhoto); err != nil {
+		return nil, err
+	}
+	return c.APIClientInterface.GetPhoto(ctx, userID, studentID, instanceURL)
+}
+
+func (c *capabilityClient) CheckHealth(ctx context.Context, instanceURL string) (bool, error) {
+	if err := c.require(CapabilityHealth); err != nil {
+		return false, err
+	}
+	return c.APIClientInterface.CheckHealth(ctx, instanceURL)
+}
+
+func (c *capabilityClient) CheckIntPing(ctx context.Context, instanceURL string) (bool, time.Duration, error) {
+	if err := c.require(CapabilityHealth); err != nil {
+		return false, 0, err
+	}
+	return c.APIClientInterface.CheckIntPing(ctx, instanceURL)
+}
@@ -2,6 +2,7 @@ package api_types
 
 import (
 	"context"
+	"net/http"
 	"time"
 )
 
@@ -12,20 +13,24 @@ type APIClientInterface interface {
 	GetLoginData(ctx context.Context, instanceURL string) (map[string]interface{}, error)
 
 	
-	GetStudentInfo(ctx context.Context, userID, instanceURL string) (interface{}, error)
-	GetGrades(ctx context.Context, userID, studentID, instanceURL string) (interface{}, error)
-	GetSchedule(ctx context.Context, userID, instanceURL string, weekStart time.Time) (interface{}, error)
-	GetSchoolInfo(ctx context.Context, userID, instanceURL string) (interface{}, error)
-	GetClasses(ctx context.Context, userID, instanceURL string) (interface{}, error)
-	GetDiary(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time) (interface{}, error)
-	GetAssignment(ctx context.Context, userID, studentID, assignmentID, instanceURL string) (interface{}, error)
-	GetAssignmentTypes(ctx context.Context, userID, instanceURL string) (interface{}, error)
+	GetStudentInfo(ctx context.Context, userID, instanceURL string) (*MySettings, error)
+	GetStudents(ctx context.Context, userID, instanceURL string) (*StudentList, error)
+	GetGrades(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time) ([]Grade, error)
+	GetSchedule(ctx context.Context, userID, studentID, instanceURL string, weekStart time.Time) (*Diary, error)
+	GetSchoolInfo(ctx context.Context, userID string, schoolID int, instanceURL string) (*SchoolInfo, error)
+	GetClasses(ctx context.Context, userID, instanceURL string) ([]Class, error)
+	GetDiary(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time) (*Diary, error)
+	GetAssignment(ctx context.Context, userID, studentID, assignmentID, instanceURL string) (*AssignmentDetails, error)
+	GetAssignmentTypes(ctx context.Context, userID, instanceURL string) ([]AssignmentType, error)
 	GetDownloadFile(ctx context.Context, userID, studentID, assignmentID, fileID, instanceURL string) (interface{}, error)
 	GetReportFile(ctx context.Context, userID, instanceURL, reportURL string, filters map[string]interface{}, yearID int, timeout int, transport *int) (interface{}, error)
 	GetJournal(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time, termID, classID int, transport *int) (interface{}, error)
-	GetInfo(ctx context.Context, userID, instanceURL string) (interface{}, error)
+	GetInfo(ctx context.Context, userID, instanceURL string) (*MySettings, error)
+	GetCurrentYear(ctx context.Context, userID, instanceURL string) (*SchoolYear, error)
+	GetYears(ctx context.Context, userID, instanceURL string) ([]SchoolYear, error)
+	GetTerms(ctx context.Context, userID, instanceURL string, yearID int) ([]Term, error)
 	GetPhoto(ctx context.Context, userID, studentID, instanceURL string) (interface{}, error)
-	GetGradesForSubject(ctx context.Context, userID, studentID, subjectID, instanceURL string, start, end time.Time, termID, classID int, transport *int) (interface{}, error)
+	GetGradesForSubject(ctx context.Context, userID, studentID, subjectID, instanceURL string, start, end time.Time, termID, classID int, transport *int) ([]Grade, error)
 	GetFullJournal(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time, termID, classID int, transport *int) (interface{}, error)
 
 	
@@ -34,47 +39,80 @@ type APIClientInterface interface {
 }
 
 
-type APIClientFactory struct{}
+type APIClientFactory struct {
+	registry  *ProviderRegistry
+	transport http.RoundTripper
+	userAgent string
+	breakers  *BreakerRegistry
+	coalescer *Coalescer
+	jars      *sessionJars
+}
 
 
-func (f *APIClientFactory) NewAPIClient(mode APIMode, config APIConfig) (APIClientInterface, error) {
-	switch mode {
-	case NSWebAPI:
-		return f.createNSWebAPIClient(config)
-	case NSMobileAPI:
-		return f.createNSMobileAPIClient(config)
-	case DevMockAPI:
-		return f.createDevMockAPIClient(config)
-	default:
-		return nil, ErrInvalidAPIMode
+
+func NewAPIClientFactory(registry *ProviderRegistry, transportConfig TransportConfig) (*APIClientFactory, error) {
+	transport, err := NewTransport(transportConfig)
+	if err != nil {
+		return nil, err
+	}
+	factory := &APIClientFactory{
+		registry:  registry,
+		transport: transport,
+		userAgent: transportConfig.UserAgent,
+		breakers:  NewBreakerRegistry(transportConfig.BreakerThreshold, transportConfig.BreakerCooldown),
+		jars:      newSessionJars(sessionJarIdleTTL),
+	}
+	if transportConfig.Coalesce {
+		factory.coalescer = NewCoalescer()
 	}
+	return factory, nil
 }
 
 
-func (f *APIClientFactory) createNSWebAPIClient(config APIConfig) (APIClientInterface, error) {
-	
-	client := &NSWebAPIClient{
-		timeout:    time.Duration(config.Timeout) * time.Second,
-		retryMax:   config.RetryMax,
-		retryWait:  time.Duration(config.RetryWait) * time.Millisecond,
-	}
-	return client, nil
+func (f *APIClientFactory) Breakers() *BreakerRegistry {
+	return f.breakers
+}
+
+
+func (f *APIClientFactory) Coalescer() *Coalescer {
+	return f.coalescer
 }
 
 
-func (f *APIClientFactory) createNSMobileAPIClient(config APIConfig) (APIClientInterface, error) {
-	client := &NSMobileAPIClient{
-		timeout:    time.Duration(config.Timeout) * time.Second,
-		retryMax:   config.RetryMax,
-		retryWait:  time.Duration(config.RetryWait) * time.Millisecond,
+func (f *APIClientFactory) Registry() *ProviderRegistry {
+	if f.registry == nil {
+		return DefaultRegistry
 	}
-	return client, nil
+	return f.registry
 }
 
 
-func (f *APIClientFactory) createDevMockAPIClient(config APIConfig) (APIClientInterface, error) {
-	client := &DevMockAPIClient{
-		Timeout:    time.Duration(config.Timeout) * time.Second,
+func (f *APIClientFactory) NewAPIClient(mode APIMode, config APIConfig) (APIClientInterface, error) {
+	provider, ok := f.Registry().Lookup(mode)
+	if !ok {
+		return nil, ErrInvalidAPIMode
+	}
+
+	transport := f.transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	userAgent := f.userAgent
+	if userAgent == "" {
+		userAgent = provider.UserAgent
+	}
+	client, err := provider.New(config, newHTTPClient(transport, userAgent, config, f.breakers, f.jars))
+	if err != nil {
+		return nil, err
 	}
-	return client, nil
-}
\ No newline at end of file
+	if f.coalescer != nil {
+		client = newCoalescingClient(client, f.coalescer)
+	}
+	return newCapabilityClient(client, provider), nil
+}
+
+
+func (f *APIClientFactory) Supports(mode APIMode, capability Capability) bool {
+	provider, ok := f.Registry().Lookup(mode)
+	return ok && provider.Supports(capability)
+}
@@ -0,0 +1,225 @@
+package api_types
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+
+type CoalescerStats struct {
+	Upstream  int64 `json:"upstream"`
+	Coalesced int64 `json:"coalesced"`
+	InFlight  int   `json:"in_flight"`
+}
+
+
+
+
+type Coalescer struct {
+	mu        sync.Mutex
+	calls     map[string]*coalescedCall
+	upstream  int64
+	coalesced int64
+}
+
+type coalescedCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+func NewCoalescer() *Coalescer {
+	return &Coalescer{calls: make(map[string]*coalescedCall)}
+}
+
+
+
+
+
+func (c *Coalescer) Do(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	call, exists := c.calls[key]
+	if exists {
+		c.coalesced++
+	} else {
+		call = &coalescedCall{done: make(chan struct{})}
+		c.calls[key] = call
+		c.upstream++
+	}
+	c.mu.Unlock()
+
+	if !exists {
+		go func() {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					call.value, call.err = nil, Errorf(ErrCodeInternalError, "panic in coalesced call: %v", recovered)
+				}
+				c.mu.Lock()
+				delete(c.calls, key)
+				c.mu.Unlock()
+				close(call.done)
+			}()
+			call.value, call.err = fn(context.WithoutCancel(ctx))
+		}()
+	}
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+
+func (c *Coalescer) Stats() CoalescerStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CoalescerStats{
+		Upstream:  c.upstream,
+		Coalesced: c.coalesced,
+		InFlight:  len(c.calls),
+	}
+}
+
+
+
+type coalescingClient struct {
+	APIClientInterface
+	coalescer *Coalescer
+}
+
+func newCoalescingClient(client APIClientInterface, coalescer *Coalescer) *coalescingClient {
+	return &coalescingClient{APIClientInterface: client, coalescer: coalescer}
+}
+
+
+func (c *coalescingClient) Unwrap() APIClientInterface {
+	return c.APIClientInterface
+}
+
+
+
+
+func UnwrapClient(client APIClientInterface) APIClientInterface {
+	for {
+		wrapper, ok := client.(interface{ Unwrap() APIClientInterface })
+		if !ok {
+			return client
+		}
+		client = wrapper.Unwrap()
+	}
+}
+
+
+
+
+func coalesce[T any](ctx context.Context, c *coalescingClient, method, userID, instanceURL string, params []interface{}, fn func(ctx context.Context) (T, error)) (T, error) {
+	parts := []string{method, userID, strings.TrimRight(instanceURL, "/")}
+	if yearID, ok := SchoolYearFromContext(ctx); ok {
+		parts = append(parts, fmt.Sprintf("year=%d", yearID))
+	}
+	for _, param := range params {
+		if t, ok := param.(time.Time); ok {
+			param = t.Format(time.RFC3339)
+		}
+		parts = append(parts, fmt.Sprint(param))
+	}
+
+	value, err := c.coalescer.Do(ctx, strings.Join(parts, "\x00"), func(ctx context.Context) (interface{}, error) {
+		return fn(ctx)
+	})
+	result, _ := value.(T)
+	return result, err
+}
+
+func (c *coalescingClient) GetStudentInfo(ctx context.Context, userID, instanceURL string) (*MySettings, error) {
+	return coalesce(ctx, c, "GetStudentInfo", userID, instanceURL, nil, func(ctx context.Context) (*MySettings, error) {
+		return c.APIClientInterface.GetStudentInfo(ctx, userID, instanceURL)
+	})
+}
+
+func (c *coalescingClient) GetStudents(ctx context.Context, userID, instanceURL string) (*StudentList, error) {
+	return coalesce(ctx, c, "GetStudents", userID, instanceURL, nil, func(ctx context.Context) (*StudentList, error) {
+		return c.APIClientInterface.GetStudents(ctx, userID, instanceURL)
+	})
+}
+
+func (c *coalescingClient) GetGrades(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time) ([]Grade, error) {
+	return coalesce(ctx, c, "GetGrades", userID, instanceURL, []interface{}{studentID, start, end}, func(ctx context.Context) ([]Grade, error) {
+		return c.APIClientInterface.GetGrades(ctx, userID, studentID, instanceURL, start, end)
+	})
+}
+
+func (c *coalescingClient) GetSchedule(ctx context.Context, userID, studentID, instanceURL string, weekStart time.Time) (*Diary, error) {
+	return coalesce(ctx, c, "GetSchedule", userID, instanceURL, []interface{}{studentID, weekStart}, func(ctx context.Context) (*Diary, error) {
+		return c.APIClientInterface.GetSchedule(ctx, userID, studentID, instanceURL, weekStart)
+	})
+}
+
+func (c *coalescingClient) GetSchoolInfo(ctx context.Context, userID string, schoolID int, instanceURL string) (*SchoolInfo, error) {
+	return coalesce(ctx, c, "GetSchoolInfo", userID, instanceURL, []interface{}{schoolID}, func(ctx context.Context) (*SchoolInfo, error) {
+		return c.APIClientInterface.GetSchoolInfo(ctx, userID, schoolID, instanceURL)
+	})
+}
+
+func (c *coalescingClient) GetClasses(ctx context.Context, userID, instanceURL string) ([]Class, error) {
+	return coalesce(ctx, c, "GetClasses", userID, instanceURL, nil, func(ctx context.Context) ([]Class, error) {
+		return c.APIClientInterface.GetClasses(ctx, userID, instanceURL)
+	})
+}
+
+func (c *coalescingClient) GetDiary(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time) (*Diary, error) {
+	return coalesce(ctx, c, "GetDiary", userID, instanceURL, []interface{}{studentID, start, end}, func(ctx context.Context) (*Diary, error) {
+		return c.APIClientInterface.GetDiary(ctx, userID, studentID, instanceURL, start, end)
+	})
+}
+
+func (c *coalescingClient) GetAssignment(ctx context.Context, userID, studentID, assignmentID, instanceURL string) (*AssignmentDetails, error) {
+	return coalesce(ctx, c, "GetAssignment", userID, instanceURL, []interface{}{studentID, assignmentID}, func(ctx context.Context) (*AssignmentDetails, error) {
+		return c.APIClientInterface.GetAssignment(ctx, userID, studentID, assignmentID, instanceURL)
+	})
+}
+
+func (c *coalescingClient) GetAssignmentTypes(ctx context.Context, userID, instanceURL string) ([]AssignmentType, error) {
+	return coalesce(ctx, c, "GetAssignmentTypes", userID, instanceURL, nil, func(ctx context.Context) ([]AssignmentType, error) {
+		return c.APIClientInterface.GetAssignmentTypes(ctx, userID, instanceURL)
+	})
+}
+
+func (c *coalescingClient) GetInfo(ctx context.Context, userID, instanceURL string) (*MySettings, error) {
+	return coalesce(ctx, c, "GetInfo", userID, instanceURL, nil, func(ctx context.Context) (*MySettings, error) {
+		return c.APIClientInterface.GetInfo(ctx, userID, instanceURL)
+	})
+}
+
+func (c *coalescingClient) GetCurrentYear(ctx context.Context, userID, instanceURL string) (*SchoolYear, error) {
+	return coalesce(ctx, c, "GetCurrentYear", userID, instanceURL, nil, func(ctx context.Context) (*SchoolYear, error) {
+		return c.APIClientInterface.GetCurrentYear(ctx, userID, instanceURL)
+	})
+}
+
+func (c *coalescingClient) GetYears(ctx context.Context, userID, instanceURL string) ([]SchoolYear, error) {
+	return coalesce(ctx, c, "GetYears", userID, instanceURL, nil, func(ctx context.Context) ([]SchoolYear, error) {
+		return c.APIClientInterface.GetYears(ctx, userID, instanceURL)
+	})
+}
+
+func (c *coalescingClient) GetTerms(ctx context.Context, userID, instanceURL string, yearID int) ([]Term, error) {
+	return coalesce(ctx, c, "GetTerms", userID, instanceURL, []interface{}{yearID}, func(ctx context.Context) ([]Term, error) {
+		return c.APIClientInterface.GetTerms(ctx, userID, instanceURL, yearID)
+	})
+}
+
+func (c *coalescingClient) GetGradesForSubject(ctx context.Context, userID, studentID, subjectID, instanceURL string, start, end time.Time, termID, classID int, transport *int) ([]Grade, error) {
+	params := []interface{}{studentID, subjectID, start, end, termID, classID, "transport=nil"}
+	if transport != nil {
+		params[len(params)-1] = fmt.Sprintf("transport=%d", *transport)
+	}
+	return coalesce(ctx, c, "GetGradesForSubject", userID, instanceURL, params, func(ctx context.Context) ([]Grade, error) {
+		return c.APIClientInterface.GetGradesForSubject(ctx, userID, studentID, subjectID, instanceURL, start, end, termID, classID, transport)
+	})
+}
@@ -0,0 +1,156 @@
+package api_types_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/api_types"
+)
+
+type blockingDiaryClient struct {
+	api_types.DevMockAPIClient
+	calls   *int32
+	release chan struct{}
+}
+
+func (c *blockingDiaryClient) GetDiary(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time) (*api_types.Diary, error) {
+	atomic.AddInt32(c.calls, 1)
+	<-c.release
+	return &api_types.Diary{}, nil
+}
+
+func (c *blockingDiaryClient) StartDeviceAuthorization(ctx context.Context, instanceURL string) (*api_types.DeviceAuthorization, error) {
+	return nil, nil
+}
+
+func (c *blockingDiaryClient) PollDeviceToken(ctx context.Context, instanceURL, deviceCode string) (*api_types.OAuthToken, error) {
+	return nil, nil
+}
+
+func newCoalescingFactory(t *testing.T, calls *int32, release chan struct{}) *api_types.APIClientFactory {
+	t.Helper()
+	registry := api_types.NewProviderRegistry()
+	require.NoError(t, registry.Register(api_types.Provider{
+		Mode:         "blocking",
+		Name:         "Blocking",
+		Capabilities: []api_types.Capability{api_types.CapabilityDiary},
+		New: func(config api_types.APIConfig, httpClient *http.Client) (api_types.APIClientInterface, error) {
+			return &blockingDiaryClient{calls: calls, release: release}, nil
+		},
+	}))
+
+	factory, err := api_types.NewAPIClientFactory(registry, api_types.TransportConfig{Coalesce: true})
+	require.NoError(t, err)
+	return factory
+}
+
+func TestCoalescer_SharesConcurrentIdenticalCalls(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	factory := newCoalescingFactory(t, &calls, release)
+
+	start := time.Date(2026, 9, 7, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+
+	const callers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, callers+1)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client, err := factory.NewAPIClient("blocking", api_types.APIConfig{})
+			if err != nil {
+				errs <- err
+				return
+			}
+			_, err = client.GetDiary(context.Background(), "token-1", "42", "https://sgo.example", start, end)
+			errs <- err
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		client, _ := factory.NewAPIClient("blocking", api_types.APIConfig{})
+		_, err := client.GetDiary(context.Background(), "token-2", "42", "https://sgo.example", start, end)
+		errs <- err
+	}()
+
+	require.Eventually(t, func() bool {
+		return factory.Coalescer().Stats().Coalesced == callers-1
+	}, time.Second, 5*time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	stats := factory.Coalescer().Stats()
+	assert.Equal(t, int64(2), stats.Upstream)
+	assert.Equal(t, int64(callers-1), stats.Coalesced)
+	assert.Equal(t, 0, stats.InFlight)
+}
+
+func TestCoalescer_WaiterCancellation(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	factory := newCoalescingFactory(t, &calls, release)
+	client, err := factory.NewAPIClient("blocking", api_types.APIConfig{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.GetDiary(ctx, "token-1", "42", "https://sgo.example", time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	assert.Eventually(t, func() bool { return factory.Coalescer().Stats().InFlight == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestUnwrapClient_KeepsOptionalInterfaces(t *testing.T) {
+	var calls int32
+	factory := newCoalescingFactory(t, &calls, make(chan struct{}))
+	client, err := factory.NewAPIClient("blocking", api_types.APIConfig{})
+	require.NoError(t, err)
+
+	_, ok := api_types.UnwrapClient(client).(api_types.DeviceFlowClient)
+	assert.True(t, ok)
+}
+
+func TestCoalescer_PanicReachesAllWaiters(t *testing.T) {
+	coalescer := api_types.NewCoalescer()
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (interface{}, error) {
+		<-release
+		panic("boom")
+	}
+
+	const callers = 3
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			_, err := coalescer.Do(context.Background(), "key", fn)
+			errs <- err
+		}()
+	}
+
+	require.Eventually(t, func() bool { return coalescer.Stats().Coalesced == callers-1 }, time.Second, 5*time.Millisecond)
+	close(release)
+	for i := 0; i < callers; i++ {
+		err := <-errs
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "boom")
+		assert.Equal(t, api_types.ErrCodeInternalError, api_types.ErrorCodeOf(err))
+	}
+	assert.Equal(t, 0, coalescer.Stats().InFlight)
+}
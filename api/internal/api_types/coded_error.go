@@ -0,0 +1,136 @@
+package api_types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+
+
+type Error struct {
+	Code ErrorCode
+	Err  error
+	Data interface{}
+}
+
+
+func NewError(code ErrorCode, err error) *Error {
+	return &Error{Code: code, Err: err}
+}
+
+
+func Errorf(code ErrorCode, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return GetErrorInfo(e.Code).PrettyName
+	}
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+
+func (e *Error) WithData(data interface{}) *Error {
+	e.Data = data
+	return e
+}
+
+type errorCodeEntry struct {
+	target error
+	code   ErrorCode
+}
+
+var errorCodeRegistry struct {
+	mu      sync.RWMutex
+	entries []errorCodeEntry
+}
+
+
+
+
+func RegisterErrorCode(target error, code ErrorCode) {
+	errorCodeRegistry.mu.Lock()
+	defer errorCodeRegistry.mu.Unlock()
+	errorCodeRegistry.entries = append(errorCodeRegistry.entries, errorCodeEntry{target: target, code: code})
+}
+
+func init() {
+	RegisterErrorCode(ErrInstanceNotAllowed, ErrCodeInstanceNotAllowed)
+	RegisterErrorCode(ErrPrivateNetworkBlocked, ErrCodeInstanceNotAllowed)
+	RegisterErrorCode(ErrInvalidInstanceURL, ErrCodeInvalidInstanceURL)
+	RegisterErrorCode(ErrInstanceMismatch, ErrCodeInstanceMismatch)
+	RegisterErrorCode(ErrCircuitOpen, ErrCodeNetSchoolTemporarilyUnavailable)
+	RegisterErrorCode(ErrInteractiveLoginRequired, ErrCodeInteractiveLoginRequired)
+	RegisterErrorCode(ErrUnexpectedPayload, ErrCodeNetSchoolAPIError)
+	RegisterErrorCode(ErrNetSchoolMaintenance, ErrCodeNetSchoolMaintenance)
+	RegisterErrorCode(ErrNetSchoolUnavailable, ErrCodeNetSchoolTemporarilyUnavailable)
+	RegisterErrorCode(ErrInvalidCredentials, ErrCodeInvalidCredentials)
+	RegisterErrorCode(ErrTooManyLoginAttempts, ErrCodeAccountLocked)
+	RegisterErrorCode(ErrAuthenticationFailed, ErrCodeNetSchoolAuthFailed)
+	RegisterErrorCode(ErrUnauthorized, ErrCodeSessionExpired)
+	RegisterErrorCode(ErrInvalidAPIMode, ErrCodeBadRequest)
+	RegisterErrorCode(ErrDeviceFlowNotSupported, ErrCodeBadRequest)
+	RegisterErrorCode(ErrDirectoryNotSupported, ErrCodeBadRequest)
+	RegisterErrorCode(ErrDeviceCodeExpired, ErrCodeDeviceCodeExpired)
+	RegisterErrorCode(ErrESIANotSupported, ErrCodeNotImplemented)
+	RegisterErrorCode(ErrCapabilityNotSupported, ErrCodeNotImplemented)
+	RegisterErrorCode(ErrESIALoginFailed, ErrCodeInvalidCredentials)
+	RegisterErrorCode(ErrESIAAccountSelectionRequired, ErrCodeAccountSelectionRequired)
+	RegisterErrorCode(ErrESIAAccountNotFound, ErrCodeAccountNotLinked)
+	RegisterErrorCode(ErrNotFound, ErrCodeNotFound)
+	RegisterErrorCode(ErrBadRequest, ErrCodeBadRequest)
+	RegisterErrorCode(ErrForbidden, ErrCodeForbidden)
+	RegisterErrorCode(ErrNetworkError, ErrCodeNetworkError)
+	RegisterErrorCode(ErrTimeout, ErrCodeTimeout)
+	RegisterErrorCode(ErrServiceUnavailable, ErrCodeServiceUnavailable)
+	RegisterErrorCode(context.DeadlineExceeded, ErrCodeTimeout)
+}
+
+
+
+
+func LookupErrorCode(err error) (ErrorCode, bool) {
+	if err == nil {
+		return ErrCodeOK, true
+	}
+
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code, true
+	}
+
+	errorCodeRegistry.mu.RLock()
+	defer errorCodeRegistry.mu.RUnlock()
+	for _, entry := range errorCodeRegistry.entries {
+		if errors.Is(err, entry.target) {
+			return entry.code, true
+		}
+	}
+	return ErrCodeInternalError, false
+}
+
+
+func ErrorCodeOf(err error) ErrorCode {
+	code, _ := LookupErrorCode(err)
+	return code
+}
+
+
+
+
+func WithDefaultCode(err error, code ErrorCode) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := LookupErrorCode(err); ok {
+		return err
+	}
+	return NewError(code, err)
+}
@@ -0,0 +1,113 @@
+package api_types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sessionJarIdleTTL    = 24 * time.Hour
+	sessionJarSweepEvery = time.Minute
+)
+
+
+
+
+type sessionJars struct {
+	mu        sync.Mutex
+	jars      map[string]*sessionJar
+	idleTTL   time.Duration
+	lastSweep time.Time
+}
+
+type sessionJar struct {
+	jar    *cookiejar.Jar
+	usedAt time.Time
+}
+
+func newSessionJars(idleTTL time.Duration) *sessionJars {
+	return &sessionJars{
+		jars:    make(map[string]*sessionJar),
+		idleTTL: idleTTL,
+	}
+}
+
+
+
+func (s *sessionJars) get(key string, seed func(*cookiejar.Jar)) *cookiejar.Jar {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= sessionJarSweepEvery {
+		for k, entry := range s.jars {
+			if now.Sub(entry.usedAt) > s.idleTTL {
+				delete(s.jars, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	entry, ok := s.jars[key]
+	if !ok {
+		jar, _ := cookiejar.New(nil)
+		seed(jar)
+		entry = &sessionJar{jar: jar}
+		s.jars[key] = entry
+	}
+	entry.usedAt = now
+	return entry.jar
+}
+
+
+
+
+
+
+type cookieTransport struct {
+	base  http.RoundTripper
+	local *cookiejar.Jar
+	jars  *sessionJars
+}
+
+func (t *cookieTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	jar := t.jarFor(req)
+	if cookies := jar.Cookies(req.URL); len(cookies) > 0 {
+		req = req.Clone(req.Context())
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		if cookies := resp.Cookies(); len(cookies) > 0 {
+			jar.SetCookies(req.URL, cookies)
+		}
+	}
+	return resp, err
+}
+
+func (t *cookieTransport) jarFor(req *http.Request) *cookiejar.Jar {
+	token := req.Header.Get("at")
+	if token == "" {
+		token = strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	}
+	if token == "" || t.jars == nil {
+		return t.local
+	}
+
+	sum := sha256.Sum256([]byte(req.URL.Scheme + "://" + req.URL.Host + "\n" + token))
+	return t.jars.get(hex.EncodeToString(sum[:]), func(jar *cookiejar.Jar) {
+		if cookies := t.local.Cookies(req.URL); len(cookies) > 0 {
+			root := *req.URL
+			root.Path, root.RawPath, root.RawQuery = "/", "", ""
+			jar.SetCookies(&root, cookies)
+		}
+	})
+}
@@ -3,6 +3,9 @@ package api_types
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,6 +14,36 @@ type DevMockAPIClient struct {
 	Timeout time.Duration
 }
 
+func init() {
+	DefaultRegistry.MustRegister(Provider{
+		Mode:        DevMockAPI,
+		Name:        "Mock API",
+		Description: "Тестовый провайдер с фиксированными данными для разработки",
+		Mock:        true,
+		Capabilities: []Capability{
+			CapabilityStudentInfo, CapabilityStudents, CapabilityGrades, CapabilitySchedule,
+			CapabilitySchoolInfo, CapabilityClasses, CapabilityDiary, CapabilityAssignments,
+			CapabilityAssignmentTypes, CapabilityFiles, CapabilityReports, CapabilityJournal,
+			CapabilityInfo, CapabilityPhoto, CapabilityHealth, CapabilityDirectory,
+		},
+		Login: LoginRequirements{
+			Methods:          []LoginMethod{LoginMethodPassword},
+			RequiresPassword: true,
+		},
+		ConfigSchema: []ConfigField{
+			{Name: "timeout", Type: "duration", Default: "30s", Description: "HTTP request timeout"},
+		},
+		New: newDevMockAPIClient,
+	})
+}
+
+func newDevMockAPIClient(config APIConfig, httpClient *http.Client) (APIClientInterface, error) {
+	client := &DevMockAPIClient{
+		Timeout:    time.Duration(config.Timeout) * time.Second,
+	}
+	return client, nil
+}
+
 
 func (c *DevMockAPIClient) Login(ctx context.Context, username, password string, schoolID int, instanceURL string, loginData map[string]interface{}) (string, error) {
 	
@@ -18,11 +51,6 @@ func (c *DevMockAPIClient) Login(ctx context.Context, username, password string,
 		return "", ErrAuthenticationFailed
 	}
 
-	if username == "nsfail" && password == "nsfail" {
-		
-		return "mock_token_for_nsfail", nil
-	}
-
 	
 	return fmt.Sprintf("mock_token_for_%s", username), nil
 }
@@ -37,134 +65,61 @@ func (c *DevMockAPIClient) GetLoginData(ctx context.Context, instanceURL string)
 }
 
 
-func (c *DevMockAPIClient) GetStudentInfo(ctx context.Context, userID, instanceURL string) (interface{}, error) {
-	studentInfo := map[string]interface{}{
-		"id":         fmt.Sprintf("student_%s", userID),
-		"first_name": "Тест",
-		"last_name":  "Студент",
-		"middle_name": "Тестович",
-		"birth_date": "2000-01-01",
-		"class":      "9А",
-		"school_id":  1,
-	}
-
-	return studentInfo, nil
+func (c *DevMockAPIClient) GetStudentInfo(ctx context.Context, userID, instanceURL string) (*MySettings, error) {
+	return c.GetInfo(ctx, userID, instanceURL)
 }
 
 
-func (c *DevMockAPIClient) GetGrades(ctx context.Context, userID, studentID, instanceURL string) (interface{}, error) {
-	grades := []interface{}{
-		map[string]interface{}{
-			"id":          fmt.Sprintf("grade_%s_1", userID),
-			"student_id":  studentID,
-			"subject_id":  "math",
-			"value":       "5",
-			"date":        "2023-09-15",
-			"description": "Контрольная работа",
-			"teacher_id":  "teacher_1",
-			"weight":      10,
+func (c *DevMockAPIClient) GetStudents(ctx context.Context, userID, instanceURL string) (*StudentList, error) {
+	return &StudentList{
+		Students: []Student{
+			{ID: "1001", Name: "Тест Студент", ClassID: "91", ClassName: "9А"},
+			{ID: "1002", Name: "Тест Студентка", ClassID: "51", ClassName: "5Б"},
 		},
-		map[string]interface{}{
-			"id":          fmt.Sprintf("grade_%s_2", userID),
-			"student_id":  studentID,
-			"subject_id":  "math",
-			"value":       "4",
-			"date":        "2023-09-10",
-			"description": "Самостоятельная работа",
-			"teacher_id":  "teacher_1",
-			"weight":      5,
-		},
-	}
-
-	return grades, nil
+		CurrentStudentID: "1001",
+	}, nil
 }
 
 
-func (c *DevMockAPIClient) GetSchedule(ctx context.Context, userID, instanceURL string, weekStart time.Time) (interface{}, error) {
-	
-	days := make([]interface{}, 7)
-	for i := 0; i < 7; i++ {
-		currentDay := weekStart.AddDate(0, 0, i)
-
-		var lessons []interface{}
-		if i < 5 { 
-			lessons = []interface{}{
-				map[string]interface{}{
-					"id":       fmt.Sprintf("lesson_%s_%d_1", userID, i),
-					"number":   1,
-					"subject":  "Математика",
-					"teacher":  "Иванова А.А.",
-					"room":     "301",
-					"start":    currentDay.Add(8 * time.Hour).Add(30 * time.Minute),
-					"end":      currentDay.Add(9 * time.Hour).Add(15 * time.Minute),
-					"date":     currentDay,
-				},
-				map[string]interface{}{
-					"id":       fmt.Sprintf("lesson_%s_%d_2", userID, i),
-					"number":   2,
-					"subject":  "Русский язык",
-					"teacher":  "Петрова Б.Б.",
-					"room":     "302",
-					"start":    currentDay.Add(9 * time.Hour).Add(30 * time.Minute),
-					"end":      currentDay.Add(10 * time.Hour).Add(15 * time.Minute),
-					"date":     currentDay,
-				},
-			}
-		}
-
-		days[i] = map[string]interface{}{
-			"date":    currentDay,
-			"lessons": lessons,
-		}
-	}
-
-	schedule := map[string]interface{}{
-		"week_start": weekStart,
-		"week_end":   weekStart.AddDate(0, 0, 6),
-		"days":       days,
+func (c *DevMockAPIClient) GetGrades(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time) ([]Grade, error) {
+	diary, err := c.GetDiary(ctx, userID, studentID, instanceURL, start, end)
+	if err != nil {
+		return nil, err
 	}
-
-	return schedule, nil
+	return diary.Grades(), nil
 }
 
 
-func (c *DevMockAPIClient) GetSchoolInfo(ctx context.Context, userID, instanceURL string) (interface{}, error) {
-	schoolInfo := map[string]interface{}{
-		"id":           1,
-		"name":         "Тестовая школа №1",
-		"address":      "ул. Тестовая, д. 1",
-		"phone":        "+7 (123) 456-78-90",
-		"email":        "test-school@example.com",
-		"principal":    "Тестов Тест Тестович",
-		"foundation_year": 1990,
-		"website":      "https://test-school.edu.ru",
-	}
-
-	return schoolInfo, nil
+func (c *DevMockAPIClient) GetSchedule(ctx context.Context, userID, studentID, instanceURL string, weekStart time.Time) (*Diary, error) {
+	return c.GetDiary(ctx, userID, studentID, instanceURL, weekStart, weekStart.AddDate(0, 0, 6))
 }
 
 
-func (c *DevMockAPIClient) GetClasses(ctx context.Context, userID, instanceURL string) (interface{}, error) {
-	classes := []interface{}{
-		map[string]interface{}{
-			"id":              "class_1",
-			"name":            "1А",
-			"grade":           1,
-			"letter":          "А",
-			"teacher":         "Тестова Т.Т.",
-			"students_count":  25,
+func (c *DevMockAPIClient) GetSchoolInfo(ctx context.Context, userID string, schoolID int, instanceURL string) (*SchoolInfo, error) {
+	return &SchoolInfo{
+		CommonInfo: SchoolCommonInfo{
+			SchoolName:     "Тестовая школа №1",
+			FullSchoolName: "Муниципальное бюджетное общеобразовательное учреждение «Тестовая школа №1»",
 		},
-		map[string]interface{}{
-			"id":              "class_9b",
-			"name":            "9Б",
-			"grade":           9,
-			"letter":          "Б",
-			"teacher":         "Тестов Т.Т.",
-			"students_count":  22,
+		ContactInfo: SchoolContactInfo{
+			JuridicalAddress: "ул. Тестовая, д. 1",
+			PostAddress:      "ул. Тестовая, д. 1",
+			Phones:           "+7 (123) 456-78-90",
+			Email:            "test-school@example.com",
+			Site:             "https://test-school.edu.ru",
 		},
-	}
+		ManagementInfo: SchoolManagementInfo{
+			Director: "Тестов Тест Тестович",
+		},
+	}, nil
+}
 
-	return classes, nil
+
+func (c *DevMockAPIClient) GetClasses(ctx context.Context, userID, instanceURL string) ([]Class, error) {
+	return []Class{
+		{ID: 1, Name: "1А"},
+		{ID: 92, Name: "9Б"},
+	}, nil
 }
 
 
@@ -184,118 +139,100 @@ func (c *DevMockAPIClient) CheckIntPing(ctx context.Context, instanceURL string)
 }
 
 
-func (c *DevMockAPIClient) GetDiary(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time) (interface{}, error) {
+func (c *DevMockAPIClient) GetDiary(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time) (*Diary, error) {
 	
-	days := make([]interface{}, 0)
+	days := make([]DiaryDay, 0)
 	current := start
 	for current.Before(end) || current.Equal(end) {
 		
 		if current.Weekday() != time.Saturday && current.Weekday() != time.Sunday {
-			lessons := []interface{}{
-				map[string]interface{}{
-					"id":       fmt.Sprintf("lesson_%s_%s", userID, current.Format("2006-01-02")),
-					"number":   1,
-					"subject":  "Математика",
-					"teacher":  "Иванова А.А.",
-					"room":     "301",
-					"start":    current.Add(8 * time.Hour).Add(30 * time.Minute),
-					"end":      current.Add(9 * time.Hour).Add(15 * time.Minute),
-					"date":     current,
-					"homework": "Выполнить задачи 1-5",
-					"assignments": []interface{}{
-						map[string]interface{}{
-							"id":       fmt.Sprintf("assignment_%s_%s", userID, current.Format("2006-01-02")),
-							"text":     "Домашнее задание",
-							"date":     current,
-							"mark":     5,
-							"typeId":   1,
-							"comment":  "Хорошая работа!",
+			date := current.Format("2006-01-02T00:00:00")
+			dayNumber := current.YearDay()
+			days = append(days, DiaryDay{
+				Date: date,
+				Lessons: []Lesson{
+					{
+						ClassmeetingID: dayNumber*10 + 1,
+						Day:            date,
+						Number:         1,
+						Room:           "301",
+						StartTime:      "08:30",
+						EndTime:        "09:15",
+						SubjectID:      1,
+						SubjectName:    "Математика",
+						Assignments: []Assignment{
+							{
+								ID:             dayNumber*10 + 1,
+								TypeID:         1,
+								AssignmentName: "Выполнить задачи 1-5",
+								Weight:         10,
+								DueDate:        date,
+								Mark:           &Mark{AssignmentID: dayNumber*10 + 1, Mark: "5"},
+							},
 						},
 					},
-				},
-				map[string]interface{}{
-					"id":       fmt.Sprintf("lesson2_%s_%s", userID, current.Format("2006-01-02")),
-					"number":   2,
-					"subject":  "Русский язык",
-					"teacher":  "Петрова Б.Б.",
-					"room":     "302",
-					"start":    current.Add(9 * time.Hour).Add(30 * time.Minute),
-					"end":      current.Add(10 * time.Hour).Add(15 * time.Minute),
-					"date":     current,
-					"homework": "Прочитать главу 3",
-					"assignments": []interface{}{
-						map[string]interface{}{
-							"id":       fmt.Sprintf("assignment2_%s_%s", userID, current.Format("2006-01-02")),
-							"text":     "Сочинение",
-							"date":     current.AddDate(0, 0, 1),
-							"mark":     4,
-							"typeId":   2,
-							"comment":  "Нужно доработать",
+					{
+						ClassmeetingID: dayNumber*10 + 2,
+						Day:            date,
+						Number:         2,
+						Room:           "302",
+						StartTime:      "09:30",
+						EndTime:        "10:15",
+						SubjectID:      2,
+						SubjectName:    "Русский язык",
+						Assignments: []Assignment{
+							{
+								ID:             dayNumber*10 + 2,
+								TypeID:         2,
+								AssignmentName: "Сочинение",
+								Weight:         20,
+								DueDate:        current.AddDate(0, 0, 1).Format("2006-01-02T00:00:00"),
+								Mark:           &Mark{AssignmentID: dayNumber*10 + 2, Mark: "4"},
+							},
 						},
 					},
 				},
-			}
-
-			days = append(days, map[string]interface{}{
-				"date":    current,
-				"lessons": lessons,
 			})
 		}
 
 		current = current.AddDate(0, 0, 1)
 	}
 
-	diary := map[string]interface{}{
-		"weekDays":   days,
-		"termName":   "1 четверть",
-		"className":  "9А",
-		"weekStart":  start,
-		"weekEnd":    end,
-	}
-
-	return diary, nil
+	return &Diary{
+		WeekStart: start.Format("2006-01-02T00:00:00"),
+		WeekEnd:   end.Format("2006-01-02T00:00:00"),
+		WeekDays:  days,
+		TermName:  "1 четверть",
+		ClassName: "9А",
+	}, nil
 }
 
 
-func (c *DevMockAPIClient) GetAssignment(ctx context.Context, userID, studentID, assignmentID, instanceURL string) (interface{}, error) {
-	assignment := map[string]interface{}{
-		"id":          assignmentID,
-		"date":        time.Now().AddDate(0, 0, -1),
-		"text":        "Пример задания",
-		"weight":      10,
-		"subject":     "Математика",
-		"teacher":     "Иванова А.А.",
-		"isDeleted":   false,
-		"description": "Описание примерного задания",
+func (c *DevMockAPIClient) GetAssignment(ctx context.Context, userID, studentID, assignmentID, instanceURL string) (*AssignmentDetails, error) {
+	id, err := strconv.Atoi(assignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: assignment id %q is not numeric", ErrUnexpectedPayload, assignmentID)
 	}
 
-	return assignment, nil
+	return &AssignmentDetails{
+		ID:             id,
+		AssignmentName: "Пример задания",
+		ActivityName:   "Домашнее задание",
+		SubjectGroup:   IDName{ID: 1, Name: "Математика"},
+		Teachers:       []IDName{{ID: 1, Name: "Иванова А.А."}},
+		Weight:         10,
+		Date:           time.Now().AddDate(0, 0, -1).Format("2006-01-02T00:00:00"),
+		Description:    "Описание примерного задания",
+	}, nil
 }
 
 
-func (c *DevMockAPIClient) GetAssignmentTypes(ctx context.Context, userID, instanceURL string) (interface{}, error) {
-	types := []interface{}{
-		map[string]interface{}{
-			"id":    1,
-			"name":  "Домашнее задание",
-			"abbr":  "ДЗ",
-			"order": 1,
-		},
-		map[string]interface{}{
-			"id":    2,
-			"name":  "Контрольная работа",
-			"abbr":  "КР",
-			"order": 2,
-		},
-		map[string]interface{}{
-			"id":    3,
-			"name":  "Самостоятельная работа",
-			"abbr":  "СР",
-			"order": 3,
-		},
-	}
-
-	return types, nil
+func (c *DevMockAPIClient) GetAssignmentTypes(ctx context.Context, userID, instanceURL string) ([]AssignmentType, error) {
+	return []AssignmentType{
+		{ID: 1, Name: "Домашнее задание", Abbr: "ДЗ", Order: 1},
+		{ID: 2, Name: "Контрольная работа", Abbr: "КР", Order: 2},
+		{ID: 3, Name: "Самостоятельная работа", Abbr: "СР", Order: 3},
+	}, nil
 }
 
 
@@ -363,18 +300,23 @@ func (c *DevMockAPIClient) GetJournal(ctx context.Context, userID, studentID, in
 }
 
 
-func (c *DevMockAPIClient) GetInfo(ctx context.Context, userID, instanceURL string) (interface{}, error) {
-	info := map[string]interface{}{
-		"email":        "test@example.com",
-		"mobilePhone":  "+7 (999) 123-45-67",
-		"firstName":    "Тест",
-		"lastName":     "Пользователь",
-		"middleName":   "Тестович",
-		"birthDate":    "2000-01-01",
-		"existsPhoto":  true,
+func (c *DevMockAPIClient) GetInfo(ctx context.Context, userID, instanceURL string) (*MySettings, error) {
+	loginName := "test"
+	if username, ok := strings.CutPrefix(userID, "mock_token_for_"); ok && username != "" {
+		loginName = username
 	}
 
-	return info, nil
+	return &MySettings{
+		UserID:       1001,
+		FirstName:    "Тест",
+		LastName:     "Пользователь",
+		MiddleName:   "Тестович",
+		BirthDate:    "2000-01-01T00:00:00",
+		LoginName:    loginName,
+		Email:        "test@example.com",
+		MobilePhone:  "+7 (999) 123-45-67",
+		SchoolYearID: 2025,
+	}, nil
 }
 
 
@@ -385,31 +327,12 @@ func (c *DevMockAPIClient) GetPhoto(ctx context.Context, userID, studentID, inst
 }
 
 
-func (c *DevMockAPIClient) GetGradesForSubject(ctx context.Context, userID, studentID, subjectID, instanceURL string, start, end time.Time, termID, classID int, transport *int) (interface{}, error) {
-	grades := []interface{}{
-		map[string]interface{}{
-			"id":          fmt.Sprintf("grade_%s_%s", studentID, subjectID),
-			"student_id":  studentID,
-			"subject_id":  subjectID,
-			"value":       "5",
-			"date":        start.AddDate(0, 0, 1).Format("2006-01-02"),
-			"description": "Контрольная работа",
-			"teacher_id":  "teacher_1",
-			"weight":      10,
-		},
-		map[string]interface{}{
-			"id":          fmt.Sprintf("grade_%s_%s_2", studentID, subjectID),
-			"student_id":  studentID,
-			"subject_id":  subjectID,
-			"value":       "4",
-			"date":        start.AddDate(0, 0, 3).Format("2006-01-02"),
-			"description": "Самостоятельная работа",
-			"teacher_id":  "teacher_1",
-			"weight":      5,
-		},
+func (c *DevMockAPIClient) GetGradesForSubject(ctx context.Context, userID, studentID, subjectID, instanceURL string, start, end time.Time, termID, classID int, transport *int) ([]Grade, error) {
+	grades, err := c.GetGrades(ctx, userID, studentID, instanceURL, start, end)
+	if err != nil {
+		return nil, err
 	}
-
-	return grades, nil
+	return filterGradesBySubject(grades, subjectID), nil
 }
 
 
@@ -485,4 +408,70 @@ func (c *DevMockAPIClient) GetFullJournal(ctx context.Context, userID, studentID
 	}
 
 	return journal, nil
-}
\ No newline at end of file
+}
+
+
+func (c *DevMockAPIClient) GetCurrentYear(ctx context.Context, userID, instanceURL string) (*SchoolYear, error) {
+	return &SchoolYear{
+		ID:        2025,
+		Name:      "2025/2026",
+		StartDate: "2025-09-01T00:00:00",
+		EndDate:   "2026-08-31T00:00:00",
+	}, nil
+}
+
+
+func (c *DevMockAPIClient) GetYears(ctx context.Context, userID, instanceURL string) ([]SchoolYear, error) {
+	current, err := c.GetCurrentYear(ctx, userID, instanceURL)
+	if err != nil {
+		return nil, err
+	}
+	return []SchoolYear{
+		{ID: 2024, Name: "2024/2025", StartDate: "2024-09-01T00:00:00", EndDate: "2025-08-31T00:00:00"},
+		*current,
+	}, nil
+}
+
+
+func (c *DevMockAPIClient) GetTerms(ctx context.Context, userID, instanceURL string, yearID int) ([]Term, error) {
+	return []Term{
+		{ID: yearID*10 + 1, Name: "1 четверть", StartDate: "2025-09-01T00:00:00", EndDate: "2025-10-26T00:00:00"},
+		{ID: yearID*10 + 2, Name: "2 четверть", StartDate: "2025-11-05T00:00:00", EndDate: "2025-12-28T00:00:00"},
+		{ID: yearID*10 + 3, Name: "3 четверть", StartDate: "2026-01-12T00:00:00", EndDate: "2026-03-22T00:00:00"},
+		{ID: yearID*10 + 4, Name: "4 четверть", StartDate: "2026-04-01T00:00:00", EndDate: "2026-05-31T00:00:00"},
+	}, nil
+}
+
+
+func (c *DevMockAPIClient) GetLoginForm(ctx context.Context, instanceURL string) (*LoginForm, error) {
+	return &LoginForm{
+		CountryID:    2,
+		StateID:      1,
+		ProvinceID:   -1,
+		CityID:       1,
+		SchoolTypeID: 2,
+		Countries:    []DirectoryItem{{ID: 2, Name: "Россия"}},
+		States:       []DirectoryItem{{ID: 1, Name: "Тестовая область"}},
+		Provinces: []DirectoryItem{
+			{ID: 10, Name: "Тестовый городской округ"},
+			{ID: 11, Name: "Тестовый муниципальный район"},
+		},
+		Cities: []DirectoryItem{
+			{ID: 1, Name: "г. Тестовск"},
+			{ID: 2, Name: "с. Тестовое"},
+		},
+		SchoolTypes: []DirectoryItem{
+			{ID: 2, Name: "Общеобразовательная"},
+			{ID: 3, Name: "Дополнительное образование"},
+		},
+	}, nil
+}
+
+
+func (c *DevMockAPIClient) GetSchoolDirectory(ctx context.Context, instanceURL string, schoolTypeID int) ([]SchoolSummary, error) {
+	return []SchoolSummary{
+		{ID: 1, Name: "Тестовая школа №1", ShortName: "Школа №1", Address: "г. Тестовск, ул. Тестовая, д. 1", ProvinceID: 10, CityID: 1},
+		{ID: 2, Name: "Тестовая гимназия №2", ShortName: "Гимназия №2", Address: "г. Тестовск, ул. Школьная, д. 2", ProvinceID: 10, CityID: 1},
+		{ID: 3, Name: "Тестовая сельская школа", ShortName: "Сельская школа", Address: "с. Тестовое, ул. Центральная, д. 5", ProvinceID: 11, CityID: 2},
+	}, nil
+}
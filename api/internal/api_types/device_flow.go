@@ -0,0 +1,43 @@
+package api_types
+
+import (
+	"context"
+	"errors"
+)
+
+
+var (
+	ErrDeviceFlowNotSupported     = errors.New("device authorization flow is not supported by this API mode")
+	ErrDeviceAuthorizationPending = errors.New("authorization pending")
+	ErrDeviceSlowDown             = errors.New("polling too fast, slow down")
+	ErrDeviceCodeExpired          = errors.New("device code expired")
+)
+
+
+type DeviceAuthorization struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+
+type OAuthToken struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+
+
+type DeviceFlowClient interface {
+	StartDeviceAuthorization(ctx context.Context, instanceURL string) (*DeviceAuthorization, error)
+	PollDeviceToken(ctx context.Context, instanceURL, deviceCode string) (*OAuthToken, error)
+}
+
+
+type TokenRefresher interface {
+	RefreshAccessToken(ctx context.Context, instanceURL, refreshToken string) (*OAuthToken, error)
+}
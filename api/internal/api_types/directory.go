@@ -0,0 +1,54 @@
+package api_types
+
+import (
+	"context"
+	"errors"
+)
+
+
+var ErrDirectoryNotSupported = errors.New("school directory is not supported by this API mode")
+
+
+type DirectoryItem struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+
+
+type LoginForm struct {
+	CountryID    int             `json:"cid"`
+	StateID      int             `json:"sid"`
+	ProvinceID   int             `json:"pid"`
+	CityID       int             `json:"cn"`
+	SchoolTypeID int             `json:"sft"`
+	Countries    []DirectoryItem `json:"countries"`
+	States       []DirectoryItem `json:"states"`
+	Provinces    []DirectoryItem `json:"provinces"`
+	Cities       []DirectoryItem `json:"cities"`
+	SchoolTypes  []DirectoryItem `json:"funcs"`
+}
+
+func (f *LoginForm) validate() error {
+	if len(f.Provinces) == 0 && len(f.Cities) == 0 && len(f.SchoolTypes) == 0 {
+		return errors.New("login form has no municipalities, cities or school types")
+	}
+	return nil
+}
+
+
+type SchoolSummary struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	ShortName  string `json:"shortName"`
+	Address    string `json:"addressString"`
+	ProvinceID int    `json:"provinceId"`
+	CityID     int    `json:"cityId"`
+}
+
+
+
+type SchoolDirectoryClient interface {
+	GetLoginForm(ctx context.Context, instanceURL string) (*LoginForm, error)
+	GetSchoolDirectory(ctx context.Context, instanceURL string, schoolTypeID int) ([]SchoolSummary, error)
+}
@@ -17,6 +17,12 @@ var (
 	ErrUnauthorized        = errors.New("unauthorized")
 	ErrForbidden           = errors.New("forbidden")
 	ErrServiceUnavailable  = errors.New("service unavailable")
+	ErrCircuitOpen         = errors.New("NetSchool instance is temporarily unavailable (circuit open)")
+	ErrInteractiveLoginRequired = errors.New("NetSchool requires interactive login")
+	ErrNetSchoolMaintenance = errors.New("NetSchool is under maintenance")
+	ErrNetSchoolUnavailable = errors.New("NetSchool is temporarily unavailable")
+	ErrInvalidCredentials  = errors.New("NetSchool rejected the login or password")
+	ErrTooManyLoginAttempts = errors.New("too many failed NetSchool login attempts")
 )
 
 
@@ -34,12 +40,18 @@ const (
 	ErrCodeUnauthorized     ErrorCode = -7
 	ErrCodeForbidden        ErrorCode = -8
 	ErrCodeServiceUnavailable ErrorCode = -9
+	ErrCodeNotImplemented   ErrorCode = -10
+	ErrCodeTooManyRequests  ErrorCode = -11
 	
 	
 	ErrCodeInvalidCredentials ErrorCode = 1001
 	ErrCodeAccountLocked     ErrorCode = 1002
 	ErrCodeAccountDisabled   ErrorCode = 1003
 	ErrCodeSessionExpired    ErrorCode = 1004
+	ErrCodeAccountSelectionRequired ErrorCode = 1005
+	ErrCodeAccountNotLinked  ErrorCode = 1006
+	ErrCodeInteractiveLoginRequired ErrorCode = 1007
+	ErrCodeDeviceCodeExpired ErrorCode = 1008
 	
 	
 	ErrCodeDataValidationError ErrorCode = 2001
@@ -57,6 +69,9 @@ const (
 	ErrCodeNetSchoolAPIError  ErrorCode = 4002
 	ErrCodeNetSchoolTemporarilyUnavailable ErrorCode = 4003
 	ErrCodeNetSchoolMaintenance ErrorCode = 4004
+	ErrCodeInstanceNotAllowed ErrorCode = 4005
+	ErrCodeInvalidInstanceURL ErrorCode = 4006
+	ErrCodeInstanceMismatch   ErrorCode = 4007
 )
 
 
@@ -79,12 +94,18 @@ var ErrorCodesMap = map[ErrorCode]ErrorInfo{
 	ErrCodeUnauthorized: {ErrCodeUnauthorized, "Unauthorized", "Неавторизованный доступ", 401},
 	ErrCodeForbidden: {ErrCodeForbidden, "Forbidden", "Доступ запрещен", 403},
 	ErrCodeServiceUnavailable: {ErrCodeServiceUnavailable, "Service Unavailable", "Сервис недоступен", 503},
+	ErrCodeNotImplemented: {ErrCodeNotImplemented, "Not Implemented", "Функция не поддерживается на этом сервере", 501},
+	ErrCodeTooManyRequests: {ErrCodeTooManyRequests, "Too Many Requests", "Слишком много запросов, повторите позже", 429},
 	
 	
 	ErrCodeInvalidCredentials: {ErrCodeInvalidCredentials, "Invalid Credentials", "Неверные учетные данные", 401},
 	ErrCodeAccountLocked: {ErrCodeAccountLocked, "Account Locked", "Аккаунт заблокирован", 401},
 	ErrCodeAccountDisabled: {ErrCodeAccountDisabled, "Account Disabled", "Аккаунт отключен", 401},
 	ErrCodeSessionExpired: {ErrCodeSessionExpired, "Session Expired", "Сессия истекла", 401},
+	ErrCodeAccountSelectionRequired: {ErrCodeAccountSelectionRequired, "Account Selection Required", "Выберите учетную запись NetSchool", 409},
+	ErrCodeAccountNotLinked: {ErrCodeAccountNotLinked, "Account Not Linked", "Учетная запись NetSchool не связана с этим входом", 400},
+	ErrCodeInteractiveLoginRequired: {ErrCodeInteractiveLoginRequired, "Interactive Login Required", "NetSchool требует вход через браузер", 401},
+	ErrCodeDeviceCodeExpired: {ErrCodeDeviceCodeExpired, "Device Code Expired", "Код подтверждения устарел, начните вход заново", 410},
 	
 	
 	ErrCodeDataValidationError: {ErrCodeDataValidationError, "Data Validation Error", "Ошибка валидации данных", 400},
@@ -102,6 +123,9 @@ var ErrorCodesMap = map[ErrorCode]ErrorInfo{
 	ErrCodeNetSchoolAPIError: {ErrCodeNetSchoolAPIError, "NetSchool API Error", "Ошибка API NetSchool", 500},
 	ErrCodeNetSchoolTemporarilyUnavailable: {ErrCodeNetSchoolTemporarilyUnavailable, "NetSchool Temporarily Unavailable", "NetSchool временно недоступна", 503},
 	ErrCodeNetSchoolMaintenance: {ErrCodeNetSchoolMaintenance, "NetSchool Maintenance", "NetSchool на обслуживании", 503},
+	ErrCodeInstanceNotAllowed: {ErrCodeInstanceNotAllowed, "Instance Not Allowed", "Сервер NetSchool не входит в список разрешенных", 403},
+	ErrCodeInvalidInstanceURL: {ErrCodeInvalidInstanceURL, "Invalid Instance URL", "Некорректный адрес сервера NetSchool", 400},
+	ErrCodeInstanceMismatch: {ErrCodeInstanceMismatch, "Instance Mismatch", "Адрес сервера NetSchool не совпадает с сервером сессии", 403},
 }
 
 
@@ -122,4 +146,4 @@ func GetErrorInfo(code ErrorCode) ErrorInfo {
 
 func (e ErrorInfo) error() error {
 	return fmt.Errorf("%s (%d): %s", e.PrettyName, e.Code, e.Description)
-}
\ No newline at end of file
+}
@@ -0,0 +1,75 @@
+package api_types
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrESIANotSupported             = errors.New("ESIA login is not enabled on this server")
+	ErrESIALoginFailed              = errors.New("ESIA rejected the login or password")
+	ErrESIAAccountSelectionRequired = errors.New("several NetSchool accounts are linked to this ESIA login")
+	ErrESIAAccountNotFound          = errors.New("requested NetSchool account is not linked to this ESIA login")
+)
+
+type ESIACredentials struct {
+	Login    string
+	Password string
+	Account  string
+}
+
+type ESIAAccount struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	School   string `json:"school,omitempty"`
+	SchoolID int    `json:"school_id,omitempty"`
+}
+
+type ESIAAccountSelectionError struct {
+	Accounts []ESIAAccount
+}
+
+func (e *ESIAAccountSelectionError) Error() string {
+	return fmt.Sprintf("%s: %d accounts", ErrESIAAccountSelectionRequired, len(e.Accounts))
+}
+
+func (e *ESIAAccountSelectionError) Unwrap() error {
+	return ErrESIAAccountSelectionRequired
+}
+
+func ChooseESIAAccount(accounts []ESIAAccount, want string) (ESIAAccount, error) {
+	if len(accounts) == 0 {
+		return ESIAAccount{}, ErrESIAAccountNotFound
+	}
+
+	want = strings.TrimSpace(want)
+	if want == "" {
+		if len(accounts) == 1 {
+			return accounts[0], nil
+		}
+		return ESIAAccount{}, &ESIAAccountSelectionError{Accounts: accounts}
+	}
+
+	for _, account := range accounts {
+		if account.ID == want {
+			return account, nil
+		}
+	}
+
+	var matches []ESIAAccount
+	for _, account := range accounts {
+		if strings.EqualFold(account.Name, want) || (account.SchoolID != 0 && strconv.Itoa(account.SchoolID) == want) {
+			matches = append(matches, account)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return ESIAAccount{}, ErrESIAAccountNotFound
+	case 1:
+		return matches[0], nil
+	default:
+		return ESIAAccount{}, &ESIAAccountSelectionError{Accounts: matches}
+	}
+}
@@ -0,0 +1,39 @@
+package api_types_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/api_types"
+)
+
+func TestChooseESIAAccount(t *testing.T) {
+	parent := api_types.ESIAAccount{ID: "11", Name: "Иванова Мария", School: "Школа №1", SchoolID: 1}
+	student := api_types.ESIAAccount{ID: "12", Name: "Иванов Петр", School: "Школа №42", SchoolID: 42}
+
+	account, err := api_types.ChooseESIAAccount([]api_types.ESIAAccount{parent}, "")
+	require.NoError(t, err)
+	assert.Equal(t, parent, account)
+
+	account, err = api_types.ChooseESIAAccount([]api_types.ESIAAccount{parent, student}, "12")
+	require.NoError(t, err)
+	assert.Equal(t, student, account)
+
+	account, err = api_types.ChooseESIAAccount([]api_types.ESIAAccount{parent, student}, "иванова мария")
+	require.NoError(t, err)
+	assert.Equal(t, parent, account)
+
+	account, err = api_types.ChooseESIAAccount([]api_types.ESIAAccount{parent, student}, "42")
+	require.NoError(t, err)
+	assert.Equal(t, student, account)
+
+	_, err = api_types.ChooseESIAAccount([]api_types.ESIAAccount{parent, student}, "")
+	var selection *api_types.ESIAAccountSelectionError
+	require.ErrorAs(t, err, &selection)
+	assert.ErrorIs(t, err, api_types.ErrESIAAccountSelectionRequired)
+	assert.Len(t, selection.Accounts, 2)
+
+	_, err = api_types.ChooseESIAAccount([]api_types.ESIAAccount{parent, student}, "99")
+	assert.ErrorIs(t, err, api_types.ErrESIAAccountNotFound)
+}
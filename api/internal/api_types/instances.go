@@ -0,0 +1,202 @@
+package api_types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+var (
+	ErrInstanceNotAllowed    = errors.New("NetSchool instance is not allowed")
+	ErrInvalidInstanceURL    = errors.New("invalid instance_url")
+	ErrPrivateNetworkBlocked = errors.New("NetSchool instance resolves to a private network address")
+	ErrInstanceMismatch      = errors.New("instance_url does not match the session instance")
+)
+
+var privateNetworks = mustParseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+func IsPrivateAddress(ip net.IP) bool {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func NormalizeInstanceURL(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return "", fmt.Errorf("%w: empty", ErrInvalidInstanceURL)
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidInstanceURL, err)
+	}
+	scheme := strings.ToLower(parsed.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return "", fmt.Errorf("%w: unsupported scheme %q", ErrInvalidInstanceURL, parsed.Scheme)
+	}
+	if parsed.User != nil {
+		return "", fmt.Errorf("%w: credentials are not allowed", ErrInvalidInstanceURL)
+	}
+	if parsed.RawQuery != "" || parsed.Fragment != "" || strings.Trim(parsed.Path, "/") != "" {
+		return "", fmt.Errorf("%w: path and query are not allowed", ErrInvalidInstanceURL)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if host == "" {
+		return "", fmt.Errorf("%w: missing host", ErrInvalidInstanceURL)
+	}
+	port := parsed.Port()
+	if (scheme == "https" && port == "443") || (scheme == "http" && port == "80") {
+		port = ""
+	}
+
+	hostPort := host
+	if strings.Contains(host, ":") {
+		hostPort = "[" + host + "]"
+	}
+	if port != "" {
+		hostPort = net.JoinHostPort(host, port)
+	}
+	return scheme + "://" + hostPort, nil
+}
+
+type InstanceAllowlist struct {
+	instances    map[string]bool
+	allowPrivate bool
+}
+
+func NewInstanceAllowlist(instances []string, allowPrivate bool) (*InstanceAllowlist, error) {
+	allowlist := &InstanceAllowlist{
+		instances:    make(map[string]bool, len(instances)),
+		allowPrivate: allowPrivate,
+	}
+	for _, instance := range instances {
+		normalized, err := NormalizeInstanceURL(instance)
+		if err != nil {
+			return nil, fmt.Errorf("allowed instance %q: %w", instance, err)
+		}
+		allowlist.instances[normalized] = true
+	}
+	return allowlist, nil
+}
+
+func (a *InstanceAllowlist) Instances() []string {
+	instances := make([]string, 0, len(a.instances))
+	for instance := range a.instances {
+		instances = append(instances, instance)
+	}
+	sort.Strings(instances)
+	return instances
+}
+
+func (a *InstanceAllowlist) Check(rawURL string) (string, error) {
+	normalized, err := NormalizeInstanceURL(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if a == nil {
+		return "", fmt.Errorf("%w: %s", ErrInstanceNotAllowed, normalized)
+	}
+	if !a.instances[normalized] {
+		return "", fmt.Errorf("%w: %s", ErrInstanceNotAllowed, normalized)
+	}
+	if !a.allowPrivate {
+		parsed, _ := url.Parse(normalized)
+		if ip := net.ParseIP(parsed.Hostname()); ip != nil && IsPrivateAddress(ip) {
+			return "", fmt.Errorf("%w: %s", ErrPrivateNetworkBlocked, normalized)
+		}
+	}
+	return normalized, nil
+}
+
+func (a *InstanceAllowlist) dialControl(network, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip != nil && IsPrivateAddress(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateNetworkBlocked, ip)
+	}
+	return nil
+}
+
+
+
+type instanceGuardTransport struct {
+	base         http.RoundTripper
+	allowlist    *InstanceAllowlist
+	resolveHosts bool
+}
+
+func (t *instanceGuardTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	instance := req.URL.Scheme + "://" + req.URL.Host
+	if _, err := t.allowlist.Check(instance); err != nil {
+		return nil, err
+	}
+	if t.resolveHosts {
+		if err := checkResolvedHost(req.Context(), req.URL.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+
+
+func checkResolvedHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if IsPrivateAddress(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrPrivateNetworkBlocked, host, addr.IP)
+		}
+	}
+	return nil
+}
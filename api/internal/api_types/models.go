@@ -0,0 +1,329 @@
+package api_types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+
+
+var ErrUnexpectedPayload = errors.New("unexpected NetSchool payload")
+
+
+
+type payloadValidator interface {
+	validate() error
+}
+
+
+
+func decodePayload(endpoint string, body []byte, target interface{}) error {
+	if err := json.Unmarshal(body, target); err != nil {
+		if isHTMLBody(body) {
+			return fmt.Errorf("%w from %s: HTML page instead of JSON", ErrUnexpectedPayload, endpoint)
+		}
+		return fmt.Errorf("%w from %s: %v", ErrUnexpectedPayload, endpoint, err)
+	}
+	if v, ok := target.(payloadValidator); ok {
+		if err := v.validate(); err != nil {
+			return fmt.Errorf("%w from %s: %v", ErrUnexpectedPayload, endpoint, err)
+		}
+	}
+	return nil
+}
+
+
+func parseNetSchoolDate(value string) (time.Time, error) {
+	if len(value) > len("2006-01-02") {
+		value = value[:len("2006-01-02")]
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+
+type SchoolYear struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+}
+
+func (y *SchoolYear) Period() (time.Time, time.Time, error) {
+	start, err := parseNetSchoolDate(y.StartDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid school year start: %w", err)
+	}
+	end, err := parseNetSchoolDate(y.EndDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid school year end: %w", err)
+	}
+	return start, end, nil
+}
+
+func (y *SchoolYear) validate() error {
+	if y.ID == 0 {
+		return errors.New("school year has no id")
+	}
+	return nil
+}
+
+
+type Term struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+}
+
+
+func (t Term) Contains(date time.Time) bool {
+	start, err := parseNetSchoolDate(t.StartDate)
+	if err != nil {
+		return false
+	}
+	end, err := parseNetSchoolDate(t.EndDate)
+	if err != nil {
+		return false
+	}
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	return !day.Before(start) && !day.After(end)
+}
+
+
+func (t Term) Period() (time.Time, time.Time, error) {
+	start, err := parseNetSchoolDate(t.StartDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid term start: %w", err)
+	}
+	end, err := parseNetSchoolDate(t.EndDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid term end: %w", err)
+	}
+	return start, end, nil
+}
+
+
+type schoolYearKey struct{}
+
+
+
+func WithSchoolYear(ctx context.Context, yearID int) context.Context {
+	if yearID == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, schoolYearKey{}, yearID)
+}
+
+
+func SchoolYearFromContext(ctx context.Context) (int, bool) {
+	yearID, ok := ctx.Value(schoolYearKey{}).(int)
+	return yearID, ok && yearID != 0
+}
+
+
+type MySettings struct {
+	UserID       int    `json:"userId"`
+	FirstName    string `json:"firstName"`
+	LastName     string `json:"lastName"`
+	MiddleName   string `json:"middleName"`
+	BirthDate    string `json:"birthDate"`
+	LoginName    string `json:"loginName"`
+	Email        string `json:"email"`
+	MobilePhone  string `json:"mobilePhone"`
+	SchoolYearID int    `json:"schoolyearId"`
+}
+
+func (s *MySettings) validate() error {
+	if s.UserID == 0 {
+		return errors.New("user settings have no userId")
+	}
+	return nil
+}
+
+
+type Diary struct {
+	WeekStart string     `json:"weekStart"`
+	WeekEnd   string     `json:"weekEnd"`
+	WeekDays  []DiaryDay `json:"weekDays"`
+	TermName  string     `json:"termName"`
+	ClassName string     `json:"className"`
+}
+
+func (d *Diary) validate() error {
+	if d.WeekStart == "" {
+		return errors.New("diary has no weekStart")
+	}
+	return nil
+}
+
+type DiaryDay struct {
+	Date    string   `json:"date"`
+	Lessons []Lesson `json:"lessons"`
+}
+
+type Lesson struct {
+	ClassmeetingID int          `json:"classmeetingId"`
+	Day            string       `json:"day"`
+	Number         int          `json:"number"`
+	Relay          int          `json:"relay"`
+	Room           string       `json:"room"`
+	StartTime      string       `json:"startTime"`
+	EndTime        string       `json:"endTime"`
+	SubjectID      int          `json:"subjectId,omitempty"`
+	SubjectName    string       `json:"subjectName"`
+	Assignments    []Assignment `json:"assignments"`
+}
+
+type Assignment struct {
+	ID              int    `json:"id"`
+	TypeID          int    `json:"typeId"`
+	AssignmentName  string `json:"assignmentName"`
+	Weight          int    `json:"weight"`
+	DueDate         string `json:"dueDate"`
+	ClassAssignment bool   `json:"classAssignment"`
+	Mark            *Mark  `json:"mark"`
+}
+
+
+type Mark struct {
+	AssignmentID int        `json:"assignmentId"`
+	StudentID    int        `json:"studentId"`
+	Mark         flexibleID `json:"mark"`
+	ResultScore  *float64   `json:"resultScore"`
+	DutyMark     bool       `json:"dutyMark"`
+}
+
+
+
+type Grade struct {
+	AssignmentID   int    `json:"assignment_id"`
+	AssignmentName string `json:"assignment_name"`
+	TypeID         int    `json:"type_id"`
+	SubjectID      int    `json:"subject_id,omitempty"`
+	SubjectName    string `json:"subject_name"`
+	Date           string `json:"date"`
+	Mark           string `json:"mark"`
+	Weight         int    `json:"weight"`
+	DutyMark       bool   `json:"duty_mark"`
+}
+
+
+func (d *Diary) Grades() []Grade {
+	grades := make([]Grade, 0)
+	for _, day := range d.WeekDays {
+		for _, lesson := range day.Lessons {
+			for _, assignment := range lesson.Assignments {
+				if assignment.Mark == nil || assignment.Mark.Mark == "" {
+					continue
+				}
+				grades = append(grades, Grade{
+					AssignmentID:   assignment.ID,
+					AssignmentName: assignment.AssignmentName,
+					TypeID:         assignment.TypeID,
+					SubjectID:      lesson.SubjectID,
+					SubjectName:    lesson.SubjectName,
+					Date:           day.Date,
+					Mark:           string(assignment.Mark.Mark),
+					Weight:         assignment.Weight,
+					DutyMark:       assignment.Mark.DutyMark,
+				})
+			}
+		}
+	}
+	return grades
+}
+
+
+type IDName struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+
+type AssignmentDetails struct {
+	ID             int          `json:"id"`
+	AssignmentName string       `json:"assignmentName"`
+	ActivityName   string       `json:"activityName"`
+	ProblemName    string       `json:"problemName"`
+	SubjectGroup   IDName       `json:"subjectGroup"`
+	Teachers       []IDName     `json:"teachers"`
+	Weight         int          `json:"weight"`
+	Date           string       `json:"date"`
+	Description    string       `json:"description"`
+	Attachments    []Attachment `json:"attachments"`
+}
+
+func (a *AssignmentDetails) validate() error {
+	if a.ID == 0 {
+		return errors.New("assignment has no id")
+	}
+	return nil
+}
+
+type Attachment struct {
+	ID               int    `json:"id"`
+	Name             string `json:"name"`
+	OriginalFileName string `json:"originalFileName"`
+	Description      string `json:"description"`
+}
+
+
+type AssignmentType struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Abbr  string `json:"abbr"`
+	Order int    `json:"order"`
+}
+
+
+type SchoolInfo struct {
+	CommonInfo     SchoolCommonInfo     `json:"commonInfo"`
+	ContactInfo    SchoolContactInfo    `json:"contactInfo"`
+	ManagementInfo SchoolManagementInfo `json:"managementInfo"`
+}
+
+func (s *SchoolInfo) validate() error {
+	if s.CommonInfo.SchoolName == "" && s.CommonInfo.FullSchoolName == "" {
+		return errors.New("school card has no name")
+	}
+	return nil
+}
+
+type SchoolCommonInfo struct {
+	SchoolName     string `json:"schoolName"`
+	FullSchoolName string `json:"fullSchoolName"`
+	About          string `json:"about"`
+}
+
+type SchoolContactInfo struct {
+	JuridicalAddress string `json:"juridicalAddress"`
+	PostAddress      string `json:"postAddress"`
+	Phones           string `json:"phones"`
+	Email            string `json:"email"`
+	Site             string `json:"web"`
+}
+
+type SchoolManagementInfo struct {
+	Director string `json:"director"`
+}
+
+
+type Class struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+
+func filterGradesBySubject(grades []Grade, subject string) []Grade {
+	filtered := make([]Grade, 0)
+	for _, grade := range grades {
+		if strconv.Itoa(grade.SubjectID) == subject || grade.SubjectName == subject {
+			filtered = append(filtered, grade)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,219 @@
+package api_types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+
+var maintenanceMarkers = []string{
+	"технические работы",
+	"технических работ",
+	"профилактическ",
+	"обновление системы",
+	"under maintenance",
+	"scheduled maintenance",
+	"maintenance mode",
+}
+
+var sessionExpiredMarkers = []string{
+	"сеанс завершен",
+	"сеанс работы завершен",
+	"сессия истекла",
+	"время сессии истекло",
+	"session expired",
+	"sessionexpired",
+}
+
+var invalidCredentialsMarkers = []string{
+	"неправильный пароль",
+	"неверный пароль",
+	"неправильное имя пользователя",
+	"неверное имя пользователя",
+	"неверный логин",
+	"invalid username or password",
+	"invalid login or password",
+}
+
+var tooManyAttemptsMarkers = []string{
+	"превышено количество попыток",
+	"превышено число попыток",
+	"слишком много попыток",
+	"слишком много неудачных",
+	"too many login attempts",
+	"too many failed login",
+}
+
+
+
+
+
+
+func ClassifyResponse(resp *http.Response, body []byte) error {
+	html := looksLikeHTML(resp, body)
+	var text string
+	if html || resp.StatusCode >= http.StatusBadRequest {
+		text = responseText(resp, body)
+	}
+	if err := classifyUnavailable(resp, html, text); err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("%w: NetSchool rejected the access token", ErrUnauthorized)
+	}
+	if redirectedToLogin(resp) || (html && containsAny(text, sessionExpiredMarkers)) {
+		return fmt.Errorf("%w: NetSchool session expired", ErrUnauthorized)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		if err := classifyLoginMessage(text); err != nil {
+			return err
+		}
+	}
+	return classifyStatus(resp.StatusCode)
+}
+
+
+
+func classifyStatus(status int) error {
+	switch {
+	case status >= http.StatusOK && status < http.StatusMultipleChoices:
+		return nil
+	case status == http.StatusForbidden:
+		return fmt.Errorf("%w (status %d)", ErrForbidden, status)
+	case status == http.StatusNotFound:
+		return fmt.Errorf("%w (status %d)", ErrNotFound, status)
+	}
+	return fmt.Errorf("%w: status %d", ErrUnexpectedPayload, status)
+}
+
+
+
+func classifyUnavailable(resp *http.Response, html bool, text string) error {
+	if (html || resp.StatusCode == http.StatusServiceUnavailable) && containsAny(text, maintenanceMarkers) {
+		return fmt.Errorf("%w (status %d)", ErrNetSchoolMaintenance, resp.StatusCode)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w (status %d)", ErrNetSchoolUnavailable, resp.StatusCode)
+	}
+	return nil
+}
+
+
+
+func classifyLoginMessage(message string) error {
+	lower := strings.ToLower(message)
+	switch {
+	case containsAny(lower, tooManyAttemptsMarkers):
+		return fmt.Errorf("%w: %s", ErrTooManyLoginAttempts, message)
+	case containsAny(lower, invalidCredentialsMarkers):
+		return fmt.Errorf("%w: %s", ErrInvalidCredentials, message)
+	}
+	return nil
+}
+
+
+
+func interactiveLoginReason(resp *http.Response, body []byte) string {
+	if resp.Request != nil && resp.Request.URL != nil {
+		host := strings.ToLower(resp.Request.URL.Host)
+		if strings.Contains(host, "esia") || strings.Contains(host, "gosuslugi") {
+			return "redirected to ESIA"
+		}
+	}
+	if strings.Contains(strings.ToLower(resp.Header.Get("Content-Type")), "text/html") {
+		return "login page returned HTML instead of JSON"
+	}
+	lower := strings.ToLower(string(body))
+	if strings.Contains(lower, "captcha") || strings.Contains(lower, "капч") {
+		return "captcha required"
+	}
+	if strings.Contains(lower, "esia") || strings.Contains(lower, "госуслуг") {
+		return "ESIA login required"
+	}
+	return ""
+}
+
+
+func readResponse(resp *http.Response) ([]byte, error) {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if err := ClassifyResponse(resp, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+
+
+
+func responseText(resp *http.Response, body []byte) string {
+	if strings.Contains(strings.ToLower(resp.Header.Get("Content-Type")), "1251") {
+		if decoded, err := charmap.Windows1251.NewDecoder().Bytes(body); err == nil {
+			body = decoded
+		}
+	}
+
+	var payload struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &payload) == nil && payload.Message != "" {
+		return strings.ToLower(payload.Message)
+	}
+	return strings.ToLower(string(body))
+}
+
+func looksLikeHTML(resp *http.Response, body []byte) bool {
+	if strings.Contains(strings.ToLower(resp.Header.Get("Content-Type")), "text/html") {
+		return true
+	}
+	return isHTMLBody(body)
+}
+
+func isHTMLBody(body []byte) bool {
+	prefix := bytes.ToLower(bytes.TrimSpace(body))
+	return bytes.HasPrefix(prefix, []byte("<!doctype html")) || bytes.HasPrefix(prefix, []byte("<html"))
+}
+
+
+
+
+func redirectedToLogin(resp *http.Response) bool {
+	if resp.Request == nil || resp.Request.Response == nil || resp.Request.URL == nil {
+		return false
+	}
+	original := resp.Request
+	for original.Response != nil && original.Response.Request != nil {
+		original = original.Response.Request
+	}
+	final := resp.Request.URL
+	if original.URL != nil && original.URL.Path == final.Path {
+		return false
+	}
+	host := strings.ToLower(final.Host)
+	path := strings.ToLower(strings.TrimRight(final.Path, "/"))
+	return path == "" ||
+		strings.Contains(path, "login") ||
+		strings.Contains(path, "about.html") ||
+		strings.Contains(path, "authorize") ||
+		strings.Contains(host, "esia") ||
+		strings.Contains(host, "gosuslugi")
+}
+
+func containsAny(text string, markers []string) bool {
+	for _, marker := range markers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package api_types_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/api_types"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "netschool", name))
+	require.NoError(t, err)
+	return body
+}
+
+func TestClassifyResponse_Fixtures(t *testing.T) {
+	cases := []struct {
+		fixture     string
+		status      int
+		contentType string
+		want        error
+		code        api_types.ErrorCode
+	}{
+		{"maintenance.html", http.StatusServiceUnavailable, "text/html; charset=utf-8", api_types.ErrNetSchoolMaintenance, api_types.ErrCodeNetSchoolMaintenance},
+		{"maintenance.html", http.StatusOK, "text/html; charset=utf-8", api_types.ErrNetSchoolMaintenance, api_types.ErrCodeNetSchoolMaintenance},
+		{"maintenance_cp1251.html", http.StatusOK, "text/html; charset=windows-1251", api_types.ErrNetSchoolMaintenance, api_types.ErrCodeNetSchoolMaintenance},
+		{"session_expired.html", http.StatusOK, "text/html", api_types.ErrUnauthorized, api_types.ErrCodeSessionExpired},
+		{"unauthorized.json", http.StatusUnauthorized, "application/json", api_types.ErrUnauthorized, api_types.ErrCodeSessionExpired},
+		{"wrong_password.json", http.StatusBadRequest, "application/json", api_types.ErrInvalidCredentials, api_types.ErrCodeInvalidCredentials},
+		{"too_many_attempts.json", http.StatusConflict, "application/json", api_types.ErrTooManyLoginAttempts, api_types.ErrCodeAccountLocked},
+		{"diary_init.json", http.StatusBadGateway, "application/json", api_types.ErrNetSchoolUnavailable, api_types.ErrCodeNetSchoolTemporarilyUnavailable},
+		{"diary_init.json", http.StatusForbidden, "application/json", api_types.ErrForbidden, api_types.ErrCodeForbidden},
+		{"ordinary_page.html", http.StatusNotFound, "text/html; charset=utf-8", api_types.ErrNotFound, api_types.ErrCodeNotFound},
+		{"ordinary_page.html", http.StatusBadRequest, "text/html; charset=utf-8", api_types.ErrUnexpectedPayload, api_types.ErrCodeNetSchoolAPIError},
+		{"diary_init.json", http.StatusTeapot, "application/json", api_types.ErrUnexpectedPayload, api_types.ErrCodeNetSchoolAPIError},
+	}
+
+	for _, tc := range cases {
+		resp := &http.Response{StatusCode: tc.status, Header: http.Header{"Content-Type": {tc.contentType}}}
+		err := api_types.ClassifyResponse(resp, readFixture(t, tc.fixture))
+		assert.ErrorIs(t, err, tc.want, tc.fixture)
+		assert.Equal(t, tc.code, api_types.ErrorCodeOf(err), tc.fixture)
+	}
+
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": {"application/json"}}}
+	assert.NoError(t, api_types.ClassifyResponse(resp, readFixture(t, "diary_init.json")))
+
+	resp = &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": {"text/html; charset=utf-8"}}}
+	assert.NoError(t, api_types.ClassifyResponse(resp, readFixture(t, "ordinary_page.html")))
+}
+
+func newFixtureServer(t *testing.T, routes map[string]func(w http.ResponseWriter, r *http.Request)) (*httptest.Server, api_types.APIClientInterface) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if handler, ok := routes[r.URL.Path]; ok {
+			handler(w, r)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	factory := &api_types.APIClientFactory{}
+	client, err := factory.NewAPIClient(api_types.NSWebAPI, api_types.APIConfig{Mode: api_types.NSWebAPI, Timeout: 5})
+	require.NoError(t, err)
+	return server, client
+}
+
+func serveFixture(t *testing.T, status int, contentType, fixture string) func(w http.ResponseWriter, r *http.Request) {
+	body := readFixture(t, fixture)
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		w.Write(body)
+	}
+}
+
+func TestNSWebAPIClient_LoginFailures(t *testing.T) {
+	loginData := map[string]interface{}{"salt": "123", "lt": "1", "ver": "1"}
+	cases := []struct {
+		status  int
+		fixture string
+		want    error
+	}{
+		{http.StatusBadRequest, "wrong_password.json", api_types.ErrInvalidCredentials},
+		{http.StatusConflict, "too_many_attempts.json", api_types.ErrTooManyLoginAttempts},
+	}
+
+	for _, tc := range cases {
+		server, client := newFixtureServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+			"/webapi/logindata": func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(`{}`)) },
+			"/webapi/login":     serveFixture(t, tc.status, "application/json; charset=utf-8", tc.fixture),
+		})
+
+		_, err := client.Login(context.Background(), "user", "secret", 1, server.URL, loginData)
+		assert.ErrorIs(t, err, tc.want, tc.fixture)
+	}
+
+	server, client := newFixtureServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"/webapi/logindata": func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(`{}`)) },
+		"/webapi/login":     serveFixture(t, http.StatusServiceUnavailable, "text/html; charset=utf-8", "maintenance.html"),
+	})
+	_, err := client.Login(context.Background(), "user", "secret", 1, server.URL, loginData)
+	assert.ErrorIs(t, err, api_types.ErrNetSchoolMaintenance)
+	assert.NotErrorIs(t, err, api_types.ErrInteractiveLoginRequired)
+}
+
+func TestNSWebAPIClient_ClassifiesDataResponses(t *testing.T) {
+	server, client := newFixtureServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"/webapi/student/diary/init": serveFixture(t, http.StatusOK, "text/html; charset=utf-8", "maintenance.html"),
+	})
+	_, err := client.GetStudents(context.Background(), "token", server.URL)
+	assert.ErrorIs(t, err, api_types.ErrNetSchoolMaintenance)
+
+	server, client = newFixtureServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"/webapi/mysettings": func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/about.html", http.StatusFound)
+		},
+		"/about.html": serveFixture(t, http.StatusOK, "text/html; charset=utf-8", "login_page.html"),
+	})
+	_, err = client.GetInfo(context.Background(), "token", server.URL)
+	assert.ErrorIs(t, err, api_types.ErrUnauthorized)
+	assert.Equal(t, api_types.ErrCodeSessionExpired, api_types.ErrorCodeOf(err))
+
+	server, client = newFixtureServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"/webapi/years/current": serveFixture(t, http.StatusOK, "text/html; charset=utf-8", "session_expired.html"),
+	})
+	_, err = client.GetCurrentYear(context.Background(), "token", server.URL)
+	assert.ErrorIs(t, err, api_types.ErrUnauthorized)
+}
+
+func TestNSWebAPIClient_ClassifiesClientErrors(t *testing.T) {
+	cases := []struct {
+		status int
+		want   error
+		code   api_types.ErrorCode
+	}{
+		{http.StatusForbidden, api_types.ErrForbidden, api_types.ErrCodeForbidden},
+		{http.StatusNotFound, api_types.ErrNotFound, api_types.ErrCodeNotFound},
+		{http.StatusConflict, api_types.ErrUnexpectedPayload, api_types.ErrCodeNetSchoolAPIError},
+	}
+
+	for _, tc := range cases {
+		server, client := newFixtureServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+			"/webapi/classes":            serveFixture(t, tc.status, "application/json", "diary_init.json"),
+			"/webapi/student/diary/init": serveFixture(t, tc.status, "application/json", "diary_init.json"),
+		})
+
+		_, err := client.GetClasses(context.Background(), "token", server.URL)
+		assert.ErrorIs(t, err, tc.want, tc.status)
+		assert.Equal(t, tc.code, api_types.ErrorCodeOf(err), tc.status)
+
+		_, err = client.GetStudents(context.Background(), "token", server.URL)
+		assert.ErrorIs(t, err, tc.want, tc.status)
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -15,66 +17,57 @@ import (
 
 
 type NSMobileAPIClient struct {
-	timeout   time.Duration
-	retryMax  int
-	retryWait time.Duration
+	timeout    time.Duration
+	retryMax   int
+	retryWait  time.Duration
+	httpClient *http.Client
 }
 
+func init() {
+	DefaultRegistry.MustRegister(Provider{
+		Mode:        NSMobileAPI,
+		Name:        "NetSchool Mobile API",
+		Description: "Мобильный API Сетевого Города с входом через OAuth device-code",
+		Capabilities: []Capability{
+			CapabilityStudentInfo, CapabilityStudents, CapabilityGrades, CapabilitySchedule,
+			CapabilitySchoolInfo, CapabilityClasses, CapabilityDiary, CapabilityAssignments,
+			CapabilityAssignmentTypes, CapabilityFiles, CapabilityReports, CapabilityJournal,
+			CapabilityInfo, CapabilityPhoto, CapabilityHealth, CapabilityTokenRefresh,
+		},
+		Login: LoginRequirements{
+			Methods:             []LoginMethod{LoginMethodDeviceCode, LoginMethodESIA},
+			RequiresSchoolID:    true,
+			RequiresInstanceURL: true,
+		},
+		ConfigSchema: netSchoolConfigSchema,
+		UserAgent:    "NetSchoolApp/1.0",
+		New:          newNSMobileAPIClient,
+	})
+}
 
-func (c *NSMobileAPIClient) Login(ctx context.Context, username, password string, schoolID int, instanceURL string, loginData map[string]interface{}) (string, error) {
-	client := &http.Client{Timeout: c.timeout}
-
-	
-	deviceCodeURL := fmt.Sprintf("%s/connect/deviceauthorization", instanceURL)
-
-	deviceCodeData := url.Values{}
-	deviceCodeData.Set("client_id", "parent-mobile")
-	deviceCodeData.Set("scope", "mobile-api")
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, deviceCodeURL, strings.NewReader(deviceCodeData.Encode()))
-	if err != nil {
-		return "", fmt.Errorf("failed to create device code request: %w", err)
+func newNSMobileAPIClient(config APIConfig, httpClient *http.Client) (APIClientInterface, error) {
+	client := &NSMobileAPIClient{
+		timeout:    time.Duration(config.Timeout) * time.Second,
+		retryMax:   config.RetryMax,
+		retryWait:  time.Duration(config.RetryWait) * time.Millisecond,
+		httpClient: httpClient,
 	}
+	return client, nil
+}
 
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("User-Agent", "NetSchoolApp/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to get device code: %w", err)
-	}
 
-	deviceCodeBody, err := io.ReadAll(resp.Body)
-	resp.Body.Close()
+func (c *NSMobileAPIClient) Login(ctx context.Context, username, password string, schoolID int, instanceURL string, loginData map[string]interface{}) (string, error) {
+	authorization, err := c.StartDeviceAuthorization(ctx, instanceURL)
 	if err != nil {
-		return "", fmt.Errorf("failed to read device code response: %w", err)
+		return "", err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to get device code, status: %d, body: %s", resp.StatusCode, string(deviceCodeBody))
-	}
-
-	var deviceCodeResp struct {
-		DeviceCode      string `json:"device_code"`
-		UserCode        string `json:"user_code"`
-		VerificationURI string `json:"verification_uri"`
-		ExpiresIn       int    `json:"expires_in"`
-		Interval        int    `json:"interval"`
-	}
-
-	if err := json.Unmarshal(deviceCodeBody, &deviceCodeResp); err != nil {
-		return "", fmt.Errorf("failed to parse device code response: %w", err)
-	}
-
-	
-	tokenURL := fmt.Sprintf("%s/connect/token", instanceURL)
-
 	
-	maxAttempts := deviceCodeResp.ExpiresIn / deviceCodeResp.Interval
-	if maxAttempts == 0 {
-		maxAttempts = 100 
+	maxAttempts := 100
+	if authorization.Interval > 0 && authorization.ExpiresIn > 0 {
+		maxAttempts = authorization.ExpiresIn / authorization.Interval
 	}
-	interval := time.Duration(deviceCodeResp.Interval) * time.Second
+	interval := time.Duration(authorization.Interval) * time.Second
 	if interval == 0 {
 		interval = 5 * time.Second 
 	}
@@ -84,70 +77,17 @@ func (c *NSMobileAPIClient) Login(ctx context.Context, username, password string
 		case <-ctx.Done():
 			return "", ctx.Err()
 		case <-time.After(interval):
-			tokenData := url.Values{}
-			tokenData.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
-			tokenData.Set("device_code", deviceCodeResp.DeviceCode)
-			tokenData.Set("client_id", "parent-mobile")
-			tokenData.Set("client_secret", "04064338-13df-4747-8dea-69849f9ecdf0")
-
-			tokenReq, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(tokenData.Encode()))
-			if err != nil {
-				return "", fmt.Errorf("failed to create token request: %w", err)
-			}
-
-			tokenReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-			tokenReq.Header.Set("User-Agent", "NetSchoolApp/1.0")
-
-			tokenResp, err := client.Do(tokenReq)
-			if err != nil {
-				return "", fmt.Errorf("failed to get token: %w", err)
-			}
-
-			tokenBody, err := io.ReadAll(tokenResp.Body)
-			tokenResp.Body.Close()
-			if err != nil {
-				return "", fmt.Errorf("failed to read token response: %w", err)
-			}
-
-			if tokenResp.StatusCode == http.StatusOK {
-				
-				var tokenResult struct {
-					AccessToken  string `json:"access_token"`
-					TokenType    string `json:"token_type"`
-					ExpiresIn    int    `json:"expires_in"`
-					RefreshToken string `json:"refresh_token"`
-				}
-
-				if err := json.Unmarshal(tokenBody, &tokenResult); err != nil {
-					return "", fmt.Errorf("failed to parse token response: %w", err)
-				}
-
-				return tokenResult.AccessToken, nil
-			} else if tokenResp.StatusCode == http.StatusBadRequest {
-				
-				var errorResult struct {
-					Error            string `json:"error"`
-					ErrorDescription string `json:"error_description"`
-				}
-
-				if err := json.Unmarshal(tokenBody, &errorResult); err != nil {
-					return "", fmt.Errorf("failed to parse error response: %w", err)
-				}
-
-				if errorResult.Error == "authorization_pending" {
-					
-					continue
-				} else if errorResult.Error == "slow_down" {
-					
-					interval += 5 * time.Second
-					continue
-				} else if errorResult.Error == "expired_token" {
-					return "", fmt.Errorf("device code expired: %s", errorResult.ErrorDescription)
-				} else {
-					return "", fmt.Errorf("token request failed: %s - %s", errorResult.Error, errorResult.ErrorDescription)
-				}
-			} else {
-				return "", fmt.Errorf("token request failed with status %d: %s", tokenResp.StatusCode, string(tokenBody))
+			token, err := c.PollDeviceToken(ctx, instanceURL, authorization.DeviceCode)
+			switch {
+			case err == nil:
+				return token.AccessToken, nil
+			case errors.Is(err, ErrDeviceAuthorizationPending):
+				continue
+			case errors.Is(err, ErrDeviceSlowDown):
+				interval += 5 * time.Second
+				continue
+			default:
+				return "", err
 			}
 		}
 	}
@@ -156,180 +96,194 @@ func (c *NSMobileAPIClient) Login(ctx context.Context, username, password string
 }
 
 
-func (c *NSMobileAPIClient) GetLoginData(ctx context.Context, instanceURL string) (map[string]interface{}, error) {
-	
-	loginData := map[string]interface{}{
-		"auth_method": "oauth_device_flow",
-		"client_id":   "parent-mobile",
-		"scopes":      []string{"mobile-api"},
-		"instance_url": instanceURL,
-	}
-
-	return loginData, nil
-}
+func (c *NSMobileAPIClient) StartDeviceAuthorization(ctx context.Context, instanceURL string) (*DeviceAuthorization, error) {
+	client := c.httpClient
 
+	
+	deviceCodeURL := fmt.Sprintf("%s/connect/deviceauthorization", instanceURL)
 
-func (c *NSMobileAPIClient) GetStudentInfo(ctx context.Context, userID, instanceURL string) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
+	deviceCodeData := url.Values{}
+	deviceCodeData.Set("client_id", "parent-mobile")
+	deviceCodeData.Set("scope", "mobile-api")
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/students", instanceURL), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, deviceCodeURL, strings.NewReader(deviceCodeData.Encode()))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create device code request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+userID)
-	req.Header.Set("User-Agent", "NetSchoolApp/1.0")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, fmt.Errorf("failed to get device code: %w", err)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	deviceCodeBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read device code response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get student info, status: %d, body: %s", resp.StatusCode, string(body))
+		if err := ClassifyResponse(resp, deviceCodeBody); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: failed to get device code, status: %d, body: %s", ErrUnexpectedPayload, resp.StatusCode, string(deviceCodeBody))
 	}
 
-	var result interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	var authorization DeviceAuthorization
+	if err := json.Unmarshal(deviceCodeBody, &authorization); err != nil {
+		return nil, fmt.Errorf("failed to parse device code response: %w", err)
 	}
 
-	return result, nil
+	return &authorization, nil
 }
 
 
-func (c *NSMobileAPIClient) GetGrades(ctx context.Context, userID, studentID, instanceURL string) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
+func (c *NSMobileAPIClient) PollDeviceToken(ctx context.Context, instanceURL, deviceCode string) (*OAuthToken, error) {
+	client := c.httpClient
+
+	tokenURL := fmt.Sprintf("%s/connect/token", instanceURL)
+
+	tokenData := url.Values{}
+	tokenData.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
+	tokenData.Set("device_code", deviceCode)
+	tokenData.Set("client_id", "parent-mobile")
+	tokenData.Set("client_secret", "04064338-13df-4747-8dea-69849f9ecdf0")
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/grades", instanceURL), nil)
+	tokenReq, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(tokenData.Encode()))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create token request: %w", err)
 	}
 
-	
-	q := req.URL.Query()
-	q.Set("studentId", studentID)
-	req.URL.RawQuery = q.Encode()
-
-	req.Header.Set("Authorization", "Bearer "+userID)
-	req.Header.Set("User-Agent", "NetSchoolApp/1.0")
+	tokenReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := client.Do(req)
+	tokenResp, err := client.Do(tokenReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, fmt.Errorf("failed to get token: %w", err)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	tokenBody, err := io.ReadAll(tokenResp.Body)
+	tokenResp.Body.Close()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read token response: %w", err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get grades, status: %d, body: %s", resp.StatusCode, string(body))
-	}
+	switch tokenResp.StatusCode {
+	case http.StatusOK:
+		var token OAuthToken
+		if err := json.Unmarshal(tokenBody, &token); err != nil {
+			return nil, fmt.Errorf("failed to parse token response: %w", err)
+		}
+		if token.AccessToken == "" {
+			return nil, fmt.Errorf("token response does not contain access_token")
+		}
+		return &token, nil
+	case http.StatusBadRequest:
+		var errorResult struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
 
-	var result interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
+		if err := json.Unmarshal(tokenBody, &errorResult); err != nil {
+			return nil, fmt.Errorf("failed to parse error response: %w", err)
+		}
 
-	return result, nil
+		switch errorResult.Error {
+		case "authorization_pending":
+			return nil, ErrDeviceAuthorizationPending
+		case "slow_down":
+			return nil, ErrDeviceSlowDown
+		case "expired_token":
+			return nil, fmt.Errorf("%w: %s", ErrDeviceCodeExpired, errorResult.ErrorDescription)
+		default:
+			return nil, fmt.Errorf("token request failed: %s - %s", errorResult.Error, errorResult.ErrorDescription)
+		}
+	default:
+		if err := ClassifyResponse(tokenResp, tokenBody); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: token request failed with status %d: %s", ErrUnexpectedPayload, tokenResp.StatusCode, string(tokenBody))
+	}
 }
 
 
-func (c *NSMobileAPIClient) GetSchedule(ctx context.Context, userID, instanceURL string, weekStart time.Time) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
+func (c *NSMobileAPIClient) RefreshAccessToken(ctx context.Context, instanceURL, refreshToken string) (*OAuthToken, error) {
+	client := c.httpClient
+
+	tokenData := url.Values{}
+	tokenData.Set("grant_type", "refresh_token")
+	tokenData.Set("refresh_token", refreshToken)
+	tokenData.Set("client_id", "parent-mobile")
+	tokenData.Set("client_secret", "04064338-13df-4747-8dea-69849f9ecdf0")
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/classmeetings", instanceURL), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/connect/token", instanceURL), strings.NewReader(tokenData.Encode()))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create refresh request: %w", err)
 	}
 
-	
-	q := req.URL.Query()
-	q.Set("weekStart", weekStart.Format("2006-01-02"))
-	q.Set("weekEnd", weekStart.AddDate(0, 0, 6).Format("2006-01-02"))
-	req.URL.RawQuery = q.Encode()
-
-	req.Header.Set("Authorization", "Bearer "+userID)
-	req.Header.Set("User-Agent", "NetSchoolApp/1.0")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read refresh response: %w", err)
 	}
 
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("%w: refresh token rejected: %s", ErrUnauthorized, string(body))
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get schedule, status: %d, body: %s", resp.StatusCode, string(body))
+		if err := ClassifyResponse(resp, body); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: refresh request failed with status %d: %s", ErrUnexpectedPayload, resp.StatusCode, string(body))
 	}
 
-	var result interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	var token OAuthToken
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse refresh response: %w", err)
 	}
-
-	return result, nil
-}
-
-
-func (c *NSMobileAPIClient) GetSchoolInfo(ctx context.Context, userID, instanceURL string) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/education", instanceURL), nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("refresh response does not contain access_token")
 	}
 
-	req.Header.Set("Authorization", "Bearer "+userID)
-	req.Header.Set("User-Agent", "NetSchoolApp/1.0")
+	return &token, nil
+}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+func (c *NSMobileAPIClient) GetLoginData(ctx context.Context, instanceURL string) (map[string]interface{}, error) {
+	
+	loginData := map[string]interface{}{
+		"auth_method": "oauth_device_flow",
+		"client_id":   "parent-mobile",
+		"scopes":      []string{"mobile-api"},
+		"instance_url": instanceURL,
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get school info, status: %d, body: %s", resp.StatusCode, string(body))
-	}
+	return loginData, nil
+}
 
-	var result interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
 
-	return result, nil
+func (c *NSMobileAPIClient) GetStudentInfo(ctx context.Context, userID, instanceURL string) (*MySettings, error) {
+	return c.GetInfo(ctx, userID, instanceURL)
 }
 
 
-func (c *NSMobileAPIClient) GetClasses(ctx context.Context, userID, instanceURL string) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
+func (c *NSMobileAPIClient) GetStudents(ctx context.Context, userID, instanceURL string) (*StudentList, error) {
+	client := c.httpClient
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/students/class", instanceURL), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/students", instanceURL), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+userID)
-	req.Header.Set("User-Agent", "NetSchoolApp/1.0")
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -337,143 +291,111 @@ func (c *NSMobileAPIClient) GetClasses(ctx context.Context, userID, instanceURL
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponse(resp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get classes, status: %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("%w: failed to get students, status: %d, body: %s", ErrUnexpectedPayload, resp.StatusCode, string(body))
 	}
 
-	var result interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	var students []struct {
+		ID        flexibleID `json:"id"`
+		Name      string     `json:"name"`
+		ClassID   flexibleID `json:"classId"`
+		ClassName string     `json:"className"`
 	}
-
-	return result, nil
-}
-
-
-func (c *NSMobileAPIClient) GetDiary(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/diary", instanceURL), nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	if err := decodePayload("students", body, &students); err != nil {
+		return nil, err
 	}
 
-	
-	q := req.URL.Query()
-	q.Set("studentId", studentID)
-	q.Set("startDate", start.Format("2006-01-02"))
-	q.Set("endDate", end.Format("2006-01-02"))
-	req.URL.RawQuery = q.Encode()
-
-	req.Header.Set("Authorization", "Bearer "+userID)
-	req.Header.Set("User-Agent", "NetSchoolApp/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get diary, status: %d, body: %s", resp.StatusCode, string(body))
+	list := &StudentList{Students: make([]Student, 0, len(students))}
+	for _, student := range students {
+		list.Students = append(list.Students, Student{
+			ID:        string(student.ID),
+			Name:      student.Name,
+			ClassID:   string(student.ClassID),
+			ClassName: student.ClassName,
+		})
 	}
-
-	var result interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if len(list.Students) > 0 {
+		list.CurrentStudentID = list.Students[0].ID
 	}
 
-	return result, nil
+	return list, nil
 }
 
 
-func (c *NSMobileAPIClient) GetAssignment(ctx context.Context, userID, studentID, assignmentID, instanceURL string) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/assignments/%s", instanceURL, assignmentID), nil)
+func (c *NSMobileAPIClient) GetGrades(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time) ([]Grade, error) {
+	diary, err := c.GetDiary(ctx, userID, studentID, instanceURL, start, end)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
+	return diary.Grades(), nil
+}
 
-	
-	q := req.URL.Query()
-	q.Set("studentId", studentID)
-	req.URL.RawQuery = q.Encode()
 
-	req.Header.Set("Authorization", "Bearer "+userID)
-	req.Header.Set("User-Agent", "NetSchoolApp/1.0")
+func (c *NSMobileAPIClient) GetSchedule(ctx context.Context, userID, studentID, instanceURL string, weekStart time.Time) (*Diary, error) {
+	return c.GetDiary(ctx, userID, studentID, instanceURL, weekStart, weekStart.AddDate(0, 0, 6))
+}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+func (c *NSMobileAPIClient) GetSchoolInfo(ctx context.Context, userID string, schoolID int, instanceURL string) (*SchoolInfo, error) {
+	var info SchoolInfo
+	if err := c.getJSON(ctx, userID, instanceURL, "education", nil, &info); err != nil {
+		return nil, err
 	}
+	return &info, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get assignment, status: %d, body: %s", resp.StatusCode, string(body))
-	}
 
-	var result interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+func (c *NSMobileAPIClient) GetClasses(ctx context.Context, userID, instanceURL string) ([]Class, error) {
+	var classes []Class
+	if err := c.getJSON(ctx, userID, instanceURL, "students/class", nil, &classes); err != nil {
+		return nil, err
 	}
-
-	return result, nil
+	return classes, nil
 }
 
 
-func (c *NSMobileAPIClient) GetAssignmentTypes(ctx context.Context, userID, instanceURL string) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
+func (c *NSMobileAPIClient) GetDiary(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time) (*Diary, error) {
+	q := url.Values{}
+	q.Set("studentId", studentID)
+	q.Set("startDate", start.Format("2006-01-02"))
+	q.Set("endDate", end.Format("2006-01-02"))
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/assignmentTypes", instanceURL), nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	var diary Diary
+	if err := c.getJSON(ctx, userID, instanceURL, "diary", q, &diary); err != nil {
+		return nil, err
 	}
+	return &diary, nil
+}
 
-	req.Header.Set("Authorization", "Bearer "+userID)
-	req.Header.Set("User-Agent", "NetSchoolApp/1.0")
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
+func (c *NSMobileAPIClient) GetAssignment(ctx context.Context, userID, studentID, assignmentID, instanceURL string) (*AssignmentDetails, error) {
+	q := url.Values{}
+	q.Set("studentId", studentID)
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+	var assignment AssignmentDetails
+	if err := c.getJSON(ctx, userID, instanceURL, "assignments/"+url.PathEscape(assignmentID), q, &assignment); err != nil {
+		return nil, err
 	}
+	return &assignment, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get assignment types, status: %d, body: %s", resp.StatusCode, string(body))
-	}
 
-	var result interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+func (c *NSMobileAPIClient) GetAssignmentTypes(ctx context.Context, userID, instanceURL string) ([]AssignmentType, error) {
+	var types []AssignmentType
+	if err := c.getJSON(ctx, userID, instanceURL, "assignmentTypes", nil, &types); err != nil {
+		return nil, err
 	}
-
-	return result, nil
+	return types, nil
 }
 
 
 func (c *NSMobileAPIClient) GetDownloadFile(ctx context.Context, userID, studentID, assignmentID, fileID, instanceURL string) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
+	client := c.httpClient
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/attachments/%s", instanceURL, fileID), nil)
 	if err != nil {
@@ -487,7 +409,6 @@ func (c *NSMobileAPIClient) GetDownloadFile(ctx context.Context, userID, student
 	req.URL.RawQuery = q.Encode()
 
 	req.Header.Set("Authorization", "Bearer "+userID)
-	req.Header.Set("User-Agent", "NetSchoolApp/1.0")
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -497,7 +418,10 @@ func (c *NSMobileAPIClient) GetDownloadFile(ctx context.Context, userID, student
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to download file, status: %d, body: %s", resp.StatusCode, string(body))
+		if err := ClassifyResponse(resp, body); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: failed to download file, status: %d, body: %s", ErrUnexpectedPayload, resp.StatusCode, string(body))
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -510,7 +434,7 @@ func (c *NSMobileAPIClient) GetDownloadFile(ctx context.Context, userID, student
 
 
 func (c *NSMobileAPIClient) GetReportFile(ctx context.Context, userID, instanceURL, reportURL string, filters map[string]interface{}, yearID int, timeout int, transport *int) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
+	client := c.httpClient
 
 	
 	fullURL := fmt.Sprintf("%s/%s", instanceURL, reportURL)
@@ -528,7 +452,6 @@ func (c *NSMobileAPIClient) GetReportFile(ctx context.Context, userID, instanceU
 
 	req.Header.Set("Authorization", "Bearer "+userID)
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "NetSchoolApp/1.0")
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -536,18 +459,18 @@ func (c *NSMobileAPIClient) GetReportFile(ctx context.Context, userID, instanceU
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponse(resp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get report file, status: %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("%w: failed to get report file, status: %d, body: %s", ErrUnexpectedPayload, resp.StatusCode, string(body))
 	}
 
 	var result interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if err := decodePayload(reportURL, body, &result); err != nil {
+		return nil, err
 	}
 
 	return result, nil
@@ -555,7 +478,7 @@ func (c *NSMobileAPIClient) GetReportFile(ctx context.Context, userID, instanceU
 
 
 func (c *NSMobileAPIClient) GetJournal(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time, termID, classID int, transport *int) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
+	client := c.httpClient
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/journal", instanceURL), nil)
 	if err != nil {
@@ -575,7 +498,6 @@ func (c *NSMobileAPIClient) GetJournal(ctx context.Context, userID, studentID, i
 	req.URL.RawQuery = q.Encode()
 
 	req.Header.Set("Authorization", "Bearer "+userID)
-	req.Header.Set("User-Agent", "NetSchoolApp/1.0")
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -583,61 +505,35 @@ func (c *NSMobileAPIClient) GetJournal(ctx context.Context, userID, studentID, i
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponse(resp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get journal, status: %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("%w: failed to get journal, status: %d, body: %s", ErrUnexpectedPayload, resp.StatusCode, string(body))
 	}
 
 	var result interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if err := decodePayload("journal", body, &result); err != nil {
+		return nil, err
 	}
 
 	return result, nil
 }
 
 
-func (c *NSMobileAPIClient) GetInfo(ctx context.Context, userID, instanceURL string) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/info", instanceURL), nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+userID)
-	req.Header.Set("User-Agent", "NetSchoolApp/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get info, status: %d, body: %s", resp.StatusCode, string(body))
-	}
-
-	var result interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+func (c *NSMobileAPIClient) GetInfo(ctx context.Context, userID, instanceURL string) (*MySettings, error) {
+	var settings MySettings
+	if err := c.getJSON(ctx, userID, instanceURL, "info", nil, &settings); err != nil {
+		return nil, err
 	}
-
-	return result, nil
+	return &settings, nil
 }
 
 
 func (c *NSMobileAPIClient) GetPhoto(ctx context.Context, userID, studentID, instanceURL string) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
+	client := c.httpClient
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/photo", instanceURL), nil)
 	if err != nil {
@@ -650,7 +546,6 @@ func (c *NSMobileAPIClient) GetPhoto(ctx context.Context, userID, studentID, ins
 	req.URL.RawQuery = q.Encode()
 
 	req.Header.Set("Authorization", "Bearer "+userID)
-	req.Header.Set("User-Agent", "NetSchoolApp/1.0")
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -660,7 +555,10 @@ func (c *NSMobileAPIClient) GetPhoto(ctx context.Context, userID, studentID, ins
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get photo, status: %d, body: %s", resp.StatusCode, string(body))
+		if err := ClassifyResponse(resp, body); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: failed to get photo, status: %d, body: %s", ErrUnexpectedPayload, resp.StatusCode, string(body))
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -672,56 +570,17 @@ func (c *NSMobileAPIClient) GetPhoto(ctx context.Context, userID, studentID, ins
 }
 
 
-func (c *NSMobileAPIClient) GetGradesForSubject(ctx context.Context, userID, studentID, subjectID, instanceURL string, start, end time.Time, termID, classID int, transport *int) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/grades", instanceURL), nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	
-	q := req.URL.Query()
-	q.Set("studentId", studentID)
-	q.Set("subjectId", subjectID)
-	q.Set("startDate", start.Format("2006-01-02"))
-	q.Set("endDate", end.Format("2006-01-02"))
-	q.Set("termId", fmt.Sprintf("%d", termID))
-	q.Set("classId", fmt.Sprintf("%d", classID))
-	if transport != nil {
-		q.Set("transport", fmt.Sprintf("%d", *transport))
-	}
-	req.URL.RawQuery = q.Encode()
-
-	req.Header.Set("Authorization", "Bearer "+userID)
-	req.Header.Set("User-Agent", "NetSchoolApp/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+func (c *NSMobileAPIClient) GetGradesForSubject(ctx context.Context, userID, studentID, subjectID, instanceURL string, start, end time.Time, termID, classID int, transport *int) ([]Grade, error) {
+	grades, err := c.GetGrades(ctx, userID, studentID, instanceURL, start, end)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get grades for subject, status: %d, body: %s", resp.StatusCode, string(body))
+		return nil, err
 	}
-
-	var result interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	return result, nil
+	return filterGradesBySubject(grades, subjectID), nil
 }
 
 
 func (c *NSMobileAPIClient) GetFullJournal(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time, termID, classID int, transport *int) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
+	client := c.httpClient
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/full-journal", instanceURL), nil)
 	if err != nil {
@@ -741,7 +600,6 @@ func (c *NSMobileAPIClient) GetFullJournal(ctx context.Context, userID, studentI
 	req.URL.RawQuery = q.Encode()
 
 	req.Header.Set("Authorization", "Bearer "+userID)
-	req.Header.Set("User-Agent", "NetSchoolApp/1.0")
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -749,18 +607,18 @@ func (c *NSMobileAPIClient) GetFullJournal(ctx context.Context, userID, studentI
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponse(resp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get full journal, status: %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("%w: failed to get full journal, status: %d, body: %s", ErrUnexpectedPayload, resp.StatusCode, string(body))
 	}
 
 	var result interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if err := decodePayload("full-journal", body, &result); err != nil {
+		return nil, err
 	}
 
 	return result, nil
@@ -768,7 +626,7 @@ func (c *NSMobileAPIClient) GetFullJournal(ctx context.Context, userID, studentI
 
 
 func (c *NSMobileAPIClient) CheckHealth(ctx context.Context, instanceURL string) (bool, error) {
-	client := &http.Client{Timeout: c.timeout}
+	client := c.httpClient
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/ping", instanceURL), nil)
 	if err != nil {
@@ -786,7 +644,7 @@ func (c *NSMobileAPIClient) CheckHealth(ctx context.Context, instanceURL string)
 
 
 func (c *NSMobileAPIClient) CheckIntPing(ctx context.Context, instanceURL string) (bool, time.Duration, error) {
-	client := &http.Client{Timeout: c.timeout}
+	client := c.httpClient
 
 	start := time.Now()
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/int-ping", instanceURL), nil)
@@ -803,4 +661,64 @@ func (c *NSMobileAPIClient) CheckIntPing(ctx context.Context, instanceURL string
 	duration := time.Since(start)
 
 	return resp.StatusCode == http.StatusOK, duration, nil
-}
\ No newline at end of file
+}
+
+
+func (c *NSMobileAPIClient) GetCurrentYear(ctx context.Context, userID, instanceURL string) (*SchoolYear, error) {
+	var year SchoolYear
+	if err := c.getJSON(ctx, userID, instanceURL, "years/current", nil, &year); err != nil {
+		return nil, err
+	}
+	return &year, nil
+}
+
+
+func (c *NSMobileAPIClient) GetYears(ctx context.Context, userID, instanceURL string) ([]SchoolYear, error) {
+	var years []SchoolYear
+	if err := c.getJSON(ctx, userID, instanceURL, "years", nil, &years); err != nil {
+		return nil, err
+	}
+	return years, nil
+}
+
+
+func (c *NSMobileAPIClient) GetTerms(ctx context.Context, userID, instanceURL string, yearID int) ([]Term, error) {
+	q := url.Values{}
+	q.Set("yearId", strconv.Itoa(yearID))
+
+	var terms []Term
+	if err := c.getJSON(ctx, userID, instanceURL, "terms", q, &terms); err != nil {
+		return nil, err
+	}
+	return terms, nil
+}
+
+
+func (c *NSMobileAPIClient) getJSON(ctx context.Context, userID, instanceURL, endpoint string, query url.Values, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s", instanceURL, endpoint), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if len(query) > 0 {
+		req.URL.RawQuery = query.Encode()
+	}
+
+	req.Header.Set("Authorization", "Bearer "+userID)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := readResponse(resp)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: request to %s failed, status: %d, body: %s", ErrUnexpectedPayload, endpoint, resp.StatusCode, string(body))
+	}
+
+	return decodePayload(endpoint, body, target)
+}
@@ -0,0 +1,106 @@
+package api_types_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/api_types"
+)
+
+func newDeviceFlowClient(t *testing.T) api_types.DeviceFlowClient {
+	factory := &api_types.APIClientFactory{}
+	client, err := factory.NewAPIClient(api_types.NSMobileAPI, api_types.APIConfig{Mode: api_types.NSMobileAPI, Timeout: 5})
+	require.NoError(t, err)
+
+	deviceClient, ok := api_types.UnwrapClient(client).(api_types.DeviceFlowClient)
+	require.True(t, ok)
+	return deviceClient
+}
+
+func TestNSMobileAPIClient_PollDeviceToken(t *testing.T) {
+	responses := map[string]struct {
+		status int
+		body   string
+	}{
+		"pending":  {http.StatusBadRequest, `{"error":"authorization_pending"}`},
+		"slowdown": {http.StatusBadRequest, `{"error":"slow_down"}`},
+		"expired":  {http.StatusBadRequest, `{"error":"expired_token","error_description":"code expired"}`},
+		"granted":  {http.StatusOK, `{"access_token":"at","refresh_token":"rt","expires_in":3600,"token_type":"Bearer"}`},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/connect/token", r.URL.Path)
+		require.NoError(t, r.ParseForm())
+		resp := responses[r.PostForm.Get("device_code")]
+		w.WriteHeader(resp.status)
+		w.Write([]byte(resp.body))
+	}))
+	defer server.Close()
+
+	client := newDeviceFlowClient(t)
+	ctx := context.Background()
+
+	_, err := client.PollDeviceToken(ctx, server.URL, "pending")
+	assert.ErrorIs(t, err, api_types.ErrDeviceAuthorizationPending)
+
+	_, err = client.PollDeviceToken(ctx, server.URL, "slowdown")
+	assert.ErrorIs(t, err, api_types.ErrDeviceSlowDown)
+
+	_, err = client.PollDeviceToken(ctx, server.URL, "expired")
+	assert.ErrorIs(t, err, api_types.ErrDeviceCodeExpired)
+
+	token, err := client.PollDeviceToken(ctx, server.URL, "granted")
+	require.NoError(t, err)
+	assert.Equal(t, "at", token.AccessToken)
+	assert.Equal(t, "rt", token.RefreshToken)
+	assert.Equal(t, 3600, token.ExpiresIn)
+}
+
+func TestNSMobileAPIClient_StartDeviceAuthorization(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/connect/deviceauthorization", r.URL.Path)
+		w.Write([]byte(`{"device_code":"dc","user_code":"ABCD-1234","verification_uri":"https://example.org/device","expires_in":300,"interval":5}`))
+	}))
+	defer server.Close()
+
+	authorization, err := newDeviceFlowClient(t).StartDeviceAuthorization(context.Background(), server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "dc", authorization.DeviceCode)
+	assert.Equal(t, "ABCD-1234", authorization.UserCode)
+	assert.Equal(t, "https://example.org/device", authorization.VerificationURI)
+	assert.Equal(t, 300, authorization.ExpiresIn)
+	assert.Equal(t, 5, authorization.Interval)
+}
+
+func TestNSMobileAPIClient_RefreshAccessToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+		if r.PostForm.Get("refresh_token") != "valid" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"new-at","refresh_token":"new-rt","expires_in":1800}`))
+	}))
+	defer server.Close()
+
+	factory := &api_types.APIClientFactory{}
+	client, err := factory.NewAPIClient(api_types.NSMobileAPI, api_types.APIConfig{Mode: api_types.NSMobileAPI, Timeout: 5})
+	require.NoError(t, err)
+	refresher, ok := api_types.UnwrapClient(client).(api_types.TokenRefresher)
+	require.True(t, ok)
+
+	token, err := refresher.RefreshAccessToken(context.Background(), server.URL, "valid")
+	require.NoError(t, err)
+	assert.Equal(t, "new-at", token.AccessToken)
+	assert.Equal(t, "new-rt", token.RefreshToken)
+	assert.Equal(t, 1800, token.ExpiresIn)
+
+	_, err = refresher.RefreshAccessToken(context.Background(), server.URL, "revoked")
+	assert.ErrorIs(t, err, api_types.ErrUnauthorized)
+}
@@ -19,20 +19,61 @@ import (
 
 
 type NSWebAPIClient struct {
-	timeout   time.Duration
-	retryMax  int
-	retryWait time.Duration
+	timeout    time.Duration
+	retryMax   int
+	retryWait  time.Duration
+	httpClient *http.Client
+}
+
+func init() {
+	DefaultRegistry.MustRegister(Provider{
+		Mode:        NSWebAPI,
+		Name:        "NetSchool Web API",
+		Description: "Сетевой Город. Образование через /webapi с входом по логину и паролю",
+		Capabilities: []Capability{
+			CapabilityStudentInfo, CapabilityStudents, CapabilityGrades, CapabilitySchedule,
+			CapabilitySchoolInfo, CapabilityClasses, CapabilityDiary, CapabilityAssignments,
+			CapabilityAssignmentTypes, CapabilityFiles, CapabilityReports, CapabilityJournal,
+			CapabilityInfo, CapabilityPhoto, CapabilityHealth, CapabilityDirectory,
+		},
+		Login: LoginRequirements{
+			Methods:             []LoginMethod{LoginMethodPassword},
+			RequiresPassword:    true,
+			RequiresSchoolID:    true,
+			RequiresInstanceURL: true,
+		},
+		ConfigSchema: netSchoolConfigSchema,
+		UserAgent:    "NetCityApp/1.0",
+		New:          newNSWebAPIClient,
+	})
+}
+
+func newNSWebAPIClient(config APIConfig, httpClient *http.Client) (APIClientInterface, error) {
+	
+	client := &NSWebAPIClient{
+		timeout:    time.Duration(config.Timeout) * time.Second,
+		retryMax:   config.RetryMax,
+		retryWait:  time.Duration(config.RetryWait) * time.Millisecond,
+		httpClient: httpClient,
+	}
+	return client, nil
 }
 
 
 func (c *NSWebAPIClient) Login(ctx context.Context, username, password string, schoolID int, instanceURL string, loginData map[string]interface{}) (string, error) {
 	
-	client := &http.Client{Timeout: c.timeout}
+	client := c.httpClient
 	
-	_, err := client.Get(fmt.Sprintf("%s/webapi/logindata", instanceURL))
+	cookieReq, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/webapi/logindata", instanceURL), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create logindata request: %w", err)
+	}
+	cookieResp, err := client.Do(cookieReq)
 	if err != nil {
 		return "", fmt.Errorf("failed to get logindata cookies: %w", err)
 	}
+	io.Copy(io.Discard, cookieResp.Body)
+	cookieResp.Body.Close()
 
 	
 	loginMeta := make(map[string]interface{})
@@ -87,7 +128,6 @@ func (c *NSWebAPIClient) Login(ctx context.Context, username, password string, s
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("User-Agent", "NetCityApp/1.0")
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -100,6 +140,16 @@ func (c *NSWebAPIClient) Login(ctx context.Context, username, password string, s
 		return "", fmt.Errorf("failed to read login response: %w", err)
 	}
 
+	
+	if err := classifyUnavailable(resp, looksLikeHTML(resp, body), responseText(resp, body)); err != nil {
+		return "", err
+	}
+
+	
+	if reason := interactiveLoginReason(resp, body); reason != "" {
+		return "", fmt.Errorf("%w: %s", ErrInteractiveLoginRequired, reason)
+	}
+
 	var authResult map[string]interface{}
 	if err := json.Unmarshal(body, &authResult); err != nil {
 		return "", fmt.Errorf("failed to parse login response: %w", err)
@@ -112,7 +162,10 @@ func (c *NSWebAPIClient) Login(ctx context.Context, username, password string, s
 		if !msgExists {
 			message = "Unknown authentication error"
 		}
-		return "", fmt.Errorf("authentication failed: %v", message)
+		if err := classifyLoginMessage(fmt.Sprint(message)); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("%w: %v", ErrAuthenticationFailed, message)
 	}
 
 	accessToken, ok := at.(string)
@@ -125,7 +178,7 @@ func (c *NSWebAPIClient) Login(ctx context.Context, username, password string, s
 
 
 func (c *NSWebAPIClient) GetLoginData(ctx context.Context, instanceURL string) (map[string]interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
+	client := c.httpClient
 	
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/webapi/logindata", instanceURL), nil)
 	if err != nil {
@@ -138,63 +191,57 @@ func (c *NSWebAPIClient) GetLoginData(ctx context.Context, instanceURL string) (
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponse(resp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
 
 	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if err := decodePayload("logindata", body, &result); err != nil {
+		return nil, err
 	}
 
 	return result, nil
 }
 
 
-func (c *NSWebAPIClient) GetStudentInfo(ctx context.Context, userID, instanceURL string) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
-	
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/webapi/student/diary/init", instanceURL), nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+func (c *NSWebAPIClient) GetLoginForm(ctx context.Context, instanceURL string) (*LoginForm, error) {
+	var form LoginForm
+	if err := c.getJSON(ctx, "", instanceURL, "prepareloginform", nil, &form); err != nil {
+		return nil, err
 	}
+	return &form, nil
+}
 
-	
-	req.Header.Set("at", userID) 
-	req.Header.Set("User-Agent", "NetCityApp/1.0")
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+func (c *NSWebAPIClient) GetSchoolDirectory(ctx context.Context, instanceURL string, schoolTypeID int) ([]SchoolSummary, error) {
+	q := url.Values{}
+	if schoolTypeID != 0 {
+		q.Set("funcType", strconv.Itoa(schoolTypeID))
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+	var schools []SchoolSummary
+	if err := c.getJSON(ctx, "", instanceURL, "addresses/schools", q, &schools); err != nil {
+		return nil, err
 	}
+	return schools, nil
+}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
 
-	return result, nil
+func (c *NSWebAPIClient) GetStudentInfo(ctx context.Context, userID, instanceURL string) (*MySettings, error) {
+	return c.GetInfo(ctx, userID, instanceURL)
 }
 
 
-func (c *NSWebAPIClient) GetGrades(ctx context.Context, userID, studentID, instanceURL string) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
-	
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/webapi/student/%s/grades", instanceURL, studentID), nil)
+func (c *NSWebAPIClient) GetStudents(ctx context.Context, userID, instanceURL string) (*StudentList, error) {
+	client := c.httpClient
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/webapi/student/diary/init", instanceURL), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	
-	req.Header.Set("at", userID) 
-	req.Header.Set("User-Agent", "NetCityApp/1.0")
+	req.Header.Set("at", userID)
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -202,146 +249,75 @@ func (c *NSWebAPIClient) GetGrades(ctx context.Context, userID, studentID, insta
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponse(resp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+		return nil, err
 	}
 
-	return result, nil
-}
-
-
-func (c *NSWebAPIClient) GetSchedule(ctx context.Context, userID, instanceURL string, weekStart time.Time) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
-	
-	
-	yearResp, err := c.getCurrentYear(ctx, userID, instanceURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get current year: %w", err)
-	}
-	
-	yearID, ok := yearResp["id"].(string)
-	if !ok {
-		return nil, fmt.Errorf("failed to get year ID")
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: failed to get students, status: %d, body: %s", ErrUnexpectedPayload, resp.StatusCode, string(body))
 	}
 
 	
-	weekFormatted := weekStart.Format("2006-01-02")
-	
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/webapi/student/%s/schedule", instanceURL, userID), nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	var diaryInit struct {
+		Students []struct {
+			StudentID flexibleID `json:"studentId"`
+			NickName  string     `json:"nickName"`
+			ClassID   flexibleID `json:"classId"`
+			ClassName string     `json:"className"`
+		} `json:"students"`
+		CurrentStudentID flexibleID `json:"currentStudentId"`
 	}
-
-	
-	q := req.URL.Query()
-	q.Set("yearId", yearID)
-	q.Set("week", weekFormatted)
-	req.URL.RawQuery = q.Encode()
-
-	
-	req.Header.Set("at", userID) 
-	req.Header.Set("User-Agent", "NetCityApp/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+	if err := decodePayload("student/diary/init", body, &diaryInit); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+	list := &StudentList{
+		Students:         make([]Student, 0, len(diaryInit.Students)),
+		CurrentStudentID: string(diaryInit.CurrentStudentID),
 	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	for _, student := range diaryInit.Students {
+		list.Students = append(list.Students, Student{
+			ID:        string(student.StudentID),
+			Name:      student.NickName,
+			ClassID:   string(student.ClassID),
+			ClassName: student.ClassName,
+		})
 	}
 
-	return result, nil
+	return list, nil
 }
 
 
-func (c *NSWebAPIClient) GetSchoolInfo(ctx context.Context, userID, instanceURL string) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
-	
-	endpoints := []string{
-		"schools/current",
-		"school/info", 
-		"schools/my",
+func (c *NSWebAPIClient) GetGrades(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time) ([]Grade, error) {
+	diary, err := c.GetDiary(ctx, userID, studentID, instanceURL, start, end)
+	if err != nil {
+		return nil, err
 	}
-	
-	for _, endpoint := range endpoints {
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/webapi/%s", instanceURL, endpoint), nil)
-		if err != nil {
-			continue 
-		}
-
-		
-		req.Header.Set("at", userID) 
-		req.Header.Set("User-Agent", "NetCityApp/1.0")
-
-		resp, err := client.Do(req)
-		if err != nil {
-			continue 
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			continue 
-		}
-
-		var result map[string]interface{}
-		if err := json.Unmarshal(body, &result); err != nil {
-			continue 
-		}
+	return diary.Grades(), nil
+}
 
-		
-		if len(result) > 0 {
-			return result, nil
-		}
-	}
 
-	return nil, fmt.Errorf("failed to get school info from any endpoint")
+func (c *NSWebAPIClient) GetSchedule(ctx context.Context, userID, studentID, instanceURL string, weekStart time.Time) (*Diary, error) {
+	return c.GetDiary(ctx, userID, studentID, instanceURL, weekStart, weekStart.AddDate(0, 0, 6))
 }
 
 
-func (c *NSWebAPIClient) GetClasses(ctx context.Context, userID, instanceURL string) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/webapi/classes", instanceURL), nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+func (c *NSWebAPIClient) GetSchoolInfo(ctx context.Context, userID string, schoolID int, instanceURL string) (*SchoolInfo, error) {
+	var info SchoolInfo
+	if err := c.getJSON(ctx, userID, instanceURL, fmt.Sprintf("schools/%d/card", schoolID), nil, &info); err != nil {
+		return nil, err
 	}
+	return &info, nil
+}
 
-	
-	req.Header.Set("at", userID) 
-	req.Header.Set("User-Agent", "NetCityApp/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+func (c *NSWebAPIClient) GetClasses(ctx context.Context, userID, instanceURL string) ([]Class, error) {
+	var classes []Class
+	if err := c.getJSON(ctx, userID, instanceURL, "classes", nil, &classes); err != nil {
+		return nil, err
 	}
-
-	var result []map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	return result, nil
+	return classes, nil
 }
 
 
@@ -359,133 +335,52 @@ func (c *NSWebAPIClient) CheckIntPing(ctx context.Context, instanceURL string) (
 }
 
 
-func (c *NSWebAPIClient) GetDiary(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
-
-	
-	yearResp, err := c.getCurrentYear(ctx, userID, instanceURL)
+func (c *NSWebAPIClient) GetDiary(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time) (*Diary, error) {
+	yearID, err := c.schoolYearID(ctx, userID, instanceURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current year: %w", err)
 	}
 
-	yearID, ok := yearResp["id"].(string)
-	if !ok {
-		return nil, fmt.Errorf("failed to get year ID")
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/webapi/student/diary", instanceURL), nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	
-	q := req.URL.Query()
-	q.Set("yearId", yearID)
+	q := url.Values{}
+	q.Set("yearId", strconv.Itoa(yearID))
 	q.Set("studentId", studentID)
 	q.Set("weekEnd", end.Format("2006-01-02"))
 	q.Set("weekStart", start.Format("2006-01-02"))
-	req.URL.RawQuery = q.Encode()
 
-	
-	req.Header.Set("at", userID)
-	req.Header.Set("User-Agent", "NetCityApp/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	var diary Diary
+	if err := c.getJSON(ctx, userID, instanceURL, "student/diary", q, &diary); err != nil {
+		return nil, err
 	}
-
-	return result, nil
+	return &diary, nil
 }
 
 
-func (c *NSWebAPIClient) GetAssignment(ctx context.Context, userID, studentID, assignmentID, instanceURL string) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/webapi/grade/assignment/%s", instanceURL, assignmentID), nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	
-	q := req.URL.Query()
+func (c *NSWebAPIClient) GetAssignment(ctx context.Context, userID, studentID, assignmentID, instanceURL string) (*AssignmentDetails, error) {
+	q := url.Values{}
 	q.Set("studentId", studentID)
-	req.URL.RawQuery = q.Encode()
 
-	
-	req.Header.Set("at", userID)
-	req.Header.Set("User-Agent", "NetCityApp/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+	var assignment AssignmentDetails
+	if err := c.getJSON(ctx, userID, instanceURL, "student/diary/assigns/"+url.PathEscape(assignmentID), q, &assignment); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	return result, nil
+	return &assignment, nil
 }
 
 
-func (c *NSWebAPIClient) GetAssignmentTypes(ctx context.Context, userID, instanceURL string) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/webapi/grade/assignment/types", instanceURL), nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	
-	q := req.URL.Query()
+func (c *NSWebAPIClient) GetAssignmentTypes(ctx context.Context, userID, instanceURL string) ([]AssignmentType, error) {
+	q := url.Values{}
 	q.Set("all", "false")
-	req.URL.RawQuery = q.Encode()
 
-	
-	req.Header.Set("at", userID)
-	req.Header.Set("User-Agent", "NetCityApp/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	var result []map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	var types []AssignmentType
+	if err := c.getJSON(ctx, userID, instanceURL, "grade/assignment/types", q, &types); err != nil {
+		return nil, err
 	}
-
-	return result, nil
+	return types, nil
 }
 
 
 func (c *NSWebAPIClient) GetDownloadFile(ctx context.Context, userID, studentID, assignmentID, fileID, instanceURL string) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
+	client := c.httpClient
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/webapi/download/attachment/%s", instanceURL, fileID), nil)
 	if err != nil {
@@ -500,7 +395,6 @@ func (c *NSWebAPIClient) GetDownloadFile(ctx context.Context, userID, studentID,
 
 	
 	req.Header.Set("at", userID)
-	req.Header.Set("User-Agent", "NetCityApp/1.0")
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -508,9 +402,9 @@ func (c *NSWebAPIClient) GetDownloadFile(ctx context.Context, userID, studentID,
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponse(resp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
 
 	
@@ -519,7 +413,7 @@ func (c *NSWebAPIClient) GetDownloadFile(ctx context.Context, userID, studentID,
 
 
 func (c *NSWebAPIClient) GetReportFile(ctx context.Context, userID, instanceURL, reportURL string, filters map[string]interface{}, yearID int, timeout int, transport *int) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
+	client := c.httpClient
 
 	
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/webapi/%s", instanceURL, reportURL), nil)
@@ -551,7 +445,6 @@ func (c *NSWebAPIClient) GetReportFile(ctx context.Context, userID, instanceURL,
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("at", userID)
-	req.Header.Set("User-Agent", "NetCityApp/1.0")
 	req.Body = io.NopCloser(bytes.NewReader(jsonData))
 
 	resp, err := client.Do(req)
@@ -560,14 +453,14 @@ func (c *NSWebAPIClient) GetReportFile(ctx context.Context, userID, instanceURL,
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponse(resp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
 
 	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if err := decodePayload(reportURL, body, &result); err != nil {
+		return nil, err
 	}
 
 	return result, nil
@@ -575,7 +468,7 @@ func (c *NSWebAPIClient) GetReportFile(ctx context.Context, userID, instanceURL,
 
 
 func (c *NSWebAPIClient) GetJournal(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time, termID, classID int, transport *int) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
+	client := c.httpClient
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/webapi/reports/studenttotal", instanceURL), nil)
 	if err != nil {
@@ -595,7 +488,6 @@ func (c *NSWebAPIClient) GetJournal(ctx context.Context, userID, studentID, inst
 
 	
 	req.Header.Set("at", userID)
-	req.Header.Set("User-Agent", "NetCityApp/1.0")
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -603,54 +495,31 @@ func (c *NSWebAPIClient) GetJournal(ctx context.Context, userID, studentID, inst
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponse(resp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
 
 	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if err := decodePayload("reports/studenttotal", body, &result); err != nil {
+		return nil, err
 	}
 
 	return result, nil
 }
 
 
-func (c *NSWebAPIClient) GetInfo(ctx context.Context, userID, instanceURL string) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/webapi/mysettings", instanceURL), nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	
-	req.Header.Set("at", userID)
-	req.Header.Set("User-Agent", "NetCityApp/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+func (c *NSWebAPIClient) GetInfo(ctx context.Context, userID, instanceURL string) (*MySettings, error) {
+	var settings MySettings
+	if err := c.getJSON(ctx, userID, instanceURL, "mysettings", nil, &settings); err != nil {
+		return nil, err
 	}
-
-	return result, nil
+	return &settings, nil
 }
 
 
 func (c *NSWebAPIClient) GetPhoto(ctx context.Context, userID, studentID, instanceURL string) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
+	client := c.httpClient
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/webapi/Photo", instanceURL), nil)
 	if err != nil {
@@ -664,7 +533,6 @@ func (c *NSWebAPIClient) GetPhoto(ctx context.Context, userID, studentID, instan
 
 	
 	req.Header.Set("at", userID)
-	req.Header.Set("User-Agent", "NetCityApp/1.0")
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -672,9 +540,9 @@ func (c *NSWebAPIClient) GetPhoto(ctx context.Context, userID, studentID, instan
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponse(resp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
 
 	
@@ -682,51 +550,17 @@ func (c *NSWebAPIClient) GetPhoto(ctx context.Context, userID, studentID, instan
 }
 
 
-func (c *NSWebAPIClient) GetGradesForSubject(ctx context.Context, userID, studentID, subjectID, instanceURL string, start, end time.Time, termID, classID int, transport *int) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/webapi/student/%s/grades", instanceURL, studentID), nil)
+func (c *NSWebAPIClient) GetGradesForSubject(ctx context.Context, userID, studentID, subjectID, instanceURL string, start, end time.Time, termID, classID int, transport *int) ([]Grade, error) {
+	grades, err := c.GetGrades(ctx, userID, studentID, instanceURL, start, end)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	
-	q := req.URL.Query()
-	q.Set("subjectId", subjectID)
-	q.Set("period", fmt.Sprintf("%s - %s", start.Format("2006-01-02"), end.Format("2006-01-02")))
-	q.Set("termId", fmt.Sprintf("%d", termID))
-	q.Set("classId", fmt.Sprintf("%d", classID))
-	if transport != nil {
-		q.Set("transport", fmt.Sprintf("%d", *transport))
-	}
-	req.URL.RawQuery = q.Encode()
-
-	
-	req.Header.Set("at", userID)
-	req.Header.Set("User-Agent", "NetCityApp/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+		return nil, err
 	}
-
-	return result, nil
+	return filterGradesBySubject(grades, subjectID), nil
 }
 
 
 func (c *NSWebAPIClient) GetFullJournal(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time, termID, classID int, transport *int) (interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
+	client := c.httpClient
 
 	
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/webapi/reports/studenttotal", instanceURL), nil)
@@ -750,7 +584,6 @@ func (c *NSWebAPIClient) GetFullJournal(ctx context.Context, userID, studentID,
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("at", userID)
-	req.Header.Set("User-Agent", "NetCityApp/1.0")
 	req.Body = io.NopCloser(bytes.NewReader(jsonData))
 
 	resp, err := client.Do(req)
@@ -759,47 +592,90 @@ func (c *NSWebAPIClient) GetFullJournal(ctx context.Context, userID, studentID,
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponse(resp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
 
 	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if err := decodePayload("reports/studenttotal", body, &result); err != nil {
+		return nil, err
 	}
 
 	return result, nil
 }
 
 
-func (c *NSWebAPIClient) getCurrentYear(ctx context.Context, userID, instanceURL string) (map[string]interface{}, error) {
-	client := &http.Client{Timeout: c.timeout}
+func (c *NSWebAPIClient) GetCurrentYear(ctx context.Context, userID, instanceURL string) (*SchoolYear, error) {
+	var year SchoolYear
+	if err := c.getJSON(ctx, userID, instanceURL, "years/current", nil, &year); err != nil {
+		return nil, err
+	}
+	return &year, nil
+}
+
+
+func (c *NSWebAPIClient) GetYears(ctx context.Context, userID, instanceURL string) ([]SchoolYear, error) {
+	var years []SchoolYear
+	if err := c.getJSON(ctx, userID, instanceURL, "mysettings/yearlist", nil, &years); err != nil {
+		return nil, err
+	}
+	return years, nil
+}
+
+
+func (c *NSWebAPIClient) GetTerms(ctx context.Context, userID, instanceURL string, yearID int) ([]Term, error) {
+	q := url.Values{}
+	q.Set("yearId", strconv.Itoa(yearID))
+
+	var terms []Term
+	if err := c.getJSON(ctx, userID, instanceURL, "terms/search", q, &terms); err != nil {
+		return nil, err
+	}
+	return terms, nil
+}
+
+
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/webapi/years/current", instanceURL), nil)
+func (c *NSWebAPIClient) schoolYearID(ctx context.Context, userID, instanceURL string) (int, error) {
+	if yearID, ok := SchoolYearFromContext(ctx); ok {
+		return yearID, nil
+	}
+	year, err := c.GetCurrentYear(ctx, userID, instanceURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return 0, err
 	}
+	return year.ID, nil
+}
 
-	
-	req.Header.Set("at", userID) 
-	req.Header.Set("User-Agent", "NetCityApp/1.0")
 
-	resp, err := client.Do(req)
+func (c *NSWebAPIClient) getJSON(ctx context.Context, userID, instanceURL, endpoint string, query url.Values, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/webapi/%s", instanceURL, endpoint), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if len(query) > 0 {
+		req.URL.RawQuery = query.Encode()
+	}
+
+	if userID != "" {
+		req.Header.Set("at", userID)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponse(resp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return err
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: request to %s failed, status: %d", ErrUnexpectedPayload, endpoint, resp.StatusCode)
 	}
 
-	return result, nil
-}
\ No newline at end of file
+	return decodePayload(endpoint, body, target)
+}
@@ -0,0 +1,210 @@
+package api_types_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/api_types"
+)
+
+func TestNSWebAPIClient_GetStudents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/webapi/student/diary/init", r.URL.Path)
+		assert.Equal(t, "token", r.Header.Get("at"))
+		w.Write([]byte(`{
+			"students": [
+				{"studentId": 1001, "nickName": "Анна", "classId": 91, "className": "9А"},
+				{"studentId": 1002, "nickName": "Борис", "classId": 51, "className": "5Б"}
+			],
+			"currentStudentId": 1002,
+			"weekStart": "2024-09-02T00:00:00"
+		}`))
+	}))
+	defer server.Close()
+
+	factory := &api_types.APIClientFactory{}
+	client, err := factory.NewAPIClient(api_types.NSWebAPI, api_types.APIConfig{Mode: api_types.NSWebAPI, Timeout: 5})
+	require.NoError(t, err)
+
+	list, err := client.GetStudents(context.Background(), "token", server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "1002", list.CurrentStudentID)
+	require.Len(t, list.Students, 2)
+	assert.Equal(t, api_types.Student{ID: "1001", Name: "Анна", ClassID: "91", ClassName: "9А"}, list.Students[0])
+	assert.Equal(t, "1002", list.Students[1].ID)
+}
+
+func TestNSWebAPIClient_GetStudentsUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	factory := &api_types.APIClientFactory{}
+	client, err := factory.NewAPIClient(api_types.NSWebAPI, api_types.APIConfig{Mode: api_types.NSWebAPI, Timeout: 5})
+	require.NoError(t, err)
+
+	_, err = client.GetStudents(context.Background(), "expired", server.URL)
+	assert.ErrorIs(t, err, api_types.ErrUnauthorized)
+}
+
+func TestNSWebAPIClient_GetGradesFromDiary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/webapi/years/current":
+			w.Write([]byte(`{"id": 2025, "name": "2025/2026", "startDate": "2025-09-01T00:00:00", "endDate": "2026-08-31T00:00:00"}`))
+		case "/webapi/student/diary":
+			assert.Equal(t, "2025", r.URL.Query().Get("yearId"))
+			assert.Equal(t, "1001", r.URL.Query().Get("studentId"))
+			assert.Equal(t, "2025-09-01", r.URL.Query().Get("weekStart"))
+			w.Write([]byte(`{
+				"weekStart": "2025-09-01T00:00:00",
+				"weekEnd": "2025-09-07T00:00:00",
+				"weekDays": [{
+					"date": "2025-09-01T00:00:00",
+					"lessons": [{
+						"classmeetingId": 1,
+						"number": 1,
+						"subjectName": "Алгебра",
+						"assignments": [
+							{"id": 10, "typeId": 3, "assignmentName": "Контрольная", "weight": 20, "mark": {"assignmentId": 10, "studentId": 1001, "mark": 5}},
+							{"id": 11, "typeId": 1, "assignmentName": "Домашнее задание", "weight": 10, "mark": null}
+						]
+					}]
+				}]
+			}`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	factory := &api_types.APIClientFactory{}
+	client, err := factory.NewAPIClient(api_types.NSWebAPI, api_types.APIConfig{Mode: api_types.NSWebAPI, Timeout: 5})
+	require.NoError(t, err)
+
+	start := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	grades, err := client.GetGrades(context.Background(), "token", "1001", server.URL, start, start.AddDate(0, 0, 6))
+	require.NoError(t, err)
+	require.Len(t, grades, 1)
+	assert.Equal(t, api_types.Grade{
+		AssignmentID:   10,
+		AssignmentName: "Контрольная",
+		TypeID:         3,
+		SubjectName:    "Алгебра",
+		Date:           "2025-09-01T00:00:00",
+		Mark:           "5",
+		Weight:         20,
+	}, grades[0])
+}
+
+func TestNSWebAPIClient_UnexpectedPayload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error": "maintenance"}`))
+	}))
+	defer server.Close()
+
+	factory := &api_types.APIClientFactory{}
+	client, err := factory.NewAPIClient(api_types.NSWebAPI, api_types.APIConfig{Mode: api_types.NSWebAPI, Timeout: 5})
+	require.NoError(t, err)
+
+	_, err = client.GetInfo(context.Background(), "token", server.URL)
+	assert.ErrorIs(t, err, api_types.ErrUnexpectedPayload)
+
+	_, err = client.GetDiary(context.Background(), "token", "1001", server.URL, time.Now(), time.Now())
+	assert.ErrorIs(t, err, api_types.ErrUnexpectedPayload)
+}
+
+func TestNSWebAPIClient_GetDiaryUsesSchoolYearFromContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/webapi/student/diary", r.URL.Path, "current year must not be fetched again")
+		assert.Equal(t, "2024", r.URL.Query().Get("yearId"))
+		w.Write([]byte(`{"weekStart": "2024-09-02T00:00:00", "weekDays": []}`))
+	}))
+	defer server.Close()
+
+	factory := &api_types.APIClientFactory{}
+	client, err := factory.NewAPIClient(api_types.NSWebAPI, api_types.APIConfig{Mode: api_types.NSWebAPI, Timeout: 5})
+	require.NoError(t, err)
+
+	ctx := api_types.WithSchoolYear(context.Background(), 2024)
+	start := time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC)
+	diary, err := client.GetDiary(ctx, "token", "1001", server.URL, start, start.AddDate(0, 0, 6))
+	require.NoError(t, err)
+	assert.Equal(t, "2024-09-02T00:00:00", diary.WeekStart)
+}
+
+func TestTerm_Contains(t *testing.T) {
+	term := api_types.Term{ID: 1, StartDate: "2025-09-01T00:00:00", EndDate: "2025-10-26T00:00:00"}
+
+	assert.True(t, term.Contains(time.Date(2025, 9, 1, 15, 0, 0, 0, time.UTC)))
+	assert.True(t, term.Contains(time.Date(2025, 10, 26, 23, 0, 0, 0, time.UTC)))
+	assert.False(t, term.Contains(time.Date(2025, 10, 27, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, api_types.Term{ID: 2}.Contains(time.Now()))
+}
+
+func TestNSWebAPIClient_SchoolDirectory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("at"))
+		switch r.URL.Path {
+		case "/webapi/prepareloginform":
+			w.Write([]byte(`{
+				"cid": 2, "sid": 1, "pid": -1, "cn": 1, "sft": 2,
+				"provinces": [{"id": 10, "name": "Городской округ"}],
+				"cities": [{"id": 1, "name": "г. Тестовск"}],
+				"funcs": [{"id": 2, "name": "Общеобразовательная"}]
+			}`))
+		case "/webapi/addresses/schools":
+			assert.Equal(t, "2", r.URL.Query().Get("funcType"))
+			w.Write([]byte(`[{"id": 42, "name": "Школа №42", "addressString": "ул. Ленина, 1", "provinceId": 10, "cityId": 1}]`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	factory := &api_types.APIClientFactory{}
+	client, err := factory.NewAPIClient(api_types.NSWebAPI, api_types.APIConfig{Mode: api_types.NSWebAPI, Timeout: 5})
+	require.NoError(t, err)
+
+	directoryClient, ok := api_types.UnwrapClient(client).(api_types.SchoolDirectoryClient)
+	require.True(t, ok)
+
+	form, err := directoryClient.GetLoginForm(context.Background(), server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, []api_types.DirectoryItem{{ID: 10, Name: "Городской округ"}}, form.Provinces)
+	assert.Equal(t, []api_types.DirectoryItem{{ID: 2, Name: "Общеобразовательная"}}, form.SchoolTypes)
+
+	schools, err := directoryClient.GetSchoolDirectory(context.Background(), server.URL, 2)
+	require.NoError(t, err)
+	require.Len(t, schools, 1)
+	assert.Equal(t, api_types.SchoolSummary{ID: 42, Name: "Школа №42", Address: "ул. Ленина, 1", ProvinceID: 10, CityID: 1}, schools[0])
+}
+
+func TestNSWebAPIClient_LoginRequiresInteraction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/webapi/logindata":
+			w.Write([]byte(`{}`))
+		case "/webapi/login":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message": "Введите код с картинки", "captcha": true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	factory := &api_types.APIClientFactory{}
+	client, err := factory.NewAPIClient(api_types.NSWebAPI, api_types.APIConfig{Mode: api_types.NSWebAPI, Timeout: 5})
+	require.NoError(t, err)
+
+	_, err = client.Login(context.Background(), "user", "secret", 1, server.URL, map[string]interface{}{"salt": "123", "lt": "1", "ver": "1"})
+	assert.ErrorIs(t, err, api_types.ErrInteractiveLoginRequired)
+}
@@ -0,0 +1,175 @@
+package api_types
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+var (
+	ErrProviderAlreadyRegistered = errors.New("provider already registered")
+	ErrCapabilityNotSupported    = errors.New("provider does not support this capability")
+)
+
+
+type Capability string
+
+const (
+	CapabilityStudentInfo     Capability = "student_info"
+	CapabilityStudents        Capability = "students"
+	CapabilityGrades          Capability = "grades"
+	CapabilitySchedule        Capability = "schedule"
+	CapabilitySchoolInfo      Capability = "school_info"
+	CapabilityClasses         Capability = "classes"
+	CapabilityDiary           Capability = "diary"
+	CapabilityAssignments     Capability = "assignments"
+	CapabilityAssignmentTypes Capability = "assignment_types"
+	CapabilityFiles           Capability = "files"
+	CapabilityReports         Capability = "reports"
+	CapabilityJournal         Capability = "journal"
+	CapabilityInfo            Capability = "info"
+	CapabilityPhoto           Capability = "photo"
+	CapabilityHealth          Capability = "health"
+	CapabilityTokenRefresh    Capability = "token_refresh"
+	CapabilityDirectory       Capability = "directory"
+)
+
+
+type LoginMethod string
+
+const (
+	LoginMethodPassword   LoginMethod = "password"
+	LoginMethodDeviceCode LoginMethod = "device_code"
+	LoginMethodESIA       LoginMethod = "esia"
+)
+
+
+type LoginRequirements struct {
+	Methods             []LoginMethod `json:"methods"`
+	RequiresPassword    bool          `json:"requires_password"`
+	RequiresSchoolID    bool          `json:"requires_school_id"`
+	RequiresInstanceURL bool          `json:"requires_instance_url"`
+}
+
+
+func (l LoginRequirements) Supports(method LoginMethod) bool {
+	for _, m := range l.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+
+type ConfigField struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	Description string `json:"description"`
+}
+
+
+type Provider struct {
+	Mode         APIMode           `json:"api_type"`
+	Name         string            `json:"name"`
+	Description  string            `json:"description"`
+	Mock         bool              `json:"mock"`
+	Capabilities []Capability      `json:"capabilities"`
+	Login        LoginRequirements `json:"login"`
+	ConfigSchema []ConfigField     `json:"config_schema"`
+
+	
+	UserAgent string `json:"-"`
+
+	
+	
+	New func(config APIConfig, httpClient *http.Client) (APIClientInterface, error) `json:"-"`
+}
+
+
+func (p *Provider) Supports(capability Capability) bool {
+	for _, c := range p.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+
+type ProviderRegistry struct {
+	mu        sync.RWMutex
+	providers map[APIMode]*Provider
+	order     []APIMode
+}
+
+func NewProviderRegistry() *ProviderRegistry {
+	return &ProviderRegistry{
+		providers: make(map[APIMode]*Provider),
+	}
+}
+
+
+func (r *ProviderRegistry) Register(provider Provider) error {
+	if provider.Mode == "" {
+		return errors.New("provider mode is required")
+	}
+	if provider.New == nil {
+		return fmt.Errorf("provider %s has no client constructor", provider.Mode)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.providers[provider.Mode]; exists {
+		return fmt.Errorf("%w: %s", ErrProviderAlreadyRegistered, provider.Mode)
+	}
+	r.providers[provider.Mode] = &provider
+	r.order = append(r.order, provider.Mode)
+	return nil
+}
+
+
+func (r *ProviderRegistry) MustRegister(provider Provider) {
+	if err := r.Register(provider); err != nil {
+		panic(err)
+	}
+}
+
+func (r *ProviderRegistry) Lookup(mode APIMode) (*Provider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	provider, ok := r.providers[mode]
+	return provider, ok
+}
+
+
+func (r *ProviderRegistry) Providers() []*Provider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	providers := make([]*Provider, 0, len(r.order))
+	for _, mode := range r.order {
+		providers = append(providers, r.providers[mode])
+	}
+	return providers
+}
+
+
+
+var DefaultRegistry = NewProviderRegistry()
+
+
+func RegisterProvider(provider Provider) error {
+	return DefaultRegistry.Register(provider)
+}
+
+
+var netSchoolConfigSchema = []ConfigField{
+	{Name: "timeout", Type: "duration", Default: "30s", Description: "HTTP request timeout"},
+	{Name: "retry_max", Type: "int", Default: "3", Description: "Maximum number of retries"},
+	{Name: "retry_wait", Type: "duration", Default: "1s", Description: "Delay between retries"},
+}
@@ -0,0 +1,61 @@
+package api_types_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/api_types"
+)
+
+func TestDefaultRegistry_BuiltinProviders(t *testing.T) {
+	var modes []api_types.APIMode
+	for _, provider := range api_types.DefaultRegistry.Providers() {
+		modes = append(modes, provider.Mode)
+	}
+	assert.ElementsMatch(t, []api_types.APIMode{api_types.NSWebAPI, api_types.NSMobileAPI, api_types.DevMockAPI}, modes)
+
+	mobile, ok := api_types.DefaultRegistry.Lookup(api_types.NSMobileAPI)
+	require.True(t, ok)
+	assert.True(t, mobile.Login.Supports(api_types.LoginMethodDeviceCode))
+	assert.False(t, mobile.Login.Supports(api_types.LoginMethodPassword))
+	assert.True(t, mobile.Supports(api_types.CapabilityTokenRefresh))
+
+	assert.True(t, api_types.NSWebAPI.IsRealAPI())
+	assert.True(t, api_types.DevMockAPI.IsMockAPI())
+	assert.False(t, api_types.APIMode("unknown").IsValid())
+}
+
+func TestProviderRegistry_Register(t *testing.T) {
+	registry := api_types.NewProviderRegistry()
+	provider := api_types.Provider{
+		Mode:         "regional-diary",
+		Name:         "Regional diary",
+		Capabilities: []api_types.Capability{api_types.CapabilityGrades},
+		New: func(config api_types.APIConfig, httpClient *http.Client) (api_types.APIClientInterface, error) {
+			return &api_types.DevMockAPIClient{}, nil
+		},
+	}
+
+	require.NoError(t, registry.Register(provider))
+	assert.ErrorIs(t, registry.Register(provider), api_types.ErrProviderAlreadyRegistered)
+	assert.Error(t, registry.Register(api_types.Provider{Mode: "no-constructor"}))
+
+	factory, err := api_types.NewAPIClientFactory(registry, api_types.TransportConfig{})
+	require.NoError(t, err)
+	client, err := factory.NewAPIClient("regional-diary", api_types.APIConfig{})
+	require.NoError(t, err)
+	assert.IsType(t, &api_types.DevMockAPIClient{}, api_types.UnwrapClient(client))
+
+	_, err = client.GetGrades(context.Background(), "token", "1001", "https://sgo.rso23.ru", time.Now(), time.Now())
+	assert.NoError(t, err)
+	_, err = client.GetSchedule(context.Background(), "token", "1001", "https://sgo.rso23.ru", time.Now())
+	assert.ErrorIs(t, err, api_types.ErrCapabilityNotSupported)
+	assert.Equal(t, api_types.ErrCodeNotImplemented, api_types.ErrorCodeOf(err))
+
+	_, err = factory.NewAPIClient(api_types.NSWebAPI, api_types.APIConfig{})
+	assert.ErrorIs(t, err, api_types.ErrInvalidAPIMode)
+}
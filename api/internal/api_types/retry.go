@@ -0,0 +1,179 @@
+package api_types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	maxRetryBackoff    = 30 * time.Second
+	maxRetryAfterDelay = 30 * time.Second
+)
+
+
+
+
+
+type retryTransport struct {
+	base      http.RoundTripper
+	retryMax  int
+	retryWait time.Duration
+	timeout   time.Duration
+	breakers  *BreakerRegistry
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var breaker *CircuitBreaker
+	if t.breakers != nil {
+		breaker = t.breakers.Get(req.URL.Scheme + "://" + req.URL.Host)
+		if !breaker.Allow() {
+			return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, req.URL.Host)
+		}
+	}
+	recorded := false
+	defer func() {
+		if breaker != nil && !recorded {
+			breaker.Release()
+		}
+	}()
+
+	attempts := 1
+	if isRetryable(req) {
+		attempts += t.retryMax
+	}
+
+	for attempt := 0; ; attempt++ {
+		current := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			current = req.Clone(req.Context())
+			current.Body = body
+		}
+
+		cancel := context.CancelFunc(func() {})
+		if t.timeout > 0 {
+			var ctx context.Context
+			ctx, cancel = context.WithTimeout(req.Context(), t.timeout)
+			current = current.WithContext(ctx)
+		}
+
+		resp, err := t.base.RoundTrip(current)
+		if !isServerFailure(resp, err) {
+			if breaker != nil {
+				breaker.RecordSuccess()
+				recorded = true
+			}
+			return withCancel(resp, cancel), err
+		}
+
+		wait, retry := t.nextDelay(attempt, resp)
+		if attempt+1 >= attempts || !retry || req.Context().Err() != nil {
+			if breaker != nil && req.Context().Err() == nil {
+				breaker.RecordFailure()
+				recorded = true
+			}
+			return withCancel(resp, cancel), err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		cancel()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+
+
+
+func (t *retryTransport) nextDelay(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return wait, wait <= maxRetryAfterDelay
+		}
+	}
+
+	backoff := t.retryWait << uint(attempt)
+	if backoff <= 0 || backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1)), true
+}
+
+
+
+
+func withCancel(resp *http.Response, cancel context.CancelFunc) *http.Response {
+	if resp == nil {
+		cancel()
+		return nil
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func isRetryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	default:
+		return false
+	}
+}
+
+func isServerFailure(resp *http.Response, err error) bool {
+	if errors.Is(err, ErrInstanceNotAllowed) || errors.Is(err, ErrPrivateNetworkBlocked) {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		wait := time.Until(at)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
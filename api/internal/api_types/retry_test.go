@@ -0,0 +1,158 @@
+package api_types_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/api_types"
+)
+
+func TestRetry_HonorsRetryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"salt": "1"}`))
+	}))
+	defer server.Close()
+
+	factory, err := api_types.NewAPIClientFactory(api_types.DefaultRegistry, api_types.TransportConfig{})
+	require.NoError(t, err)
+	client, err := factory.NewAPIClient(api_types.NSWebAPI, api_types.APIConfig{Timeout: 5, RetryMax: 3, RetryWait: 1})
+	require.NoError(t, err)
+
+	data, err := client.GetLoginData(context.Background(), server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "1", data["salt"])
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestRetry_DoesNotRetryPost(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	factory, err := api_types.NewAPIClientFactory(api_types.DefaultRegistry, api_types.TransportConfig{})
+	require.NoError(t, err)
+	client, err := factory.NewAPIClient(api_types.NSWebAPI, api_types.APIConfig{Timeout: 5, RetryMax: 3, RetryWait: 1})
+	require.NoError(t, err)
+
+	_, err = client.Login(context.Background(), "user", "secret", 1, server.URL, map[string]interface{}{"salt": "1"})
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestCircuitBreaker_OpensPerInstance(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	factory, err := api_types.NewAPIClientFactory(api_types.DefaultRegistry, api_types.TransportConfig{
+		BreakerThreshold: 2,
+		BreakerCooldown:  time.Minute,
+	})
+	require.NoError(t, err)
+	client, err := factory.NewAPIClient(api_types.NSWebAPI, api_types.APIConfig{Timeout: 5})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		_, err = client.GetLoginData(ctx, server.URL)
+		assert.Error(t, err)
+	}
+	_, err = client.GetLoginData(ctx, server.URL)
+	assert.ErrorIs(t, err, api_types.ErrCircuitOpen)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	states := factory.Breakers().States()
+	require.Len(t, states, 1)
+	assert.Equal(t, api_types.CircuitOpen, states[0].State)
+	assert.Equal(t, server.URL, states[0].Instance)
+}
+
+func TestCircuitBreaker_CancelledProbeReleasesHalfOpen(t *testing.T) {
+	var mode int32
+	probing := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.LoadInt32(&mode) {
+		case 0:
+			w.WriteHeader(http.StatusInternalServerError)
+		case 1:
+			close(probing)
+			<-r.Context().Done()
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	factory, err := api_types.NewAPIClientFactory(api_types.DefaultRegistry, api_types.TransportConfig{
+		BreakerThreshold: 1,
+		BreakerCooldown:  20 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	client, err := factory.NewAPIClient(api_types.NSWebAPI, api_types.APIConfig{Timeout: 5})
+	require.NoError(t, err)
+
+	_, err = client.GetLoginData(context.Background(), server.URL)
+	require.Error(t, err)
+	time.Sleep(30 * time.Millisecond)
+
+	atomic.StoreInt32(&mode, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-probing
+		cancel()
+	}()
+	_, err = client.GetLoginData(ctx, server.URL)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	atomic.StoreInt32(&mode, 2)
+	_, err = client.GetLoginData(context.Background(), server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, api_types.CircuitClosed, factory.Breakers().States()[0].State)
+}
+
+func TestRetry_TimeoutAppliesPerAttempt(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(3 * time.Second):
+			}
+			return
+		}
+		w.Write([]byte(`{"salt": "1"}`))
+	}))
+	defer server.Close()
+
+	factory, err := api_types.NewAPIClientFactory(api_types.DefaultRegistry, api_types.TransportConfig{})
+	require.NoError(t, err)
+	client, err := factory.NewAPIClient(api_types.NSWebAPI, api_types.APIConfig{Timeout: 1, RetryMax: 1, RetryWait: 1})
+	require.NoError(t, err)
+
+	data, err := client.GetLoginData(context.Background(), server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "1", data["salt"])
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
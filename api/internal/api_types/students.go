@@ -0,0 +1,50 @@
+package api_types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+
+type Student struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ClassID   string `json:"class_id,omitempty"`
+	ClassName string `json:"class_name,omitempty"`
+}
+
+
+
+type StudentList struct {
+	Students         []Student `json:"students"`
+	CurrentStudentID string    `json:"current_student_id"`
+}
+
+
+
+type flexibleID string
+
+func (id *flexibleID) UnmarshalJSON(data []byte) error {
+	raw := strings.TrimSpace(string(data))
+	if raw == "null" {
+		*id = ""
+		return nil
+	}
+
+	if strings.HasPrefix(raw, `"`) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*id = flexibleID(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("invalid identifier %s: %w", raw, err)
+	}
+	*id = flexibleID(n.String())
+	return nil
+}
@@ -0,0 +1,128 @@
+package api_types
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"time"
+)
+
+
+type TransportConfig struct {
+	UserAgent           string        
+	ProxyURL            string        
+	InsecureSkipVerify  bool
+	CAFile              string        
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	BreakerThreshold    int           
+	BreakerCooldown     time.Duration 
+	Coalesce            bool
+	Allowlist           *InstanceAllowlist
+}
+
+
+
+func NewTransport(cfg TransportConfig) (http.RoundTripper, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if cfg.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+		if transport.MaxIdleConns < cfg.MaxIdleConnsPerHost {
+			transport.MaxIdleConns = cfg.MaxIdleConnsPerHost
+		}
+	}
+	if cfg.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if cfg.Allowlist == nil {
+		return transport, nil
+	}
+	
+	
+	if !cfg.Allowlist.allowPrivate && cfg.ProxyURL == "" {
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control:   cfg.Allowlist.dialControl,
+		}
+		transport.DialContext = dialer.DialContext
+	}
+	return &instanceGuardTransport{
+		base:         transport,
+		allowlist:    cfg.Allowlist,
+		resolveHosts: !cfg.Allowlist.allowPrivate && cfg.ProxyURL != "",
+	}, nil
+}
+
+
+
+type headerTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.userAgent != "" && req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.base.RoundTrip(req)
+}
+
+
+
+
+func newHTTPClient(transport http.RoundTripper, userAgent string, config APIConfig, breakers *BreakerRegistry, jars *sessionJars) *http.Client {
+	local, _ := cookiejar.New(nil)
+	return &http.Client{
+		Transport: &headerTransport{
+			base: &cookieTransport{
+				base: &retryTransport{
+					base:      transport,
+					retryMax:  config.RetryMax,
+					retryWait: time.Duration(config.RetryWait) * time.Millisecond,
+					timeout:   time.Duration(config.Timeout) * time.Second,
+					breakers:  breakers,
+				},
+				local: local,
+				jars:  jars,
+			},
+			userAgent: userAgent,
+		},
+	}
+}
@@ -0,0 +1,119 @@
+package api_types_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/api_types"
+)
+
+func TestNSWebAPIClient_LoginKeepsSessionCookies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "QuantumDiary/2.0", r.UserAgent())
+		switch r.URL.Path {
+		case "/webapi/logindata":
+			http.SetCookie(w, &http.Cookie{Name: "NSSESSIONID", Value: "abc", Path: "/"})
+			w.Write([]byte(`{"salt": "12345", "lt": "1", "ver": "2"}`))
+		case "/webapi/login":
+			cookie, err := r.Cookie("NSSESSIONID")
+			if assert.NoError(t, err) {
+				assert.Equal(t, "abc", cookie.Value)
+			}
+			w.Write([]byte(`{"at": "access-token"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	factory, err := api_types.NewAPIClientFactory(api_types.DefaultRegistry, api_types.TransportConfig{UserAgent: "QuantumDiary/2.0"})
+	require.NoError(t, err)
+	client, err := factory.NewAPIClient(api_types.NSWebAPI, api_types.APIConfig{Mode: api_types.NSWebAPI, Timeout: 5})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	loginData, err := client.GetLoginData(ctx, server.URL)
+	require.NoError(t, err)
+
+	token, err := client.Login(ctx, "user", "secret", 1, server.URL, loginData)
+	require.NoError(t, err)
+	assert.Equal(t, "access-token", token)
+}
+
+func TestAPIClientFactory_SessionCookiesReachLaterClients(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/webapi/logindata":
+			http.SetCookie(w, &http.Cookie{Name: "NSSESSIONID", Value: "abc", Path: "/"})
+			w.Write([]byte(`{"salt": "12345", "lt": "1", "ver": "2"}`))
+		case "/webapi/login":
+			http.SetCookie(w, &http.Cookie{Name: "ESRNSec", Value: "sec", Path: "/"})
+			w.Write([]byte(`{"at": "access-token"}`))
+		case "/webapi/mysettings":
+			cookies := map[string]string{}
+			for _, cookie := range r.Cookies() {
+				cookies[cookie.Name] = cookie.Value
+			}
+			if r.Header.Get("at") == "access-token" {
+				assert.Equal(t, map[string]string{"NSSESSIONID": "abc", "ESRNSec": "sec"}, cookies)
+			} else {
+				assert.Empty(t, cookies)
+			}
+			w.Write([]byte(`{"userId": 1001, "loginName": "user"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	factory, err := api_types.NewAPIClientFactory(api_types.DefaultRegistry, api_types.TransportConfig{})
+	require.NoError(t, err)
+	newClient := func() api_types.APIClientInterface {
+		client, err := factory.NewAPIClient(api_types.NSWebAPI, api_types.APIConfig{Mode: api_types.NSWebAPI, Timeout: 5})
+		require.NoError(t, err)
+		return client
+	}
+
+	ctx := context.Background()
+	login := newClient()
+	loginData, err := login.GetLoginData(ctx, server.URL)
+	require.NoError(t, err)
+	token, err := login.Login(ctx, "user", "secret", 1, server.URL, loginData)
+	require.NoError(t, err)
+	_, err = login.GetInfo(ctx, token, server.URL)
+	require.NoError(t, err)
+
+	_, err = newClient().GetInfo(ctx, token, server.URL)
+	require.NoError(t, err)
+	_, err = newClient().GetInfo(ctx, "other-token", server.URL)
+	require.NoError(t, err)
+}
+
+func TestNewTransport_InvalidProxy(t *testing.T) {
+	_, err := api_types.NewTransport(api_types.TransportConfig{ProxyURL: "://bad"})
+	assert.Error(t, err)
+}
+
+func TestNewTransport_ProxyModeChecksResolvedHost(t *testing.T) {
+	proxyCalls := 0
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxyCalls++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	allowlist, err := api_types.NewInstanceAllowlist([]string{"http://localhost:8080"}, false)
+	require.NoError(t, err)
+	transport, err := api_types.NewTransport(api_types.TransportConfig{ProxyURL: proxy.URL, Allowlist: allowlist})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "http://localhost:8080/webapi/logindata", nil)
+	require.NoError(t, err)
+	_, err = transport.RoundTrip(req)
+	assert.ErrorIs(t, err, api_types.ErrPrivateNetworkBlocked)
+	assert.Zero(t, proxyCalls)
+}
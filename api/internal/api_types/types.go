@@ -24,20 +24,18 @@ type APIConfig struct {
 
 
 func (m APIMode) IsValid() bool {
-	switch m {
-	case NSWebAPI, NSMobileAPI, DevMockAPI:
-		return true
-	default:
-		return false
-	}
+	_, ok := DefaultRegistry.Lookup(m)
+	return ok
 }
 
 
 func (m APIMode) IsRealAPI() bool {
-	return m == NSWebAPI || m == NSMobileAPI
+	provider, ok := DefaultRegistry.Lookup(m)
+	return ok && !provider.Mock
 }
 
 
 func (m APIMode) IsMockAPI() bool {
-	return m == DevMockAPI
+	provider, ok := DefaultRegistry.Lookup(m)
+	return ok && provider.Mock
 }
\ No newline at end of file
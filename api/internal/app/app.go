@@ -10,17 +10,23 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"netschool-proxy/api/api/internal/api_types"
 	"netschool-proxy/api/api/internal/config"
 	"netschool-proxy/api/api/internal/domain/auth"
+	"netschool-proxy/api/api/internal/domain/calendar"
+	"netschool-proxy/api/api/internal/domain/directory"
 	"netschool-proxy/api/api/internal/domain/cache"
 	"netschool-proxy/api/api/internal/domain/grade"
 	"netschool-proxy/api/api/internal/domain/schedule"
 	"netschool-proxy/api/api/internal/domain/student"
 	"netschool-proxy/api/api/internal/infrastructure/database"
+	"netschool-proxy/api/api/internal/infrastructure/database/migrations"
+	"netschool-proxy/api/api/internal/infrastructure/http/v1/middleware"
 	"netschool-proxy/api/api/internal/pkg/logger"
 	"netschool-proxy/api/api/internal/pkg/security"
 	infraCache "netschool-proxy/api/api/internal/infrastructure/cache"
+	"netschool-proxy/api/api/internal/infrastructure/browser"
 )
 
 
@@ -28,6 +34,10 @@ type App struct {
 	server *http.Server
 	config *config.Config
 	sessionRepo auth.SessionRepository
+	refreshTokenRepo auth.RefreshTokenRepository
+	sessionRefresher *auth.SessionRefresher
+	browserClient *browser.BrowserAuthClient
+	autoCache *cache.AutoCacheService
 }
 
 
@@ -57,7 +67,34 @@ func New(cfg *config.Config) (*App, error) {
 	}
 
 	
-	apiFactory := &api_types.APIClientFactory{}
+	if cfg.Database.AutoMigrate {
+		if err := runMigrations(db, cfg.Database.Type); err != nil {
+			return nil, err
+		}
+	}
+
+	
+	allowlist, err := api_types.NewInstanceAllowlist(allowedInstances(cfg), cfg.NetSchool.Instances.AllowPrivate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid netschool.instances configuration: %w", err)
+	}
+
+	
+	apiFactory, err := api_types.NewAPIClientFactory(api_types.DefaultRegistry, api_types.TransportConfig{
+		UserAgent:           cfg.NetSchool.HTTP.UserAgent,
+		ProxyURL:            cfg.NetSchool.HTTP.ProxyURL,
+		InsecureSkipVerify:  cfg.NetSchool.HTTP.InsecureSkipVerify,
+		CAFile:              cfg.NetSchool.HTTP.CAFile,
+		MaxIdleConnsPerHost: cfg.NetSchool.HTTP.MaxIdleConnsPerHost,
+		IdleConnTimeout:     cfg.NetSchool.HTTP.IdleConnTimeout,
+		BreakerThreshold:    cfg.NetSchool.HTTP.BreakerThreshold,
+		BreakerCooldown:     cfg.NetSchool.HTTP.BreakerCooldown,
+		Coalesce:            cfg.NetSchool.HTTP.Coalesce,
+		Allowlist:           allowlist,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize NetSchool HTTP transport: %w", err)
+	}
 	apiConfig := api_types.APIConfig{
 		Mode:       api_types.APIMode(cfg.NetSchool.Mode),
 		Timeout:    int(cfg.NetSchool.Timeout.Seconds()),
@@ -66,13 +103,20 @@ func New(cfg *config.Config) (*App, error) {
 	}
 
 	
-	jwtService := security.NewJWTService(cfg.JWT.Secret, cfg.JWT.ExpiresIn)
-
-	
-	sessionRepo := database.NewSessionRepository(db)
+	jwtService, err := newJWTService(cfg.JWT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize JWT signing: %w", err)
+	}
 
 	
-	authService := auth.NewService(sessionRepo, apiFactory, apiConfig, jwtService)
+	var sessionRepo auth.SessionRepository = database.NewSessionRepository(db)
+	if cfg.Encryption.Enabled() {
+		tokenCipher, err := newTokenCipher(cfg.Encryption)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize token encryption: %w", err)
+		}
+		sessionRepo = database.NewEncryptedSessionRepository(sessionRepo, tokenCipher)
+	}
 
 	
 	var cacheService cache.CacheStrategy
@@ -87,15 +131,71 @@ func New(cfg *config.Config) (*App, error) {
 	}
 
 	
+	refreshTokenRepo := database.NewRefreshTokenRepository(db)
+	revocations := auth.NewRevocationStore(cacheService, cfg.JWT.ExpiresIn)
+	if cfg.Cache.Type != "redis" {
+		logger.Warn("Token revocations are kept in process memory and are lost on restart or not shared between replicas, use cache.type=redis in production")
+	}
+
+	
+	sessionConfig := auth.SessionConfig{
+		TokenTTL:        cfg.NetSchool.TokenTTL,
+		SessionTTL:      cfg.NetSchool.SessionTTL,
+		RefreshBefore:   cfg.NetSchool.RefreshBefore,
+		RefreshTokenTTL: cfg.JWT.RefreshTokenTTL,
+	}
+	authService := auth.NewService(sessionRepo, refreshTokenRepo, revocations, apiFactory, allowlist, apiConfig, jwtService, sessionConfig)
+
+	
+	cacheMiddleware := middleware.NewCacheMiddleware(cacheService, middleware.DefaultVaryHeaders...)
+	authService.SetCachePurger(cacheMiddleware)
+
+	
+	var browserClient *browser.BrowserAuthClient
+	if cfg.NetSchool.Browser.Enabled {
+		browserClient, err = browser.NewBrowserAuthClient(browser.Config{
+			Headless:     cfg.NetSchool.Browser.Headless,
+			Timeout:      cfg.NetSchool.Browser.Timeout,
+			PollInterval: cfg.NetSchool.Browser.PollInterval,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize browser login: %w", err)
+		}
+		if !browserClient.IsAvailable() {
+			logger.Warn("Browser login is enabled but Playwright is not available")
+		}
+		authService.SetBrowserLogin(browserClient, auth.BrowserLoginConfig{
+			Instances: cfg.NetSchool.Browser.Instances,
+			Fallback:  cfg.NetSchool.Browser.Fallback,
+		})
+	}
+
+	
 	defaultAPIClient, err := apiFactory.NewAPIClient(api_types.APIMode(cfg.NetSchool.Mode), apiConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create default API client: %w", err)
 	}
 
 	
-	studentService := student.NewService(apiFactory, sessionRepo, apiConfig)
-	gradeService := grade.NewService(apiFactory, sessionRepo, cacheService, apiConfig)
-	scheduleService := schedule.NewService(apiFactory, sessionRepo, cacheService, apiConfig)
+	studentService := student.NewService(apiFactory, sessionRepo, apiConfig, authService.Refresher())
+	directoryService := directory.NewService(apiFactory, cacheService, apiConfig)
+	calendarService := calendar.NewService(apiFactory, sessionRepo, cacheService, apiConfig, authService.Refresher())
+	gradeService := grade.NewService(apiFactory, sessionRepo, cacheService, apiConfig, authService.Refresher(), calendarService)
+	scheduleService := schedule.NewService(apiFactory, sessionRepo, cacheService, apiConfig, authService.Refresher())
+
+	
+	var autoCache *cache.AutoCacheService
+	if cfg.Cache.Prefetch.Enabled {
+		autoCache = cache.NewAutoCacheService(sessionRepo, cache.AutoCacheConfig{
+			Interval:      cfg.Cache.Prefetch.Interval,
+			ActiveWithin:  cfg.Cache.Prefetch.ActiveWithin,
+			Concurrency:   cfg.Cache.Prefetch.Concurrency,
+			PerInstance:   cfg.Cache.Prefetch.PerInstance,
+			InstanceDelay: cfg.Cache.Prefetch.InstanceDelay,
+			StartHour:     cfg.Cache.Prefetch.StartHour,
+			EndHour:       cfg.Cache.Prefetch.EndHour,
+		}, scheduleService, gradeService)
+	}
 
 	
 	router := gin.New()
@@ -103,7 +203,7 @@ func New(cfg *config.Config) (*App, error) {
 	router.Use(gin.Logger())
 
 	
-	setupRoutes(router, authService, studentService, gradeService, scheduleService, cacheService, jwtService, sessionRepo, defaultAPIClient)
+	setupRoutes(router, authService, studentService, gradeService, scheduleService, calendarService, directoryService, cacheMiddleware, jwtService, sessionRepo, defaultAPIClient, apiFactory.Breakers(), apiFactory.Coalescer(), allowlist, cfg.Admin.UserIDs)
 
 	
 	server := &http.Server{
@@ -118,18 +218,31 @@ func New(cfg *config.Config) (*App, error) {
 		server: server,
 		config: cfg,
 		sessionRepo: sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		sessionRefresher: authService.Refresher(),
+		browserClient: browserClient,
+		autoCache: autoCache,
 	}, nil
 }
 
 
 func (a *App) Start() error {
 	
-	cleanupService := auth.NewCleanupService(a.sessionRepo, 1*time.Hour)
+	cleanupService := auth.NewCleanupService(a.sessionRepo, a.refreshTokenRepo, 1*time.Hour)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	go cleanupService.StartCleanup(ctx)
 
 	
+	go a.sessionRefresher.StartRefresh(ctx, a.config.NetSchool.RefreshInterval)
+
+	
+	if a.autoCache != nil {
+		a.autoCache.Start(ctx)
+		defer a.autoCache.Stop()
+	}
+
+	
 	logger.Info("Starting server", "port", a.config.Server.Port)
 	
 	go func() {
@@ -150,6 +263,9 @@ func (a *App) Start() error {
 
 	var err error
 	err = a.server.Shutdown(shutdownCtx)
+	if a.browserClient != nil {
+		a.browserClient.Close()
+	}
 	if err != nil {
 		logger.Error("Server forced to shutdown", "error", err)
 		return err
@@ -157,4 +273,66 @@ func (a *App) Start() error {
 
 	logger.Info("Server exited")
 	return nil
-}
\ No newline at end of file
+}
+
+
+
+
+
+func allowedInstances(cfg *config.Config) []string {
+	instances := append([]string{}, cfg.NetSchool.Instances.Allowed...)
+	instances = append(instances, cfg.NetSchool.Browser.Instances...)
+	if cfg.NetSchool.Instances.IncludeCatalog {
+		for _, instance := range directory.KnownInstances() {
+			instances = append(instances, instance.URL)
+		}
+	}
+	return instances
+}
+
+func runMigrations(db *gorm.DB, dbType string) error {
+	migrator, err := migrations.NewMigrator(db, dbType)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+	applied, err := migrator.Up(context.Background())
+	for _, migration := range applied {
+		logger.Info("Applied database migration", "version", migration.Version, "name", migration.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return nil
+}
+
+func newTokenCipher(cfg config.EncryptionConfig) (*security.TokenCipher, error) {
+	keys, err := security.LoadKeys(cfg.Keys, cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return security.NewTokenCipher(cfg.ActiveKeyID, keys)
+}
+
+
+
+func newJWTService(cfg config.JWTConfig) (*security.JWTService, error) {
+	if !cfg.UsesKeySet() {
+		return security.NewJWTService(cfg.Secret, cfg.ExpiresIn), nil
+	}
+
+	keys := make([]*security.JWTKey, 0, len(cfg.Keys))
+	for _, keyConfig := range cfg.Keys {
+		key, err := security.LoadJWTKey(keyConfig.ID, keyConfig.PrivateKeyFile, keyConfig.PublicKeyFile, keyConfig.RetiredAt)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	legacySecret := ""
+	if cfg.AcceptLegacyHS256 {
+		legacySecret = cfg.Secret
+	}
+
+	return security.NewKeySetJWTService(keys, cfg.SigningKeyID, cfg.RetiredKeyGrace, cfg.ExpiresIn, legacySecret)
+}
@@ -6,7 +6,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"netschool-proxy/api/api/internal/api_types"
 	"netschool-proxy/api/api/internal/domain/auth"
-	"netschool-proxy/api/api/internal/domain/cache"
+	"netschool-proxy/api/api/internal/domain/calendar"
+	"netschool-proxy/api/api/internal/domain/directory"
 	"netschool-proxy/api/api/internal/domain/grade"
 	"netschool-proxy/api/api/internal/domain/schedule"
 	"netschool-proxy/api/api/internal/domain/student"
@@ -22,42 +23,69 @@ func setupRoutes(
 	studentService *student.Service,
 	gradeService *grade.Service,
 	scheduleService *schedule.Service,
-	cacheService cache.CacheStrategy,
+	calendarService *calendar.Service,
+	directoryService *directory.Service,
+	cacheMiddleware *middleware.CacheMiddleware,
 	jwtService *security.JWTService,
 	sessionRepo auth.SessionRepository,
 	defaultAPIClient api_types.APIClientInterface,
+	breakers *api_types.BreakerRegistry,
+	coalescer *api_types.Coalescer,
+	allowlist *api_types.InstanceAllowlist,
+	adminUserIDs []string,
 ) {
 	
 	authHandler := v1.NewAuthHandler(authService)
-	healthHandler := v1.NewHealthHandler(defaultAPIClient, sessionRepo)
+	healthHandler := v1.NewHealthHandler(defaultAPIClient, sessionRepo, breakers, coalescer, authService.BrowserLoginStatus)
 	studentHandler := v1.NewStudentHandler(studentService)
 	gradeHandler := v1.NewGradeHandler(gradeService)
 	scheduleHandler := v1.NewScheduleHandler(scheduleService, studentService)
 	schoolHandler := v1.NewSchoolHandler(studentService)
 	assignmentHandler := v1.NewAssignmentHandler(gradeService)
+	calendarHandler := v1.NewCalendarHandler(calendarService)
+	directoryHandler := v1.NewDirectoryHandler(directoryService, authService.Providers())
+	jwksHandler := v1.NewJWKSHandler(jwtService)
+	adminHandler := v1.NewAdminHandler(authService)
+	providerHandler := v1.NewProviderHandler(authService.Providers())
 
 	
 	authMiddleware := middleware.NewAuthMiddleware(authService, jwtService)
-	cacheMiddleware := middleware.NewCacheMiddleware(cacheService)
+	adminMiddleware := middleware.NewAdminMiddleware(adminUserIDs)
 	rateLimiter := middleware.NewRateLimiter(10, 20) 
 
 	
+	router.Use(middleware.RequestID(), middleware.ErrorHandler())
+	router.Use(middleware.InstanceGuard(allowlist))
+
+	
 	public := router.Group("/")
 	{
 		public.GET("/health/ping", healthHandler.Ping)
 		public.GET("/health/intping", healthHandler.IntPing)
 		public.GET("/health/full", healthHandler.FullHealth)
+		public.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
+		public.GET("/providers", providerHandler.GetProviders)
+		public.GET("/directory/instances", directoryHandler.GetInstances)
+		public.GET("/directory/schools", rateLimiter.RateLimitMiddleware(), directoryHandler.GetSchools)
 		public.POST("/auth/login", rateLimiter.RateLimitMiddleware(), authHandler.Login)
+		public.POST("/auth/refresh", rateLimiter.RateLimitMiddleware(), authHandler.Refresh)
+		public.POST("/auth/login/device", rateLimiter.RateLimitMiddleware(), authHandler.StartDeviceLogin)
+		public.POST("/auth/login/esia", rateLimiter.RateLimitMiddleware(), authHandler.ESIALogin)
+		public.GET("/auth/login/device/:login_id", authHandler.GetDeviceLoginStatus)
 	}
 
 	
 	protected := router.Group("/api/v1")
-	protected.Use(authMiddleware.AuthRequired())
+	protected.Use(authMiddleware.AuthRequired(), middleware.SessionInstance(), middleware.CacheStatus())
 	{
 		
 		protected.POST("/auth/logout", authHandler.Logout)
+		protected.POST("/auth/logout/all", authHandler.LogoutAll)
+		protected.GET("/auth/sessions", authHandler.ListSessions)
+		protected.DELETE("/auth/sessions/:session_id", authHandler.RevokeSession)
 
 		
+		protected.GET("/students", studentHandler.ListStudents)
 		protected.GET("/students/me", cacheMiddleware.CacheResponse(5*time.Minute), studentHandler.GetStudentInfo)
 		protected.GET("/students/class", cacheMiddleware.CacheResponse(10*time.Minute), studentHandler.GetStudentsByClass)
 
@@ -74,6 +102,10 @@ func setupRoutes(
 		protected.GET("/school/classes", cacheMiddleware.CacheResponse(1*time.Hour), schoolHandler.GetClasses)
 
 		
+		protected.GET("/years", calendarHandler.GetYears)
+		protected.GET("/terms", calendarHandler.GetTerms)
+
+		
 		protected.GET("/diary", cacheMiddleware.CacheResponse(1*time.Hour), scheduleHandler.GetWeeklySchedule)
 
 		
@@ -81,7 +113,7 @@ func setupRoutes(
 		protected.GET("/assignments/types", cacheMiddleware.CacheResponse(1*time.Hour), assignmentHandler.GetAssignmentTypes)
 
 		
-		protected.GET("/journal", cacheMiddleware.CacheResponse(10*time.Minute), gradeHandler.GetGradesForStudent)
+		protected.GET("/journal", cacheMiddleware.CacheResponse(10*time.Minute), gradeHandler.GetJournal)
 		protected.GET("/journal/full", cacheMiddleware.CacheResponse(10*time.Minute), gradeHandler.GetGradesForSubject) 
 
 		
@@ -95,10 +127,11 @@ func setupRoutes(
 
 	
 	admin := router.Group("/admin")
-	admin.Use(authMiddleware.AuthRequired())
+	admin.Use(authMiddleware.AuthRequired(), adminMiddleware.AdminOnly())
 	{
 		
-		
-		
+		admin.POST("/revocations/tokens", adminHandler.RevokeToken)
+		admin.POST("/revocations/sessions/:session_id", adminHandler.RevokeSession)
+		admin.POST("/revocations/users/:user_id", adminHandler.RevokeUser)
 	}
 }
\ No newline at end of file
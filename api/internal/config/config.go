@@ -15,6 +15,8 @@ type Config struct {
 	Cache      CacheConfig      `yaml:"cache" env-prefix:"CACHE_"`
 	NetSchool  NetSchoolConfig  `yaml:"netschool" env-prefix:"NETSCHOOL_"`
 	JWT        JWTConfig        `yaml:"jwt" env-prefix:"JWT_"`
+	Encryption EncryptionConfig `yaml:"encryption" env-prefix:"ENCRYPTION_"`
+	Admin      AdminConfig      `yaml:"admin" env-prefix:"ADMIN_"`
 	Logging    LoggingConfig    `yaml:"logging" env-prefix:"LOGGING_"`
 }
 
@@ -48,6 +50,7 @@ type DatabaseConfig struct {
 	SSLMode  string `yaml:"sslmode" env:"SSLMODE" env-default:"disable"`
 	URL      string `yaml:"url" env:"URL"` 
 	SQLitePath string `yaml:"sqlite_path" env:"SQLITE_PATH" env-default:"./db.sqlite"`
+	AutoMigrate bool  `yaml:"auto_migrate" env:"AUTO_MIGRATE" env-default:"false"`
 }
 
 type CacheConfig struct {
@@ -55,18 +58,104 @@ type CacheConfig struct {
 	RedisAddr  string `yaml:"redis_addr" env:"REDIS_ADDR" env-default:"localhost:6379"`
 	TTL        int    `yaml:"ttl" env:"TTL" env-default:"300"` 
 	MemorySize int    `yaml:"memory_size" env:"MEMORY_SIZE" env-default:"1000"`
+	Prefetch   PrefetchConfig `yaml:"prefetch" env-prefix:"PREFETCH_"`
+}
+
+
+type PrefetchConfig struct {
+	Enabled       bool          `yaml:"enabled" env:"ENABLED" env-default:"false"`
+	Interval      time.Duration `yaml:"interval" env:"INTERVAL" env-default:"15m"`
+	ActiveWithin  time.Duration `yaml:"active_within" env:"ACTIVE_WITHIN" env-default:"168h"` 
+	Concurrency   int           `yaml:"concurrency" env:"CONCURRENCY" env-default:"4"`
+	PerInstance   int           `yaml:"per_instance" env:"PER_INSTANCE" env-default:"1"`       
+	InstanceDelay time.Duration `yaml:"instance_delay" env:"INSTANCE_DELAY" env-default:"500ms"` 
+	StartHour     int           `yaml:"start_hour" env:"START_HOUR" env-default:"6"`
+	EndHour       int           `yaml:"end_hour" env:"END_HOUR" env-default:"22"`
 }
 
 type NetSchoolConfig struct {
-	Mode      string        `yaml:"mode" env:"MODE" env-default:"ns-webapi"` 
-	Timeout   time.Duration `yaml:"timeout" env:"TIMEOUT" env-default:"30s"`
-	RetryMax  int           `yaml:"retry_max" env:"RETRY_MAX" env-default:"3"`
-	RetryWait time.Duration `yaml:"retry_wait" env:"RETRY_WAIT" env-default:"1s"`
+	Mode            string        `yaml:"mode" env:"MODE" env-default:"ns-webapi"` 
+	Timeout         time.Duration `yaml:"timeout" env:"TIMEOUT" env-default:"30s"`
+	RetryMax        int           `yaml:"retry_max" env:"RETRY_MAX" env-default:"3"`
+	RetryWait       time.Duration `yaml:"retry_wait" env:"RETRY_WAIT" env-default:"1s"`
+	TokenTTL        time.Duration `yaml:"token_ttl" env:"TOKEN_TTL" env-default:"24h"`
+	SessionTTL      time.Duration `yaml:"session_ttl" env:"SESSION_TTL" env-default:"720h"`
+	RefreshBefore   time.Duration `yaml:"refresh_before" env:"REFRESH_BEFORE" env-default:"10m"`
+	RefreshInterval time.Duration `yaml:"refresh_interval" env:"REFRESH_INTERVAL" env-default:"5m"`
+	HTTP            HTTPConfig    `yaml:"http" env-prefix:"HTTP_"`
+	Browser         BrowserConfig `yaml:"browser" env-prefix:"BROWSER_"`
+	Instances       InstancesConfig `yaml:"instances" env-prefix:"INSTANCES_"`
+}
+
+
+
+type InstancesConfig struct {
+	Allowed        []string `yaml:"allowed" env:"ALLOWED"`
+	IncludeCatalog bool     `yaml:"include_catalog" env:"INCLUDE_CATALOG" env-default:"true"`
+	AllowPrivate   bool     `yaml:"allow_private" env:"ALLOW_PRIVATE" env-default:"false"`
+}
+
+
+type BrowserConfig struct {
+	Enabled      bool          `yaml:"enabled" env:"ENABLED" env-default:"false"`
+	Fallback     bool          `yaml:"fallback" env:"FALLBACK" env-default:"true"` 
+	Instances    []string      `yaml:"instances" env:"INSTANCES"`                  
+	Headless     bool          `yaml:"headless" env:"HEADLESS" env-default:"true"`
+	Timeout      time.Duration `yaml:"timeout" env:"TIMEOUT" env-default:"60s"`
+	PollInterval time.Duration `yaml:"poll_interval" env:"POLL_INTERVAL" env-default:"5s"`
+}
+
+
+type HTTPConfig struct {
+	UserAgent           string        `yaml:"user_agent" env:"USER_AGENT"` 
+	ProxyURL            string        `yaml:"proxy_url" env:"PROXY_URL"`
+	InsecureSkipVerify  bool          `yaml:"insecure_skip_verify" env:"INSECURE_SKIP_VERIFY" env-default:"false"`
+	CAFile              string        `yaml:"ca_file" env:"CA_FILE"`
+	MaxIdleConnsPerHost int           `yaml:"max_idle_conns_per_host" env:"MAX_IDLE_CONNS_PER_HOST" env-default:"16"`
+	IdleConnTimeout     time.Duration `yaml:"idle_conn_timeout" env:"IDLE_CONN_TIMEOUT" env-default:"90s"`
+	BreakerThreshold    int           `yaml:"breaker_threshold" env:"BREAKER_THRESHOLD" env-default:"5"` 
+	BreakerCooldown     time.Duration `yaml:"breaker_cooldown" env:"BREAKER_COOLDOWN" env-default:"30s"` 
+	Coalesce            bool          `yaml:"coalesce" env:"COALESCE" env-default:"true"`
 }
 
 type JWTConfig struct {
-	Secret    string        `yaml:"secret" env:"SECRET" env-default:"default_secret_key_change_me"`
-	ExpiresIn time.Duration `yaml:"expires_in" env:"EXPIRES_IN" env-default:"24h"`
+	Secret            string         `yaml:"secret" env:"SECRET" env-default:"default_secret_key_change_me"`
+	ExpiresIn         time.Duration  `yaml:"expires_in" env:"EXPIRES_IN" env-default:"15m"`
+	RefreshTokenTTL   time.Duration  `yaml:"refresh_token_ttl" env:"REFRESH_TOKEN_TTL" env-default:"720h"`
+	SigningKeyID      string         `yaml:"signing_key_id" env:"SIGNING_KEY_ID"` 
+	Keys              []JWTKeyConfig `yaml:"keys"` 
+	RetiredKeyGrace   time.Duration  `yaml:"retired_key_grace" env:"RETIRED_KEY_GRACE" env-default:"24h"` 
+	AcceptLegacyHS256 bool           `yaml:"accept_legacy_hs256" env:"ACCEPT_LEGACY_HS256" env-default:"false"` 
+}
+
+
+type JWTKeyConfig struct {
+	ID             string    `yaml:"id"`
+	PrivateKeyFile string    `yaml:"private_key_file"`
+	PublicKeyFile  string    `yaml:"public_key_file"`
+	RetiredAt      time.Time `yaml:"retired_at"` 
+}
+
+
+func (c JWTConfig) UsesKeySet() bool {
+	return len(c.Keys) > 0
+}
+
+
+type EncryptionConfig struct {
+	ActiveKeyID string            `yaml:"active_key_id" env:"ACTIVE_KEY_ID"`
+	Keys        map[string]string `yaml:"keys" env:"KEYS"` 
+	KeyFile     string            `yaml:"key_file" env:"KEY_FILE"` 
+}
+
+
+func (c EncryptionConfig) Enabled() bool {
+	return c.ActiveKeyID != ""
+}
+
+
+type AdminConfig struct {
+	UserIDs []string `yaml:"user_ids" env:"USER_IDS"` 
 }
 
 type LoggingConfig struct {
@@ -32,9 +32,29 @@ func (cfg *Config) Validate() error {
 	}
 
 	
+	if cfg.NetSchool.RefreshInterval <= 0 {
+		errs = append(errs, "netschool.refresh_interval must be positive")
+	}
+
+	
+	if !cfg.Encryption.Enabled() && (len(cfg.Encryption.Keys) > 0 || cfg.Encryption.KeyFile != "") {
+		errs = append(errs, "encryption.active_key_id is required when encryption keys are configured")
+	}
 
 	
-	if cfg.JWT.Secret == "" || cfg.JWT.Secret == "default_secret_key_change_me" {
+	if cfg.JWT.UsesKeySet() {
+		for i, key := range cfg.JWT.Keys {
+			if key.ID == "" {
+				errs = append(errs, fmt.Sprintf("jwt.keys[%d].id is required", i))
+			}
+			if key.PrivateKeyFile == "" && key.PublicKeyFile == "" {
+				errs = append(errs, fmt.Sprintf("jwt.keys[%d] needs private_key_file or public_key_file", i))
+			}
+		}
+		if cfg.JWT.AcceptLegacyHS256 && (cfg.JWT.Secret == "" || cfg.JWT.Secret == "default_secret_key_change_me") {
+			errs = append(errs, "jwt.secret is required when jwt.accept_legacy_hs256 is enabled")
+		}
+	} else if cfg.JWT.Secret == "" || cfg.JWT.Secret == "default_secret_key_change_me" {
 		errs = append(errs, "jwt.secret is required and should not be default value")
 	}
 
@@ -51,7 +71,7 @@ func (cfg *Config) GetDatabaseURL() string {
 		return cfg.Database.URL
 	}
 
-	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
+	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
 		cfg.Database.User,
 		cfg.Database.Password,
 		cfg.Database.Host,
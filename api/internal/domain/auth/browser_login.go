@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/pkg/logger"
+)
+
+
+type BrowserLogin interface {
+	Authenticate(ctx context.Context, tokenClient api_types.DeviceFlowClient, instanceURL, username, password string, schoolID int) (*api_types.OAuthToken, error)
+	IsAvailable() bool
+}
+
+
+type ESIALogin interface {
+	AuthenticateESIA(ctx context.Context, tokenClient api_types.DeviceFlowClient, instanceURL string, credentials api_types.ESIACredentials) (*api_types.OAuthToken, *api_types.ESIAAccount, error)
+}
+
+
+type BrowserLoginConfig struct {
+	Instances []string
+	Fallback  bool
+}
+
+
+const (
+	BrowserLoginDisabled    = "disabled"
+	BrowserLoginAvailable   = "available"
+	BrowserLoginUnavailable = "unavailable"
+)
+
+
+
+func (s *Service) SetBrowserLogin(browser BrowserLogin, config BrowserLoginConfig) {
+	s.browserLogin = browser
+	s.browserConfig = config
+}
+
+
+func (s *Service) BrowserLoginStatus() string {
+	if s.browserLogin == nil {
+		return BrowserLoginDisabled
+	}
+	if !s.browserLogin.IsAvailable() {
+		return BrowserLoginUnavailable
+	}
+	return BrowserLoginAvailable
+}
+
+
+func (s *Service) browserLoginRequired(instanceURL string) bool {
+	if s.browserLogin == nil {
+		return false
+	}
+	instance, err := api_types.NormalizeInstanceURL(instanceURL)
+	if err != nil {
+		return false
+	}
+	for _, configured := range s.browserConfig.Instances {
+		if normalized, err := api_types.NormalizeInstanceURL(configured); err == nil && normalized == instance {
+			return true
+		}
+	}
+	return false
+}
+
+
+func (s *Service) browserFallbackAllowed(err error) bool {
+	return s.browserLogin != nil && s.browserConfig.Fallback && errors.Is(err, api_types.ErrInteractiveLoginRequired)
+}
+
+
+
+func (s *Service) loginWithBrowser(ctx context.Context, username, password string, schoolID int, instanceURL string, device DeviceInfo) (*TokenPair, error) {
+	apiClient, tokenClient, err := s.browserTokenClient()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.browserLogin.Authenticate(ctx, tokenClient, instanceURL, username, password, schoolID)
+	if err != nil {
+		return nil, fmt.Errorf("browser login failed: %w", err)
+	}
+
+	logger.Info("Authenticated via browser login", "instance", instanceURL)
+	return s.createSession(ctx, apiClient, token, username, schoolID, instanceURL, string(api_types.NSMobileAPI), device)
+}
+
+
+
+
+
+func (s *Service) LoginWithESIA(ctx context.Context, credentials api_types.ESIACredentials, instanceURL string, device DeviceInfo) (*TokenPair, error) {
+	instanceURL, err := s.instances.Check(instanceURL)
+	if err != nil {
+		return nil, err
+	}
+
+	esia, ok := s.browserLogin.(ESIALogin)
+	if !ok {
+		return nil, api_types.ErrESIANotSupported
+	}
+
+	apiClient, tokenClient, err := s.browserTokenClient()
+	if err != nil {
+		return nil, err
+	}
+
+	token, account, err := esia.AuthenticateESIA(ctx, tokenClient, instanceURL, credentials)
+	if err != nil {
+		return nil, fmt.Errorf("ESIA login failed: %w", err)
+	}
+
+	schoolID := 0
+	if account != nil {
+		schoolID = account.SchoolID
+	}
+
+	logger.Info("Authenticated via ESIA", "instance", instanceURL)
+	return s.createSession(ctx, apiClient, token, esiaUsername(credentials.Login), schoolID, instanceURL, string(api_types.NSMobileAPI), device)
+}
+
+
+
+func (s *Service) browserTokenClient() (api_types.APIClientInterface, api_types.DeviceFlowClient, error) {
+	clientConfig := s.config
+	clientConfig.Mode = api_types.NSMobileAPI
+
+	apiClient, err := s.apiClientFactory.NewAPIClient(api_types.NSMobileAPI, clientConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	tokenClient, ok := api_types.UnwrapClient(apiClient).(api_types.DeviceFlowClient)
+	if !ok {
+		return nil, nil, api_types.ErrDeviceFlowNotSupported
+	}
+	return apiClient, tokenClient, nil
+}
+
+
+
+func esiaUsername(login string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(login))))
+	return "esia_" + hex.EncodeToString(sum[:8])
+}
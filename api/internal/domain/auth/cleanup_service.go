@@ -10,15 +10,17 @@ import (
 
 
 type CleanupService struct {
-	sessionRepo SessionRepository
-	interval    time.Duration
+	sessionRepo      SessionRepository
+	refreshTokenRepo RefreshTokenRepository
+	interval         time.Duration
 }
 
 
-func NewCleanupService(sessionRepo SessionRepository, interval time.Duration) *CleanupService {
+func NewCleanupService(sessionRepo SessionRepository, refreshTokenRepo RefreshTokenRepository, interval time.Duration) *CleanupService {
 	return &CleanupService{
-		sessionRepo: sessionRepo,
-		interval:    interval,
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		interval:         interval,
 	}
 }
 
@@ -51,6 +53,10 @@ func (cs *CleanupService) cleanupExpiredSessions() {
 	} else {
 		logger.Info("Successfully cleaned up expired sessions")
 	}
+
+	if err := cs.refreshTokenRepo.CleanupExpired(ctx); err != nil {
+		logger.Error("Failed to cleanup expired refresh tokens", "error", err)
+	}
 }
 
 
@@ -58,7 +64,10 @@ func (cs *CleanupService) ManualCleanup() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	return cs.sessionRepo.CleanupExpired(ctx)
+	if err := cs.sessionRepo.CleanupExpired(ctx); err != nil {
+		return err
+	}
+	return cs.refreshTokenRepo.CleanupExpired(ctx)
 }
 
 
@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/pkg/logger"
+)
+
+
+var ErrDeviceLoginNotFound = errors.New("device login not found")
+
+const (
+	deviceLoginRetention      = 5 * time.Minute
+	deviceLoginSlowDownStep   = 5 * time.Second
+	deviceLoginSessionTimeout = 30 * time.Second
+	deviceLoginDefaultTTL     = 10 * time.Minute
+)
+
+
+type DeviceLoginStatus string
+
+const (
+	DeviceLoginPending   DeviceLoginStatus = "pending"
+	DeviceLoginCompleted DeviceLoginStatus = "completed"
+	DeviceLoginExpired   DeviceLoginStatus = "expired"
+	DeviceLoginFailed    DeviceLoginStatus = "failed"
+)
+
+
+type DeviceLogin struct {
+	ID              string              `json:"login_id"`
+	UserCode        string              `json:"user_code"`
+	VerificationURI string              `json:"verification_uri"`
+	Interval        int                 `json:"interval"`
+	ExpiresAt       time.Time           `json:"expires_at"`
+	Status          DeviceLoginStatus   `json:"status"`
+	Token           string              `json:"token,omitempty"`
+	RefreshToken    string              `json:"refresh_token,omitempty"`
+	ExpiresIn       int                 `json:"expires_in,omitempty"`
+	ErrorCode       api_types.ErrorCode `json:"error_code,omitempty"`
+}
+
+
+type deviceLoginEntry struct {
+	login DeviceLogin
+	done  chan struct{}
+}
+
+
+type deviceLoginStore struct {
+	mu     sync.RWMutex
+	logins map[string]*deviceLoginEntry
+}
+
+func newDeviceLoginStore() *deviceLoginStore {
+	return &deviceLoginStore{logins: make(map[string]*deviceLoginEntry)}
+}
+
+func (st *deviceLoginStore) add(entry *deviceLoginEntry) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.logins[entry.login.ID] = entry
+}
+
+func (st *deviceLoginStore) get(id string) (*deviceLoginEntry, DeviceLogin, bool) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	entry, exists := st.logins[id]
+	if !exists {
+		return nil, DeviceLogin{}, false
+	}
+	return entry, entry.login, true
+}
+
+func (st *deviceLoginStore) finish(id string, status DeviceLoginStatus, tokens *TokenPair, err error) {
+	st.mu.Lock()
+	entry, exists := st.logins[id]
+	if exists {
+		entry.login.Status = status
+		if tokens != nil {
+			entry.login.Token = tokens.AccessToken
+			entry.login.RefreshToken = tokens.RefreshToken
+			entry.login.ExpiresIn = tokens.ExpiresIn
+		}
+		if err != nil {
+			entry.login.ErrorCode = api_types.ErrorCodeOf(err)
+		}
+		close(entry.done)
+	}
+	st.mu.Unlock()
+
+	if exists {
+		time.AfterFunc(deviceLoginRetention, func() { st.remove(id) })
+	}
+}
+
+func (st *deviceLoginStore) remove(id string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	delete(st.logins, id)
+}
+
+
+func (s *Service) SupportsDeviceLogin(apiType string) bool {
+	provider, ok := s.Providers().Lookup(api_types.APIMode(apiType))
+	return ok && provider.Login.Supports(api_types.LoginMethodDeviceCode)
+}
+
+
+
+func (s *Service) StartDeviceLogin(ctx context.Context, schoolID int, instanceURL, apiType string, device DeviceInfo) (*DeviceLogin, error) {
+	instanceURL, err := s.instances.Check(instanceURL)
+	if err != nil {
+		return nil, err
+	}
+
+	apiMode := api_types.APIMode(apiType)
+	clientConfig := s.config
+	clientConfig.Mode = apiMode
+
+	apiClient, err := s.apiClientFactory.NewAPIClient(apiMode, clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	deviceClient, ok := api_types.UnwrapClient(apiClient).(api_types.DeviceFlowClient)
+	if !ok {
+		return nil, api_types.ErrDeviceFlowNotSupported
+	}
+
+	authorization, err := deviceClient.StartDeviceAuthorization(ctx, instanceURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start device authorization: %w", err)
+	}
+
+	loginID, err := newDeviceLoginID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate login id: %w", err)
+	}
+
+	interval := authorization.Interval
+	if interval <= 0 {
+		interval = 5
+	}
+	ttl := time.Duration(authorization.ExpiresIn) * time.Second
+	if ttl <= 0 {
+		ttl = deviceLoginDefaultTTL
+	}
+
+	entry := &deviceLoginEntry{
+		login: DeviceLogin{
+			ID:              loginID,
+			UserCode:        authorization.UserCode,
+			VerificationURI: authorization.VerificationURI,
+			Interval:        interval,
+			ExpiresAt:       time.Now().Add(ttl),
+			Status:          DeviceLoginPending,
+		},
+		done: make(chan struct{}),
+	}
+	s.deviceLogins.add(entry)
+
+	go s.pollDeviceLogin(entry.login, apiClient, deviceClient, authorization.DeviceCode, schoolID, instanceURL, apiType, device)
+
+	login := entry.login
+	return &login, nil
+}
+
+
+
+func (s *Service) WaitDeviceLogin(ctx context.Context, loginID string, wait time.Duration) (*DeviceLogin, error) {
+	entry, login, exists := s.deviceLogins.get(loginID)
+	if !exists {
+		return nil, ErrDeviceLoginNotFound
+	}
+
+	if login.Status == DeviceLoginPending && wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-entry.done:
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+
+		_, login, exists = s.deviceLogins.get(loginID)
+		if !exists {
+			return nil, ErrDeviceLoginNotFound
+		}
+	}
+
+	return &login, nil
+}
+
+
+func (s *Service) pollDeviceLogin(login DeviceLogin, apiClient api_types.APIClientInterface, deviceClient api_types.DeviceFlowClient, deviceCode string, schoolID int, instanceURL, apiType string, device DeviceInfo) {
+	ctx, cancel := context.WithDeadline(context.Background(), login.ExpiresAt)
+	defer cancel()
+
+	interval := time.Duration(login.Interval) * time.Second
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.deviceLogins.finish(login.ID, DeviceLoginExpired, nil, api_types.ErrDeviceCodeExpired)
+			return
+		case <-time.After(interval):
+		}
+
+		token, err := deviceClient.PollDeviceToken(ctx, instanceURL, deviceCode)
+		switch {
+		case err == nil:
+			sessionCtx, sessionCancel := context.WithTimeout(context.Background(), deviceLoginSessionTimeout)
+			tokens, err := s.createSession(sessionCtx, apiClient, token, "", schoolID, instanceURL, apiType, device)
+			if err != nil {
+				logger.Error("Failed to create session after device login", "login_id", login.ID, "error", err)
+				sessionCancel()
+				s.deviceLogins.finish(login.ID, DeviceLoginFailed, nil, err)
+				return
+			}
+			sessionCancel()
+			s.deviceLogins.finish(login.ID, DeviceLoginCompleted, tokens, nil)
+			return
+		case errors.Is(err, api_types.ErrDeviceAuthorizationPending):
+			continue
+		case errors.Is(err, api_types.ErrDeviceSlowDown):
+			interval += deviceLoginSlowDownStep
+			continue
+		case errors.Is(err, api_types.ErrDeviceCodeExpired), errors.Is(err, context.DeadlineExceeded):
+			s.deviceLogins.finish(login.ID, DeviceLoginExpired, nil, api_types.ErrDeviceCodeExpired)
+			return
+		default:
+			logger.Error("Device login polling failed", "login_id", login.ID, "error", err)
+			s.deviceLogins.finish(login.ID, DeviceLoginFailed, nil, err)
+			return
+		}
+	}
+}
+
+func newDeviceLoginID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,44 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/domain/auth"
+)
+
+type deviceFlowClient struct {
+	api_types.APIClientInterface
+	expiresIn int
+}
+
+func (c deviceFlowClient) StartDeviceAuthorization(ctx context.Context, instanceURL string) (*api_types.DeviceAuthorization, error) {
+	return &api_types.DeviceAuthorization{DeviceCode: "device", UserCode: "ABCD-EFGH", VerificationURI: instanceURL + "/device", ExpiresIn: c.expiresIn, Interval: 1}, nil
+}
+
+func (c deviceFlowClient) PollDeviceToken(ctx context.Context, instanceURL, deviceCode string) (*api_types.OAuthToken, error) {
+	return &api_types.OAuthToken{AccessToken: "mock_token_for_alice"}, nil
+}
+
+func TestService_DeviceLoginWithoutExpiresInUsesDefaultTTL(t *testing.T) {
+	service := newTestServiceWithRegistry(t, mockRegistry(t, func(client api_types.APIClientInterface) api_types.APIClientInterface {
+		return deviceFlowClient{APIClientInterface: client}
+	}), nil)
+	ctx := context.Background()
+
+	login, err := service.StartDeviceLogin(ctx, 1, "https://sgo.rso23.ru", string(api_types.DevMockAPI), auth.DeviceInfo{})
+	require.NoError(t, err)
+	assert.True(t, login.ExpiresAt.After(time.Now().Add(5*time.Minute)), login.ExpiresAt)
+
+	login, err = service.WaitDeviceLogin(ctx, login.ID, 5*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, auth.DeviceLoginCompleted, login.Status, login.ErrorCode)
+
+	claims, err := service.ValidateToken(ctx, login.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "alice_1001_sgo.rso23.ru", claims.UserID)
+}
@@ -0,0 +1,15 @@
+package auth
+
+import "netschool-proxy/api/api/internal/api_types"
+
+
+func init() {
+	api_types.RegisterErrorCode(ErrSessionNotFound, api_types.ErrCodeSessionExpired)
+	api_types.RegisterErrorCode(ErrSessionExpired, api_types.ErrCodeSessionExpired)
+	api_types.RegisterErrorCode(ErrRefreshNotSupported, api_types.ErrCodeSessionExpired)
+	api_types.RegisterErrorCode(ErrInvalidRefreshToken, api_types.ErrCodeUnauthorized)
+	api_types.RegisterErrorCode(ErrRefreshTokenReused, api_types.ErrCodeUnauthorized)
+	api_types.RegisterErrorCode(ErrTokenRevoked, api_types.ErrCodeUnauthorized)
+	api_types.RegisterErrorCode(ErrStudentNotLinked, api_types.ErrCodeDataAccessDenied)
+	api_types.RegisterErrorCode(ErrDeviceLoginNotFound, api_types.ErrCodeNotFound)
+}
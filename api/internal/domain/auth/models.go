@@ -1,6 +1,10 @@
 package auth
 
-import "time"
+import (
+	"errors"
+	"strconv"
+	"time"
+)
 
 
 type ProxyToken struct {
@@ -13,10 +17,12 @@ type ProxyToken struct {
 
 type NetSchoolSession struct {
 	ID                  int       `json:"id" gorm:"column:id"`
+	SessionID           string    `json:"session_id" gorm:"column:session_id"`
 	UserID              string    `json:"user_id" gorm:"column:user_id"`
 	NetSchoolAccessToken string   `json:"-" gorm:"column:access_token"` 
 	RefreshToken        string    `json:"-" gorm:"column:refresh_token"` 
 	ExpiresAt           time.Time `json:"-" gorm:"column:expires_at"` 
+	TokenExpiresAt      time.Time `json:"-" gorm:"column:token_expires_at"`
 	NetSchoolURL        string    `json:"-" gorm:"column:netschool_url"` 
 	SchoolID            int       `json:"-" gorm:"column:school_id"`
 	StudentID           string    `json:"-" gorm:"column:student_id"`
@@ -24,9 +30,90 @@ type NetSchoolSession struct {
 	APIType             string    `json:"-" gorm:"column:api_type"` 
 	CreatedAt           time.Time `json:"-" gorm:"column:created_at"`
 	UpdatedAt           time.Time `json:"-" gorm:"column:updated_at"`
+	LastUsedAt          time.Time `json:"-" gorm:"column:last_used_at"`
+	DeviceName          string    `json:"-" gorm:"column:device_name"`
+	UserAgent           string    `json:"-" gorm:"column:user_agent"`
+	Students            []SessionStudent `json:"-" gorm:"foreignKey:SessionID"`
 }
 
 
 func (NetSchoolSession) TableName() string {
 	return "sessions"
-}
\ No newline at end of file
+}
+
+
+
+func (s *NetSchoolSession) SchoolYearID() int {
+	yearID, err := strconv.Atoi(s.YearID)
+	if err != nil {
+		return 0
+	}
+	return yearID
+}
+
+
+type DeviceInfo struct {
+	Name      string
+	UserAgent string
+}
+
+
+type SessionInfo struct {
+	ID         string    `json:"id"`
+	DeviceName string    `json:"device_name,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	APIType    string    `json:"api_type"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current"`
+}
+
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionExpired  = errors.New("session expired")
+)
+
+
+var ErrStudentNotLinked = errors.New("student is not linked to this session")
+
+
+var ErrNetSchoolIdentityMissing = errors.New("NetSchool returned no user id")
+
+
+type SessionStudent struct {
+	ID        int    `json:"-" gorm:"column:id"`
+	SessionID int    `json:"-" gorm:"column:session_id"`
+	StudentID string `json:"id" gorm:"column:student_id"`
+	Name      string `json:"name" gorm:"column:name"`
+	ClassID   string `json:"class_id,omitempty" gorm:"column:class_id"`
+	ClassName string `json:"class_name,omitempty" gorm:"column:class_name"`
+}
+
+
+func (SessionStudent) TableName() string {
+	return "session_students"
+}
+
+
+
+func (s *NetSchoolSession) ResolveStudentID(requested string) (string, error) {
+	if requested == "" {
+		return s.StudentID, nil
+	}
+
+	
+	if len(s.Students) == 0 {
+		if requested == s.StudentID {
+			return requested, nil
+		}
+		return "", ErrStudentNotLinked
+	}
+
+	for _, student := range s.Students {
+		if student.StudentID == requested {
+			return requested, nil
+		}
+	}
+	return "", ErrStudentNotLinked
+}
@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/pkg/logger"
+)
+
+
+var ErrRefreshNotSupported = errors.New("session cannot be refreshed")
+
+const sessionRefreshTimeout = 30 * time.Second
+
+
+func (c SessionConfig) expiries(token *api_types.OAuthToken, now time.Time) (time.Time, time.Time) {
+	tokenExpiresAt := now.Add(c.TokenTTL)
+	if token.ExpiresIn > 0 {
+		tokenExpiresAt = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+
+	
+	expiresAt := tokenExpiresAt
+	if token.RefreshToken != "" && c.SessionTTL > 0 {
+		expiresAt = now.Add(c.SessionTTL)
+	}
+
+	return tokenExpiresAt, expiresAt
+}
+
+
+
+type SessionRefresher struct {
+	sessionRepo      SessionRepository
+	apiClientFactory *api_types.APIClientFactory
+	config           api_types.APIConfig
+	sessionConfig    SessionConfig
+
+	mu       sync.Mutex
+	inFlight map[int]*refreshCall
+}
+
+
+type refreshCall struct {
+	done    chan struct{}
+	session NetSchoolSession
+	err     error
+}
+
+
+func NewSessionRefresher(sessionRepo SessionRepository, apiClientFactory *api_types.APIClientFactory, config api_types.APIConfig, sessionConfig SessionConfig) *SessionRefresher {
+	return &SessionRefresher{
+		sessionRepo:      sessionRepo,
+		apiClientFactory: apiClientFactory,
+		config:           config,
+		sessionConfig:    sessionConfig,
+		inFlight:         make(map[int]*refreshCall),
+	}
+}
+
+
+func (r *SessionRefresher) NeedsRefresh(session *NetSchoolSession) bool {
+	if r == nil || session == nil || session.RefreshToken == "" {
+		return false
+	}
+	return session.TokenExpiresAt.Before(time.Now().Add(r.sessionConfig.RefreshBefore))
+}
+
+
+
+
+func CallWithRefresh[T any](ctx context.Context, r *SessionRefresher, session *NetSchoolSession, call func() (T, error)) (T, error) {
+	result, err := call()
+	if err == nil || !errors.Is(err, api_types.ErrUnauthorized) || r == nil || session == nil || session.RefreshToken == "" {
+		return result, err
+	}
+
+	if refreshErr := r.RefreshNow(ctx, session); refreshErr != nil {
+		logger.Error("Failed to refresh NetSchool session", "user_id", session.UserID, "error", refreshErr)
+		return result, err
+	}
+	return call()
+}
+
+
+
+func (r *SessionRefresher) RefreshNow(ctx context.Context, session *NetSchoolSession) error {
+	if r == nil || session == nil {
+		return ErrRefreshNotSupported
+	}
+
+	call := r.start(session)
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if call.err != nil {
+		return call.err
+	}
+
+	session.NetSchoolAccessToken = call.session.NetSchoolAccessToken
+	session.RefreshToken = call.session.RefreshToken
+	session.TokenExpiresAt = call.session.TokenExpiresAt
+	session.ExpiresAt = call.session.ExpiresAt
+	return nil
+}
+
+
+func (r *SessionRefresher) RefreshAsync(session *NetSchoolSession) {
+	if r == nil || session == nil {
+		return
+	}
+	r.start(session)
+}
+
+
+
+func (r *SessionRefresher) start(session *NetSchoolSession) *refreshCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if call, running := r.inFlight[session.ID]; running {
+		return call
+	}
+
+	call := &refreshCall{done: make(chan struct{}), session: *session}
+	r.inFlight[session.ID] = call
+
+	go func() {
+		defer func() {
+			r.mu.Lock()
+			delete(r.inFlight, call.session.ID)
+			r.mu.Unlock()
+			close(call.done)
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), sessionRefreshTimeout)
+		defer cancel()
+
+		if call.err = r.Refresh(ctx, &call.session); call.err != nil {
+			logger.Error("Failed to refresh NetSchool session", "user_id", call.session.UserID, "error", call.err)
+		}
+	}()
+	return call
+}
+
+
+func (r *SessionRefresher) Refresh(ctx context.Context, session *NetSchoolSession) error {
+	if session.RefreshToken == "" {
+		return ErrRefreshNotSupported
+	}
+
+	apiMode := api_types.APIMode(session.APIType)
+	if !r.apiClientFactory.Supports(apiMode, api_types.CapabilityTokenRefresh) {
+		return ErrRefreshNotSupported
+	}
+	clientConfig := r.config
+	clientConfig.Mode = apiMode
+
+	apiClient, err := r.apiClientFactory.NewAPIClient(apiMode, clientConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	tokenRefresher, ok := api_types.UnwrapClient(apiClient).(api_types.TokenRefresher)
+	if !ok {
+		return ErrRefreshNotSupported
+	}
+
+	token, err := tokenRefresher.RefreshAccessToken(ctx, session.NetSchoolURL, session.RefreshToken)
+	if err != nil {
+		return fmt.Errorf("failed to refresh access token: %w", err)
+	}
+
+	
+	if token.RefreshToken == "" {
+		token.RefreshToken = session.RefreshToken
+	}
+
+	tokenExpiresAt, expiresAt := r.sessionConfig.expiries(token, time.Now())
+	if err := r.sessionRepo.UpdateTokens(ctx, session.ID, token.AccessToken, token.RefreshToken, tokenExpiresAt, expiresAt); err != nil {
+		return fmt.Errorf("failed to save refreshed tokens: %w", err)
+	}
+
+	session.NetSchoolAccessToken = token.AccessToken
+	session.RefreshToken = token.RefreshToken
+	session.TokenExpiresAt = tokenExpiresAt
+	session.ExpiresAt = expiresAt
+
+	return nil
+}
+
+
+
+func (r *SessionRefresher) StartRefresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	r.refreshExpiring(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			r.refreshExpiring(ctx)
+		case <-ctx.Done():
+			logger.Info("Session refresher shutting down")
+			return
+		}
+	}
+}
+
+
+func (r *SessionRefresher) refreshExpiring(ctx context.Context) {
+	listCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	sessions, err := r.sessionRepo.ListExpiring(listCtx, time.Now().Add(r.sessionConfig.RefreshBefore))
+	if err != nil {
+		logger.Error("Failed to list expiring sessions", "error", err)
+		return
+	}
+
+	for _, session := range sessions {
+		r.RefreshAsync(session)
+	}
+}
@@ -0,0 +1,205 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/domain/auth"
+)
+
+type refreshingClient struct {
+	api_types.APIClientInterface
+	calls   *int32
+	release chan struct{}
+	err     error
+}
+
+func (c refreshingClient) RefreshAccessToken(ctx context.Context, instanceURL, refreshToken string) (*api_types.OAuthToken, error) {
+	atomic.AddInt32(c.calls, 1)
+	if c.release != nil {
+		<-c.release
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &api_types.OAuthToken{AccessToken: "refreshed-access", RefreshToken: "refreshed-refresh", ExpiresIn: 3600}, nil
+}
+
+type refresherFixture struct {
+	refresher *auth.SessionRefresher
+	service   *testService
+	session   *auth.NetSchoolSession
+}
+
+func newRefresherFixture(t *testing.T, wrap func(api_types.APIClientInterface) api_types.APIClientInterface) *refresherFixture {
+	t.Helper()
+	registry := api_types.DefaultRegistry
+	if wrap != nil {
+		registry = mockRegistry(t, wrap)
+		provider, _ := registry.Lookup(api_types.DevMockAPI)
+		provider.Capabilities = append(append([]api_types.Capability{}, provider.Capabilities...), api_types.CapabilityTokenRefresh)
+	}
+	service := newTestServiceWithRegistry(t, registry, nil)
+
+	factory, err := api_types.NewAPIClientFactory(registry, api_types.TransportConfig{})
+	require.NoError(t, err)
+	refresher := auth.NewSessionRefresher(service.sessions, factory, api_types.APIConfig{Mode: api_types.DevMockAPI}, auth.SessionConfig{
+		TokenTTL:      time.Hour,
+		SessionTTL:    24 * time.Hour,
+		RefreshBefore: 5 * time.Minute,
+	})
+
+	session := &auth.NetSchoolSession{
+		SessionID:            "session-1",
+		UserID:               "alice_1001_sgo.rso23.ru",
+		NetSchoolAccessToken: "expired-access",
+		RefreshToken:         "refresh",
+		TokenExpiresAt:       time.Now().Add(time.Minute),
+		ExpiresAt:            time.Now().Add(time.Hour),
+		NetSchoolURL:         "https://sgo.rso23.ru",
+		APIType:              string(api_types.DevMockAPI),
+	}
+	require.NoError(t, service.sessions.Create(context.Background(), session))
+	return &refresherFixture{refresher: refresher, service: service, session: session}
+}
+
+func (f *refresherFixture) stored(t *testing.T) *auth.NetSchoolSession {
+	t.Helper()
+	session, err := f.service.sessions.GetBySessionID(context.Background(), f.session.SessionID)
+	require.NoError(t, err)
+	require.NotNil(t, session)
+	return session
+}
+
+func TestSessionRefresher_NeedsRefresh(t *testing.T) {
+	fixture := newRefresherFixture(t, nil)
+	session := *fixture.session
+
+	assert.True(t, fixture.refresher.NeedsRefresh(&session))
+
+	session.TokenExpiresAt = time.Now().Add(time.Hour)
+	assert.False(t, fixture.refresher.NeedsRefresh(&session))
+
+	session.TokenExpiresAt = time.Now().Add(-time.Minute)
+	session.RefreshToken = ""
+	assert.False(t, fixture.refresher.NeedsRefresh(&session))
+
+	var nilRefresher *auth.SessionRefresher
+	assert.False(t, nilRefresher.NeedsRefresh(fixture.session))
+}
+
+func TestSessionRefresher_RefreshAsyncDeduplicates(t *testing.T) {
+	calls := new(int32)
+	release := make(chan struct{})
+	fixture := newRefresherFixture(t, func(client api_types.APIClientInterface) api_types.APIClientInterface {
+		return refreshingClient{APIClientInterface: client, calls: calls, release: release}
+	})
+
+	for i := 0; i < 5; i++ {
+		fixture.refresher.RefreshAsync(fixture.session)
+	}
+	require.Eventually(t, func() bool { return atomic.LoadInt32(calls) == 1 }, time.Second, 5*time.Millisecond)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	session := *fixture.session
+	assert.ErrorIs(t, fixture.refresher.RefreshNow(canceled, &session), context.Canceled)
+	assert.Equal(t, "expired-access", session.NetSchoolAccessToken)
+
+	close(release)
+	require.Eventually(t, func() bool {
+		return fixture.stored(t).NetSchoolAccessToken == "refreshed-access"
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestSessionRefresher_RefreshFailures(t *testing.T) {
+	t.Run("no refresh token", func(t *testing.T) {
+		fixture := newRefresherFixture(t, nil)
+		session := *fixture.session
+		session.RefreshToken = ""
+
+		assert.ErrorIs(t, fixture.refresher.Refresh(context.Background(), &session), auth.ErrRefreshNotSupported)
+	})
+
+	t.Run("provider without refresh", func(t *testing.T) {
+		fixture := newRefresherFixture(t, nil)
+
+		assert.ErrorIs(t, fixture.refresher.RefreshNow(context.Background(), fixture.session), auth.ErrRefreshNotSupported)
+	})
+
+	t.Run("client without refresh", func(t *testing.T) {
+		fixture := newRefresherFixture(t, func(client api_types.APIClientInterface) api_types.APIClientInterface {
+			return struct{ api_types.APIClientInterface }{client}
+		})
+
+		assert.ErrorIs(t, fixture.refresher.RefreshNow(context.Background(), fixture.session), auth.ErrRefreshNotSupported)
+	})
+
+	t.Run("rejected by NetSchool", func(t *testing.T) {
+		fixture := newRefresherFixture(t, func(client api_types.APIClientInterface) api_types.APIClientInterface {
+			return refreshingClient{APIClientInterface: client, calls: new(int32), err: api_types.ErrUnauthorized}
+		})
+
+		err := fixture.refresher.RefreshNow(context.Background(), fixture.session)
+		assert.ErrorIs(t, err, api_types.ErrUnauthorized)
+		assert.Equal(t, "expired-access", fixture.session.NetSchoolAccessToken)
+		assert.Equal(t, "expired-access", fixture.stored(t).NetSchoolAccessToken)
+	})
+}
+
+func TestCallWithRefresh_RetriesOnceAfterUnauthorized(t *testing.T) {
+	calls := new(int32)
+	fixture := newRefresherFixture(t, func(client api_types.APIClientInterface) api_types.APIClientInterface {
+		return refreshingClient{APIClientInterface: client, calls: calls}
+	})
+
+	var tokens []string
+	result, err := auth.CallWithRefresh(context.Background(), fixture.refresher, fixture.session, func() (string, error) {
+		tokens = append(tokens, fixture.session.NetSchoolAccessToken)
+		if fixture.session.NetSchoolAccessToken == "expired-access" {
+			return "", api_types.ErrUnauthorized
+		}
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result)
+	assert.Equal(t, []string{"expired-access", "refreshed-access"}, tokens)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestCallWithRefresh_ReturnsOriginalErrorWhenRefreshFails(t *testing.T) {
+	fixture := newRefresherFixture(t, func(client api_types.APIClientInterface) api_types.APIClientInterface {
+		return refreshingClient{APIClientInterface: client, calls: new(int32), err: errors.New("refresh token revoked")}
+	})
+
+	attempts := 0
+	_, err := auth.CallWithRefresh(context.Background(), fixture.refresher, fixture.session, func() (string, error) {
+		attempts++
+		return "", api_types.ErrUnauthorized
+	})
+	assert.ErrorIs(t, err, api_types.ErrUnauthorized)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestCallWithRefresh_PassesThroughOtherErrors(t *testing.T) {
+	calls := new(int32)
+	fixture := newRefresherFixture(t, func(client api_types.APIClientInterface) api_types.APIClientInterface {
+		return refreshingClient{APIClientInterface: client, calls: calls}
+	})
+
+	attempts := 0
+	_, err := auth.CallWithRefresh(context.Background(), fixture.refresher, fixture.session, func() (string, error) {
+		attempts++
+		return "", api_types.ErrNotFound
+	})
+	assert.ErrorIs(t, err, api_types.ErrNotFound)
+	assert.Equal(t, 1, attempts)
+	assert.Zero(t, atomic.LoadInt32(calls))
+}
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"netschool-proxy/api/api/internal/pkg/logger"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session revoked")
+)
+
+
+
+type ProxyRefreshToken struct {
+	ID        int        `gorm:"column:id"`
+	TokenHash string     `gorm:"column:token_hash"`
+	FamilyID  string     `gorm:"column:family_id"`
+	SessionID string     `gorm:"column:session_id"`
+	UserID    string     `gorm:"column:user_id"`
+	CreatedAt time.Time  `gorm:"column:created_at"`
+	ExpiresAt time.Time  `gorm:"column:expires_at"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	RevokedAt *time.Time `gorm:"column:revoked_at"`
+}
+
+
+func (ProxyRefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *ProxyRefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*ProxyRefreshToken, error)
+	
+	MarkUsed(ctx context.Context, id int, usedAt time.Time) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string, revokedAt time.Time) error
+	RevokeBySessionID(ctx context.Context, userID, sessionID string, revokedAt time.Time) error
+	RevokeByUserID(ctx context.Context, userID string, revokedAt time.Time) error
+	ReassignSession(ctx context.Context, sessionID, userID string) error
+	CleanupExpired(ctx context.Context) error
+}
+
+
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+
+
+
+func (s *Service) RefreshTokens(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	now := time.Now()
+
+	stored, err := s.refreshTokenRepo.GetByHash(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load refresh token: %w", err)
+	}
+	if stored == nil || stored.RevokedAt != nil || !stored.ExpiresAt.After(now) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if stored.UsedAt != nil {
+		s.revokeRefreshFamily(ctx, stored, now)
+		return nil, ErrRefreshTokenReused
+	}
+
+	
+	marked, err := s.refreshTokenRepo.MarkUsed(ctx, stored.ID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !marked {
+		s.revokeRefreshFamily(ctx, stored, now)
+		return nil, ErrRefreshTokenReused
+	}
+
+	session, err := s.sessionRepo.GetBySessionID(ctx, stored.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil || session.UserID != stored.UserID {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return s.issueTokens(ctx, session, stored.FamilyID)
+}
+
+
+
+func (s *Service) revokeRefreshFamily(ctx context.Context, stored *ProxyRefreshToken, now time.Time) {
+	logger.Warn("Refresh token reuse detected, revoking token family", "user_id", stored.UserID, "session_id", stored.SessionID)
+
+	if err := s.refreshTokenRepo.RevokeFamily(ctx, stored.FamilyID, now); err != nil {
+		logger.Error("Failed to revoke refresh token family", "family_id", stored.FamilyID, "error", err)
+	}
+	if err := s.revocations.RevokeSession(ctx, stored.SessionID); err != nil {
+		logger.Error("Failed to revoke access tokens after refresh token reuse", "session_id", stored.SessionID, "error", err)
+	}
+	if _, err := s.sessionRepo.DeleteBySessionID(ctx, stored.UserID, stored.SessionID); err != nil {
+		logger.Error("Failed to delete session after refresh token reuse", "session_id", stored.SessionID, "error", err)
+	}
+}
+
+
+
+func (s *Service) issueTokens(ctx context.Context, session *NetSchoolSession, familyID string) (*TokenPair, error) {
+	accessToken, err := s.jwtService.GenerateToken(session.UserID, session.SessionID, session.SchoolID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate proxy token: %w", err)
+	}
+
+	if familyID == "" {
+		if familyID, err = newSessionID(); err != nil {
+			return nil, fmt.Errorf("failed to generate token family: %w", err)
+		}
+	}
+
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	now := time.Now()
+	stored := &ProxyRefreshToken{
+		TokenHash: hashRefreshToken(refreshToken),
+		FamilyID:  familyID,
+		SessionID: session.SessionID,
+		UserID:    session.UserID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.sessionConfig.RefreshTokenTTL),
+	}
+	if err := s.refreshTokenRepo.Create(ctx, stored); err != nil {
+		return nil, fmt.Errorf("failed to save refresh token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(s.jwtService.ExpiresIn().Seconds()),
+	}, nil
+}
+
+func newRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,103 @@
+package auth_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/domain/auth"
+)
+
+func TestService_RefreshRotatesToken(t *testing.T) {
+	service := newTestService(t)
+	ctx := context.Background()
+	first, _ := service.login(t, "alice")
+
+	second, err := service.RefreshTokens(ctx, first.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+	_, err = service.ValidateToken(ctx, second.AccessToken)
+	require.NoError(t, err)
+
+	third, err := service.RefreshTokens(ctx, second.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, second.RefreshToken, third.RefreshToken)
+}
+
+func TestService_RefreshReuseRevokesFamily(t *testing.T) {
+	service := newTestService(t)
+	ctx := context.Background()
+	first, _ := service.login(t, "alice")
+	other, _ := service.login(t, "alice")
+
+	second, err := service.RefreshTokens(ctx, first.RefreshToken)
+	require.NoError(t, err)
+
+	_, err = service.RefreshTokens(ctx, first.RefreshToken)
+	assert.ErrorIs(t, err, auth.ErrRefreshTokenReused)
+
+	_, err = service.RefreshTokens(ctx, second.RefreshToken)
+	assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+	_, err = service.ValidateToken(ctx, second.AccessToken)
+	assert.Error(t, err)
+
+	_, err = service.ValidateToken(ctx, other.AccessToken)
+	assert.NoError(t, err)
+	_, err = service.RefreshTokens(ctx, other.RefreshToken)
+	assert.NoError(t, err)
+}
+
+type barrierRefreshTokens struct {
+	auth.RefreshTokenRepository
+	loaded sync.WaitGroup
+}
+
+func (r *barrierRefreshTokens) GetByHash(ctx context.Context, tokenHash string) (*auth.ProxyRefreshToken, error) {
+	token, err := r.RefreshTokenRepository.GetByHash(ctx, tokenHash)
+	r.loaded.Done()
+	r.loaded.Wait()
+	return token, err
+}
+
+func TestService_ConcurrentRefreshSucceedsOnce(t *testing.T) {
+	barrier := &barrierRefreshTokens{}
+	service := newTestServiceWithRefreshTokens(t, func(repo auth.RefreshTokenRepository) auth.RefreshTokenRepository {
+		barrier.RefreshTokenRepository = repo
+		return barrier
+	})
+	tokens, _ := service.login(t, "alice")
+
+	const attempts = 2
+	barrier.loaded.Add(attempts)
+	results := make(chan error, attempts)
+	for i := 0; i < attempts; i++ {
+		go func() {
+			_, err := service.RefreshTokens(context.Background(), tokens.RefreshToken)
+			results <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < attempts; i++ {
+		err := <-results
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, auth.ErrRefreshTokenReused)
+	}
+	assert.Equal(t, 1, succeeded)
+}
+
+func TestService_RefreshRejectsExpiredToken(t *testing.T) {
+	service := newTestService(t)
+	tokens, _ := service.login(t, "alice")
+
+	require.NoError(t, service.db.Model(&auth.ProxyRefreshToken{}).Where("1 = 1").Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+	_, err := service.RefreshTokens(context.Background(), tokens.RefreshToken)
+	assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+}
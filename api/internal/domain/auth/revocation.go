@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"netschool-proxy/api/api/internal/pkg/security"
+)
+
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+const (
+	revokedTokenPrefix   = "revoked:jti:"
+	revokedSessionPrefix = "revoked:session:"
+	revokedUserPrefix    = "revoked:user:"
+)
+
+
+
+
+type RevocationCache interface {
+	Get(ctx context.Context, key string, target interface{}) (bool, error)
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+}
+
+
+
+type RevocationStore struct {
+	cache       RevocationCache
+	maxTokenTTL time.Duration
+}
+
+func NewRevocationStore(cache RevocationCache, maxTokenTTL time.Duration) *RevocationStore {
+	return &RevocationStore{
+		cache:       cache,
+		maxTokenTTL: maxTokenTTL,
+	}
+}
+
+
+func (r *RevocationStore) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	if tokenID == "" {
+		return nil
+	}
+	ttl := time.Until(expiresAt)
+	if expiresAt.IsZero() || ttl > r.maxTokenTTL {
+		ttl = r.maxTokenTTL
+	}
+	if ttl <= 0 {
+		return nil
+	}
+	return r.cache.Set(ctx, revokedTokenPrefix+tokenID, true, ttl)
+}
+
+
+func (r *RevocationStore) RevokeSession(ctx context.Context, sessionID string) error {
+	return r.cache.Set(ctx, revokedSessionPrefix+sessionID, true, r.maxTokenTTL)
+}
+
+
+
+func (r *RevocationStore) RevokeUser(ctx context.Context, userID string, at time.Time) error {
+	cutoff := at.Truncate(time.Second).Add(time.Second)
+	return r.cache.Set(ctx, revokedUserPrefix+userID, cutoff.Unix(), r.maxTokenTTL)
+}
+
+
+func (r *RevocationStore) IsRevoked(ctx context.Context, claims *security.Claims) (bool, error) {
+	var revoked bool
+	if claims.ID != "" {
+		found, err := r.cache.Get(ctx, revokedTokenPrefix+claims.ID, &revoked)
+		if err != nil {
+			return false, err
+		}
+		if found && revoked {
+			return true, nil
+		}
+	}
+
+	found, err := r.cache.Get(ctx, revokedSessionPrefix+claims.SessionID, &revoked)
+	if err != nil {
+		return false, err
+	}
+	if found && revoked {
+		return true, nil
+	}
+
+	var revokedAt int64
+	found, err = r.cache.Get(ctx, revokedUserPrefix+claims.UserID, &revokedAt)
+	if err != nil {
+		return false, err
+	}
+	
+	if found && (claims.IssuedAt == nil || claims.IssuedAt.Unix() < revokedAt) {
+		return true, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,90 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/domain/auth"
+	infraCache "netschool-proxy/api/api/internal/infrastructure/cache"
+	"netschool-proxy/api/api/internal/pkg/security"
+)
+
+func claimsAt(tokenID, userID, sessionID string, issuedAt time.Time) *security.Claims {
+	return &security.Claims{
+		UserID:    userID,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:       tokenID,
+			IssuedAt: jwt.NewNumericDate(issuedAt),
+		},
+	}
+}
+
+func assertRevoked(t *testing.T, store *auth.RevocationStore, claims *security.Claims, want bool) {
+	t.Helper()
+	revoked, err := store.IsRevoked(context.Background(), claims)
+	require.NoError(t, err)
+	assert.Equal(t, want, revoked, "jti=%s session=%s", claims.ID, claims.SessionID)
+}
+
+func TestRevocationStore_TokenAndSession(t *testing.T) {
+	store := auth.NewRevocationStore(infraCache.NewMemoryCacheService(100), time.Hour)
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, store.RevokeToken(ctx, "jti-1", now.Add(time.Minute)))
+	assertRevoked(t, store, claimsAt("jti-1", "alice", "s1", now), true)
+	assertRevoked(t, store, claimsAt("jti-2", "alice", "s1", now), false)
+
+	require.NoError(t, store.RevokeSession(ctx, "s1"))
+	assertRevoked(t, store, claimsAt("jti-2", "alice", "s1", now), true)
+	assertRevoked(t, store, claimsAt("jti-3", "alice", "s2", now), false)
+
+	require.NoError(t, store.RevokeToken(ctx, "jti-expired", now.Add(-time.Minute)))
+	assertRevoked(t, store, claimsAt("jti-expired", "alice", "s2", now), false)
+}
+
+func TestRevocationStore_UserCutoff(t *testing.T) {
+	store := auth.NewRevocationStore(infraCache.NewMemoryCacheService(100), time.Hour)
+	ctx := context.Background()
+	revokedAt := time.Now()
+
+	require.NoError(t, store.RevokeUser(ctx, "alice", revokedAt))
+	assertRevoked(t, store, claimsAt("before", "alice", "s1", revokedAt.Add(-time.Second)), true)
+	assertRevoked(t, store, claimsAt("same-instant", "alice", "s1", revokedAt), true)
+	assertRevoked(t, store, claimsAt("same-second", "alice", "s1", revokedAt.Truncate(time.Second)), true)
+	assertRevoked(t, store, claimsAt("next-second", "alice", "s1", revokedAt.Truncate(time.Second).Add(time.Second)), false)
+	assertRevoked(t, store, claimsAt("other-user", "bob", "s2", revokedAt.Add(-time.Second)), false)
+}
+
+func TestRevocationStore_SameSecondToken(t *testing.T) {
+	service := newTestService(t)
+	ctx := context.Background()
+	tokens, claims := service.login(t, "alice")
+
+	require.NoError(t, service.LogoutAll(ctx, claims.UserID))
+	_, err := service.ValidateToken(ctx, tokens.AccessToken)
+	assert.ErrorIs(t, err, auth.ErrTokenRevoked)
+}
+
+func TestRevocationStore_EntriesExpire(t *testing.T) {
+	store := auth.NewRevocationStore(infraCache.NewMemoryCacheService(100), 50*time.Millisecond)
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, store.RevokeToken(ctx, "jti-1", now.Add(time.Hour)))
+	require.NoError(t, store.RevokeSession(ctx, "s1"))
+	require.NoError(t, store.RevokeUser(ctx, "alice", now))
+	assertRevoked(t, store, claimsAt("jti-1", "bob", "s2", now), true)
+	assertRevoked(t, store, claimsAt("jti-2", "bob", "s1", now), true)
+	assertRevoked(t, store, claimsAt("jti-3", "alice", "s3", now.Add(-time.Second)), true)
+
+	time.Sleep(80 * time.Millisecond)
+	assertRevoked(t, store, claimsAt("jti-1", "bob", "s2", now), false)
+	assertRevoked(t, store, claimsAt("jti-2", "bob", "s1", now), false)
+	assertRevoked(t, store, claimsAt("jti-3", "alice", "s3", now.Add(-time.Second)), false)
+}
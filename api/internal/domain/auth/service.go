@@ -2,193 +2,445 @@ package auth
 
 import (
 	"context"
-	"errors"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/pkg/logger"
 	"netschool-proxy/api/api/internal/pkg/security"
 )
 
 type Service struct {
 	sessionRepo    SessionRepository
+	refreshTokenRepo RefreshTokenRepository
+	revocations    *RevocationStore
 	apiClientFactory *api_types.APIClientFactory
 	config         api_types.APIConfig
 	jwtService     *security.JWTService
+	sessionConfig  SessionConfig
+	refresher      *SessionRefresher
+	deviceLogins   *deviceLoginStore
+	browserLogin   BrowserLogin
+	browserConfig  BrowserLoginConfig
+	cachePurger    SessionCachePurger
+	instances      *api_types.InstanceAllowlist
 }
 
 type SessionRepository interface {
 	Create(ctx context.Context, session *NetSchoolSession) error
-	GetByUserID(ctx context.Context, userID string) (*NetSchoolSession, error)
-	Delete(ctx context.Context, userID string) error
+	GetBySessionID(ctx context.Context, sessionID string) (*NetSchoolSession, error)
+	ListByUserID(ctx context.Context, userID string) ([]*NetSchoolSession, error)
+	UpdateTokens(ctx context.Context, id int, accessToken, refreshToken string, tokenExpiresAt, expiresAt time.Time) error
+	Touch(ctx context.Context, id int, lastUsedAt time.Time) error
+	UpdateUserID(ctx context.Context, id int, userID string) error
+	ListExpiring(ctx context.Context, before time.Time) ([]*NetSchoolSession, error)
+	ListActive(ctx context.Context, usedSince time.Time) ([]*NetSchoolSession, error)
+	DeleteBySessionID(ctx context.Context, userID, sessionID string) (bool, error)
+	DeleteByUserID(ctx context.Context, userID string) error
 	CleanupExpired(ctx context.Context) error
 }
 
-func NewService(sessionRepo SessionRepository, apiClientFactory *api_types.APIClientFactory, config api_types.APIConfig, jwtService *security.JWTService) *Service {
+
+
+type SessionCachePurger interface {
+	PurgeSession(ctx context.Context, userID, sessionID string) error
+	PurgeUser(ctx context.Context, userID string) error
+}
+
+
+type SessionConfig struct {
+	TokenTTL      time.Duration 
+	SessionTTL    time.Duration 
+	RefreshBefore time.Duration 
+	RefreshTokenTTL time.Duration 
+}
+
+
+const sessionTouchInterval = time.Minute
+
+func NewService(sessionRepo SessionRepository, refreshTokenRepo RefreshTokenRepository, revocations *RevocationStore, apiClientFactory *api_types.APIClientFactory, instances *api_types.InstanceAllowlist, config api_types.APIConfig, jwtService *security.JWTService, sessionConfig SessionConfig) *Service {
 	return &Service{
 		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		revocations:      revocations,
 		apiClientFactory: apiClientFactory,
 		config:           config,
 		jwtService:       jwtService,
+		sessionConfig:    sessionConfig,
+		refresher:        NewSessionRefresher(sessionRepo, apiClientFactory, config, sessionConfig),
+		deviceLogins:     newDeviceLoginStore(),
+		instances:        instances,
 	}
 }
 
-func (s *Service) Login(ctx context.Context, username, password string, schoolID int, instanceURL string) (string, error) {
+
+func (s *Service) Refresher() *SessionRefresher {
+	return s.refresher
+}
+
+
+func (s *Service) Providers() *api_types.ProviderRegistry {
+	return s.apiClientFactory.Registry()
+}
+
+func (s *Service) Login(ctx context.Context, username, password string, schoolID int, instanceURL string) (*TokenPair, error) {
 	
-	return s.LoginWithAPIType(ctx, username, password, schoolID, instanceURL, string(s.config.Mode))
+	return s.LoginWithAPIType(ctx, username, password, schoolID, instanceURL, string(s.config.Mode), DeviceInfo{})
 }
 
-func (s *Service) LoginWithAPIType(ctx context.Context, username, password string, schoolID int, instanceURL string, apiType string) (string, error) {
+func (s *Service) LoginWithAPIType(ctx context.Context, username, password string, schoolID int, instanceURL string, apiType string, device DeviceInfo) (*TokenPair, error) {
+	instanceURL, err := s.instances.Check(instanceURL)
+	if err != nil {
+		return nil, err
+	}
+
 	
+	if s.browserLoginRequired(instanceURL) {
+		return s.loginWithBrowser(ctx, username, password, schoolID, instanceURL, device)
+	}
+
 	apiMode := api_types.APIMode(apiType)
 	clientConfig := s.config
 	clientConfig.Mode = apiMode
 
 	apiClient, err := s.apiClientFactory.NewAPIClient(apiMode, clientConfig)
 	if err != nil {
-		return "", fmt.Errorf("failed to create API client: %w", err)
+		return nil, fmt.Errorf("failed to create API client: %w", err)
 	}
 
 	
 	loginData, err := apiClient.GetLoginData(ctx, instanceURL)
 	if err != nil {
-		return "", fmt.Errorf("failed to get login data from API: %w", err)
+		return nil, fmt.Errorf("failed to get login data from API: %w", err)
 	}
 
 	
 	accessToken, err := apiClient.Login(ctx, username, password, schoolID, instanceURL, loginData)
 	if err != nil {
-		return "", fmt.Errorf("failed to authenticate with API: %w", err)
+		
+		if s.browserFallbackAllowed(err) {
+			return s.loginWithBrowser(ctx, username, password, schoolID, instanceURL, device)
+		}
+		return nil, fmt.Errorf("failed to authenticate with API: %w", err)
 	}
 
-	
-	userID := fmt.Sprintf("%s_%d_%s", username, schoolID, apiType)
+	return s.createSession(ctx, apiClient, &api_types.OAuthToken{AccessToken: accessToken}, username, schoolID, instanceURL, apiType, device)
+}
 
-	
-	
-	var studentID string
-	var yearID string
+
+func (s *Service) createSession(ctx context.Context, apiClient api_types.APIClientInterface, token *api_types.OAuthToken, legacyUsername string, schoolID int, instanceURL, apiType string, device DeviceInfo) (*TokenPair, error) {
+	accessToken := token.AccessToken
 
 	
 	userInfo, err := apiClient.GetInfo(ctx, accessToken, instanceURL)
 	if err != nil {
-		
-		studentID = fmt.Sprintf("user_%s_%d", username, schoolID)
-	} else {
-		
-		if userInfoMap, ok := userInfo.(map[string]interface{}); ok {
-			if id, exists := userInfoMap["id"]; exists {
-				studentID = fmt.Sprintf("%v", id)
-			} else {
-				studentID = fmt.Sprintf("user_%s_%d", username, schoolID)
-			}
-		} else {
-			studentID = fmt.Sprintf("user_%s_%d", username, schoolID)
-		}
+		return nil, fmt.Errorf("failed to fetch NetSchool identity: %w", err)
 	}
+	if userInfo.UserID == 0 {
+		return nil, fmt.Errorf("failed to fetch NetSchool identity: %w", ErrNetSchoolIdentityMissing)
+	}
+
+	userID := netschoolUserID(instanceURL, userInfo)
+	s.adoptLegacySessions(ctx, userID, instanceURL, legacyUserIDs(userInfo, legacyUsername, schoolID, apiType))
+	studentID := strconv.Itoa(userInfo.UserID)
+	var yearID string
 
 	
+	year, err := apiClient.GetCurrentYear(ctx, accessToken, instanceURL)
+	if err == nil {
+		yearID = strconv.Itoa(year.ID)
+	} else if userInfo.SchoolYearID != 0 {
+		yearID = strconv.Itoa(userInfo.SchoolYearID)
+	}
+
 	
-	schoolInfo, err := apiClient.GetSchoolInfo(ctx, accessToken, instanceURL)
-	if err != nil {
-		
-		yearID = "current_year"
-	} else {
-		
-		if schoolInfoMap, ok := schoolInfo.(map[string]interface{}); ok {
-			if year, exists := schoolInfoMap["yearId"]; exists {
-				yearID = fmt.Sprintf("%v", year)
-			} else if id, exists := schoolInfoMap["id"]; exists {
-				yearID = fmt.Sprintf("%v", id)
-			} else {
-				yearID = "current_year"
+	var students []SessionStudent
+	studentList, err := apiClient.GetStudents(ctx, accessToken, instanceURL)
+	if err == nil {
+		students = sessionStudents(studentList)
+		if len(students) > 0 {
+			studentID = students[0].StudentID
+			for _, student := range students {
+				if student.StudentID == studentList.CurrentStudentID {
+					studentID = student.StudentID
+					break
+				}
 			}
-		} else {
-			yearID = "current_year"
 		}
 	}
 
 	
+	sessionID, err := newSessionID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate session id: %w", err)
+	}
+
+	
+	now := time.Now()
+	tokenExpiresAt, expiresAt := s.sessionConfig.expiries(token, now)
 	session := &NetSchoolSession{
+		SessionID:            sessionID,
 		UserID:               userID,
 		NetSchoolAccessToken: accessToken, 
-		RefreshToken:         "", 
-		ExpiresAt:            time.Now().Add(24 * time.Hour), 
+		RefreshToken:         token.RefreshToken, 
+		ExpiresAt:            expiresAt, 
+		TokenExpiresAt:       tokenExpiresAt,
 		NetSchoolURL:         instanceURL,                     
 		SchoolID:             schoolID,
 		StudentID:            studentID,
 		YearID:               yearID,
 		APIType:              apiType,                         
-		CreatedAt:            time.Now(),
-		UpdatedAt:            time.Now(),
+		CreatedAt:            now,
+		UpdatedAt:            now,
+		LastUsedAt:           now,
+		DeviceName:           device.Name,
+		UserAgent:            device.UserAgent,
+		Students:             students,
 	}
 
 	if err := s.sessionRepo.Create(ctx, session); err != nil {
-		return "", fmt.Errorf("failed to save session: %w", err)
+		return nil, fmt.Errorf("failed to save session: %w", err)
 	}
 
 	
-	proxyToken, err := s.jwtService.GenerateToken(userID, fmt.Sprintf("%d", session.ID), schoolID)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate proxy token: %w", err)
+	return s.issueTokens(ctx, session, "")
+}
+
+
+
+
+func netschoolUserID(instanceURL string, info *api_types.MySettings) string {
+	host := instanceURL
+	if parsed, err := url.Parse(instanceURL); err == nil && parsed.Host != "" {
+		host = parsed.Host
+	}
+	return fmt.Sprintf("%s_%d_%s", strings.ToLower(info.LoginName), info.UserID, strings.ToLower(host))
+}
+
+
+
+
+
+func legacyUserIDs(info *api_types.MySettings, username string, schoolID int, apiType string) []string {
+	ids := []string{fmt.Sprintf("%s_%d_%s", info.LoginName, schoolID, apiType)}
+	if username != "" && username != info.LoginName {
+		ids = append(ids, fmt.Sprintf("%s_%d_%s", username, schoolID, apiType))
 	}
+	return ids
+}
+
+
 
-	return proxyToken, nil
+
+
+func (s *Service) adoptLegacySessions(ctx context.Context, userID, instanceURL string, legacyIDs []string) {
+	for _, legacyID := range legacyIDs {
+		sessions, err := s.sessionRepo.ListByUserID(ctx, legacyID)
+		if err != nil {
+			logger.Warn("Failed to list legacy sessions", "user_id", legacyID, "error", err)
+			continue
+		}
+		for _, session := range sessions {
+			sessionURL, err := api_types.NormalizeInstanceURL(session.NetSchoolURL)
+			if err != nil || sessionURL != instanceURL {
+				continue
+			}
+			if err := s.sessionRepo.UpdateUserID(ctx, session.ID, userID); err != nil {
+				logger.Warn("Failed to move legacy session", "session_id", session.SessionID, "error", err)
+				continue
+			}
+			if err := s.refreshTokenRepo.ReassignSession(ctx, session.SessionID, userID); err != nil {
+				logger.Warn("Failed to move legacy refresh tokens", "session_id", session.SessionID, "error", err)
+			}
+			logger.Info("Moved legacy session to NetSchool user id", "session_id", session.SessionID, "from", legacyID, "to", userID)
+		}
+	}
 }
 
+func sessionStudents(list *api_types.StudentList) []SessionStudent {
+	students := make([]SessionStudent, 0, len(list.Students))
+	seen := make(map[string]bool, len(list.Students))
+	for _, student := range list.Students {
+		if student.ID == "" || seen[student.ID] {
+			continue
+		}
+		seen[student.ID] = true
+		students = append(students, SessionStudent{
+			StudentID: student.ID,
+			Name:      student.Name,
+			ClassID:   student.ClassID,
+			ClassName: student.ClassName,
+		})
+	}
+	return students
+}
+
+
 func (s *Service) ValidateToken(ctx context.Context, token string) (*security.Claims, error) {
+	claims, _, err := s.ValidateTokenWithSession(ctx, token)
+	return claims, err
+}
+
+
+
+func (s *Service) ValidateTokenWithSession(ctx context.Context, token string) (*security.Claims, *NetSchoolSession, error) {
 	claims, err := s.jwtService.ParseToken(token)
 	if err != nil {
-		return nil, fmt.Errorf("invalid token: %w", err)
+		return nil, nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	
+	revoked, err := s.revocations.IsRevoked(ctx, claims)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if revoked {
+		return nil, nil, ErrTokenRevoked
 	}
 
 	
-	session, err := s.sessionRepo.GetByUserID(ctx, claims.UserID)
+	session, err := s.sessionRepo.GetBySessionID(ctx, claims.SessionID)
 	if err != nil {
-		return nil, fmt.Errorf("session not found: %w", err)
+		return nil, nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil || session.UserID != claims.UserID {
+		return nil, nil, ErrSessionNotFound
 	}
 
 	
 	if session.ExpiresAt.Before(time.Now()) {
 		
-		s.sessionRepo.Delete(ctx, claims.UserID)
-		return nil, errors.New("session expired")
+		s.sessionRepo.DeleteBySessionID(ctx, session.UserID, session.SessionID)
+		return nil, nil, ErrSessionExpired
 	}
 
-	return claims, nil
+	
+	if s.refresher.NeedsRefresh(session) {
+		s.refresher.RefreshAsync(session)
+	}
+
+	
+	if now := time.Now(); now.Sub(session.LastUsedAt) > sessionTouchInterval {
+		if err := s.sessionRepo.Touch(ctx, session.ID, now); err == nil {
+			session.LastUsedAt = now
+		}
+	}
+
+	return claims, session, nil
 }
 
 
-func (s *Service) ValidateTokenWithSession(ctx context.Context, token string) (*security.Claims, *NetSchoolSession, error) {
-	claims, err := s.jwtService.ParseToken(token)
+func (s *Service) ListSessions(ctx context.Context, userID, currentSessionID string) ([]*SessionInfo, error) {
+	sessions, err := s.sessionRepo.ListByUserID(ctx, userID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("invalid token: %w", err)
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
 	}
 
-	
-	session, err := s.sessionRepo.GetByUserID(ctx, claims.UserID)
+	infos := make([]*SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		infos = append(infos, &SessionInfo{
+			ID:         session.SessionID,
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			APIType:    session.APIType,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			Current:    session.SessionID == currentSessionID,
+		})
+	}
+	return infos, nil
+}
+
+
+func (s *Service) SetCachePurger(purger SessionCachePurger) {
+	s.cachePurger = purger
+}
+
+
+func (s *Service) Logout(ctx context.Context, userID, sessionID string) error {
+	return s.RevokeSession(ctx, userID, sessionID)
+}
+
+
+func (s *Service) LogoutAll(ctx context.Context, userID string) error {
+	now := time.Now()
+	if err := s.refreshTokenRepo.RevokeByUserID(ctx, userID, now); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	if err := s.revocations.RevokeUser(ctx, userID, now); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+	if err := s.sessionRepo.DeleteByUserID(ctx, userID); err != nil {
+		return err
+	}
+
+	if s.cachePurger != nil {
+		if err := s.cachePurger.PurgeUser(ctx, userID); err != nil {
+			logger.Warn("Failed to purge cached responses", "user_id", userID, "error", err)
+		}
+	}
+	return nil
+}
+
+
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	session, err := s.sessionRepo.GetBySessionID(ctx, sessionID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("session not found: %w", err)
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil || session.UserID != userID {
+		return ErrSessionNotFound
 	}
 
-	
-	if session.ExpiresAt.Before(time.Now()) {
-		
-		s.sessionRepo.Delete(ctx, claims.UserID)
-		return nil, nil, errors.New("session expired")
+	if err := s.refreshTokenRepo.RevokeBySessionID(ctx, userID, sessionID, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	if err := s.revocations.RevokeSession(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
 	}
 
-	return claims, session, nil
+	deleted, err := s.sessionRepo.DeleteBySessionID(ctx, userID, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	if !deleted {
+		return ErrSessionNotFound
+	}
+
+	if s.cachePurger != nil {
+		if err := s.cachePurger.PurgeSession(ctx, userID, sessionID); err != nil {
+			logger.Warn("Failed to purge cached responses", "session_id", sessionID, "error", err)
+		}
+	}
+	return nil
 }
 
-func (s *Service) GetSessionByUserID(ctx context.Context, userID string) (*NetSchoolSession, error) {
-	session, err := s.sessionRepo.GetByUserID(ctx, userID)
+
+func (s *Service) RevokeSessionByID(ctx context.Context, sessionID string) error {
+	session, err := s.sessionRepo.GetBySessionID(ctx, sessionID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get session: %w", err)
+		return fmt.Errorf("failed to get session: %w", err)
 	}
-	return session, nil
+	if session == nil {
+		return ErrSessionNotFound
+	}
+	return s.RevokeSession(ctx, session.UserID, session.SessionID)
+}
+
+
+func (s *Service) RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	return s.revocations.RevokeToken(ctx, tokenID, expiresAt)
 }
 
-func (s *Service) Logout(ctx context.Context, userID string) error {
-	return s.sessionRepo.Delete(ctx, userID)
-}
\ No newline at end of file
+func newSessionID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,181 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/domain/auth"
+	"netschool-proxy/api/api/internal/domain/calendar"
+	"netschool-proxy/api/api/internal/domain/grade"
+	"netschool-proxy/api/api/internal/domain/schedule"
+	infraCache "netschool-proxy/api/api/internal/infrastructure/cache"
+	"netschool-proxy/api/api/internal/infrastructure/database"
+	"netschool-proxy/api/api/internal/infrastructure/http/v1/middleware"
+	"netschool-proxy/api/api/internal/pkg/security"
+)
+
+type testService struct {
+	*auth.Service
+	db       *gorm.DB
+	sessions *database.SessionRepository
+	cache    *infraCache.MemoryCacheService
+}
+
+func newTestService(t *testing.T) *testService {
+	t.Helper()
+	return newTestServiceWithRefreshTokens(t, nil)
+}
+
+func newTestServiceWithRefreshTokens(t *testing.T, wrap func(auth.RefreshTokenRepository) auth.RefreshTokenRepository) *testService {
+	t.Helper()
+	return newTestServiceWithRegistry(t, api_types.DefaultRegistry, wrap)
+}
+
+func newTestServiceWithRegistry(t *testing.T, registry *api_types.ProviderRegistry, wrap func(auth.RefreshTokenRepository) auth.RefreshTokenRepository) *testService {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "auth.sqlite")), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&auth.NetSchoolSession{}, &auth.SessionStudent{}, &auth.ProxyRefreshToken{}))
+
+	factory, err := api_types.NewAPIClientFactory(registry, api_types.TransportConfig{})
+	require.NoError(t, err)
+
+	allowlist, err := api_types.NewInstanceAllowlist([]string{"https://sgo.rso23.ru"}, false)
+	require.NoError(t, err)
+
+	sessions := database.NewSessionRepository(db)
+	var refreshTokens auth.RefreshTokenRepository = database.NewRefreshTokenRepository(db)
+	if wrap != nil {
+		refreshTokens = wrap(refreshTokens)
+	}
+	cacheService := infraCache.NewMemoryCacheService(1000)
+	service := auth.NewService(
+		sessions,
+		refreshTokens,
+		auth.NewRevocationStore(cacheService, time.Hour),
+		factory,
+		allowlist,
+		api_types.APIConfig{Mode: api_types.DevMockAPI, Timeout: 5},
+		security.NewJWTService("test-secret", time.Hour),
+		auth.SessionConfig{TokenTTL: time.Hour, SessionTTL: 24 * time.Hour, RefreshTokenTTL: 24 * time.Hour},
+	)
+	service.SetCachePurger(middleware.NewCacheMiddleware(cacheService))
+
+	return &testService{Service: service, db: db, sessions: sessions, cache: cacheService}
+}
+
+
+
+func mockRegistry(t *testing.T, wrap func(api_types.APIClientInterface) api_types.APIClientInterface) *api_types.ProviderRegistry {
+	t.Helper()
+	mock, ok := api_types.DefaultRegistry.Lookup(api_types.DevMockAPI)
+	require.True(t, ok)
+	provider := *mock
+	provider.New = func(config api_types.APIConfig, httpClient *http.Client) (api_types.APIClientInterface, error) {
+		client, err := mock.New(config, httpClient)
+		if err != nil {
+			return nil, err
+		}
+		return wrap(client), nil
+	}
+	registry := api_types.NewProviderRegistry()
+	registry.MustRegister(provider)
+	return registry
+}
+
+func (s *testService) login(t *testing.T, username string) (*auth.TokenPair, *security.Claims) {
+	t.Helper()
+	tokens, err := s.LoginWithAPIType(context.Background(), username, "secret", 1, "https://sgo.rso23.ru", string(api_types.DevMockAPI), auth.DeviceInfo{})
+	require.NoError(t, err)
+	claims, err := s.ValidateToken(context.Background(), tokens.AccessToken)
+	require.NoError(t, err)
+	return tokens, claims
+}
+
+func (s *testService) cached(t *testing.T, key string) bool {
+	t.Helper()
+	var value string
+	found, err := s.cache.Get(context.Background(), key, &value)
+	require.NoError(t, err)
+	return found
+}
+
+func TestService_LogoutPurgesServiceCache(t *testing.T) {
+	service := newTestService(t)
+	ctx := context.Background()
+	_, first := service.login(t, "alice")
+	_, second := service.login(t, "alice")
+
+	start, end := grade.DefaultPeriod(time.Now())
+	keysFor := func(claims *security.Claims) []string {
+		return []string{
+			grade.StudentGradesCacheKey(claims.UserID, claims.SessionID, "1001", start, end),
+			schedule.WeeklyCacheKey(claims.UserID, claims.SessionID, "1001", "https://sgo.rso23.ru", schedule.WeekStart(time.Now())),
+			calendar.CacheKey(claims.UserID, claims.SessionID),
+		}
+	}
+	for _, claims := range []*security.Claims{first, second} {
+		for _, key := range keysFor(claims) {
+			require.NoError(t, service.cache.Set(ctx, key, "cached", time.Hour))
+		}
+	}
+
+	require.NoError(t, service.Logout(ctx, first.UserID, first.SessionID))
+	for _, key := range keysFor(first) {
+		assert.False(t, service.cached(t, key), key)
+	}
+	for _, key := range keysFor(second) {
+		assert.True(t, service.cached(t, key), key)
+	}
+
+	require.NoError(t, service.LogoutAll(ctx, second.UserID))
+	for _, key := range keysFor(second) {
+		assert.False(t, service.cached(t, key), key)
+	}
+}
+
+type identityFailingClient struct {
+	api_types.APIClientInterface
+}
+
+func (identityFailingClient) GetInfo(ctx context.Context, userID, instanceURL string) (*api_types.MySettings, error) {
+	return nil, api_types.ErrUnexpectedPayload
+}
+
+func TestService_LoginDerivesUserIDFromNetSchoolIdentity(t *testing.T) {
+	service := newTestService(t)
+
+	_, claims := service.login(t, "Alice")
+	assert.Equal(t, "alice_1001_sgo.rso23.ru", claims.UserID)
+}
+
+func TestService_LoginFailsWithoutNetSchoolIdentity(t *testing.T) {
+	service := newTestServiceWithRegistry(t, mockRegistry(t, func(client api_types.APIClientInterface) api_types.APIClientInterface {
+		return identityFailingClient{client}
+	}), nil)
+
+	_, err := service.LoginWithAPIType(context.Background(), "alice", "secret", 1, "https://sgo.rso23.ru", string(api_types.DevMockAPI), auth.DeviceInfo{})
+	assert.ErrorIs(t, err, api_types.ErrUnexpectedPayload)
+
+	var count int64
+	require.NoError(t, service.db.Model(&auth.NetSchoolSession{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestService_LoginRejectsUnlistedInstance(t *testing.T) {
+	service := newTestService(t)
+
+	_, err := service.LoginWithAPIType(context.Background(), "alice", "secret", 1, "http://10.0.0.5", string(api_types.DevMockAPI), auth.DeviceInfo{})
+	assert.ErrorIs(t, err, api_types.ErrInstanceNotAllowed)
+
+	_, err = service.LoginWithESIA(context.Background(), api_types.ESIACredentials{Login: "alice", Password: "secret"}, "https://evil.example", auth.DeviceInfo{})
+	assert.ErrorIs(t, err, api_types.ErrInstanceNotAllowed)
+}
@@ -0,0 +1,137 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/domain/auth"
+)
+
+func (s *testService) session(t *testing.T, sessionID string) *auth.NetSchoolSession {
+	t.Helper()
+	session, err := s.sessions.GetBySessionID(context.Background(), sessionID)
+	require.NoError(t, err)
+	require.NotNil(t, session)
+	return session
+}
+
+func TestService_LoginKeepsEarlierSessions(t *testing.T) {
+	service := newTestService(t)
+	ctx := context.Background()
+
+	firstTokens, first := service.login(t, "alice")
+	firstSession := service.session(t, first.SessionID)
+	require.NoError(t, service.sessions.UpdateTokens(ctx, firstSession.ID, "netschool-token-1", "", time.Now().Add(time.Hour), firstSession.ExpiresAt))
+
+	_, second := service.login(t, "alice")
+	require.Equal(t, first.UserID, second.UserID)
+	require.NotEqual(t, first.SessionID, second.SessionID)
+
+	sessions, err := service.sessions.ListByUserID(ctx, first.UserID)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 2)
+	assert.Equal(t, "netschool-token-1", service.session(t, first.SessionID).NetSchoolAccessToken)
+	assert.NotEmpty(t, service.session(t, second.SessionID).NetSchoolAccessToken)
+
+	_, err = service.ValidateToken(ctx, firstTokens.AccessToken)
+	assert.NoError(t, err)
+}
+
+func TestService_RevokeSessionRequiresOwner(t *testing.T) {
+	service := newTestService(t)
+	ctx := context.Background()
+	_, alice := service.login(t, "alice")
+	bobTokens, bob := service.login(t, "bob")
+	require.NotEqual(t, alice.UserID, bob.UserID)
+
+	err := service.RevokeSession(ctx, alice.UserID, bob.SessionID)
+	assert.ErrorIs(t, err, auth.ErrSessionNotFound)
+
+	service.session(t, bob.SessionID)
+	_, err = service.ValidateToken(ctx, bobTokens.AccessToken)
+	assert.NoError(t, err)
+}
+
+func TestService_LogoutAllKeepsOtherUsers(t *testing.T) {
+	service := newTestService(t)
+	ctx := context.Background()
+	service.login(t, "alice")
+	_, alice := service.login(t, "alice")
+	bobTokens, bob := service.login(t, "bob")
+
+	require.NoError(t, service.LogoutAll(ctx, alice.UserID))
+
+	sessions, err := service.sessions.ListByUserID(ctx, alice.UserID)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	sessions, err = service.sessions.ListByUserID(ctx, bob.UserID)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	_, err = service.ValidateToken(ctx, bobTokens.AccessToken)
+	assert.NoError(t, err)
+}
+
+func TestService_ValidateTokenTouchesSession(t *testing.T) {
+	service := newTestService(t)
+	ctx := context.Background()
+	tokens, claims := service.login(t, "alice")
+
+	stale := time.Now().Add(-time.Hour)
+	require.NoError(t, service.sessions.Touch(ctx, service.session(t, claims.SessionID).ID, stale))
+	require.WithinDuration(t, stale, service.session(t, claims.SessionID).LastUsedAt, time.Second)
+
+	_, err := service.ValidateToken(ctx, tokens.AccessToken)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), service.session(t, claims.SessionID).LastUsedAt, 5*time.Second)
+}
+
+func TestService_LoginAdoptsLegacySessions(t *testing.T) {
+	service := newTestService(t)
+	ctx := context.Background()
+	legacyID := "alice_1_dev-mockapi"
+	now := time.Now()
+
+	legacy := func(sessionID, instanceURL string) {
+		require.NoError(t, service.sessions.Create(ctx, &auth.NetSchoolSession{
+			SessionID:    sessionID,
+			UserID:       legacyID,
+			ExpiresAt:    now.Add(time.Hour),
+			NetSchoolURL: instanceURL,
+			SchoolID:     1,
+			APIType:      "dev-mockapi",
+			CreatedAt:    now,
+			UpdatedAt:    now,
+			LastUsedAt:   now,
+		}))
+		require.NoError(t, service.db.Create(&auth.ProxyRefreshToken{
+			TokenHash: "hash-" + sessionID,
+			FamilyID:  "family-" + sessionID,
+			SessionID: sessionID,
+			UserID:    legacyID,
+			CreatedAt: now,
+			ExpiresAt: now.Add(time.Hour),
+		}).Error)
+	}
+	legacy("legacy-same", "https://sgo.rso23.ru/")
+	legacy("legacy-other", "https://other.example")
+
+	_, claims := service.login(t, "alice")
+	assert.Equal(t, claims.UserID, service.session(t, "legacy-same").UserID)
+	assert.Equal(t, legacyID, service.session(t, "legacy-other").UserID)
+
+	sessions, err := service.sessions.ListByUserID(ctx, claims.UserID)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 2)
+
+	refreshTokenOwner := func(sessionID string) string {
+		var token auth.ProxyRefreshToken
+		require.NoError(t, service.db.Where("session_id = ?", sessionID).First(&token).Error)
+		return token.UserID
+	}
+	assert.Equal(t, claims.UserID, refreshTokenOwner("legacy-same"))
+	assert.Equal(t, legacyID, refreshTokenOwner("legacy-other"))
+}
@@ -2,61 +2,98 @@ package cache
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"log"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
-	"netschool-proxy/api/api/internal/api_types"
 	"netschool-proxy/api/api/internal/domain/auth"
+	"netschool-proxy/api/api/internal/pkg/logger"
 )
 
 
+
+type SessionWarmer interface {
+	WarmSession(ctx context.Context, session *auth.NetSchoolSession) error
+}
+
+
+type ActiveSessionLister interface {
+	ListActive(ctx context.Context, usedSince time.Time) ([]*auth.NetSchoolSession, error)
+}
+
+
+type AutoCacheConfig struct {
+	Interval      time.Duration
+	ActiveWithin  time.Duration
+	Concurrency   int
+	PerInstance   int
+	InstanceDelay time.Duration
+	StartHour     int
+	EndHour       int
+}
+
+
+type AutoCacheStats struct {
+	Sessions int
+	Warmed   int
+	Failed   int
+}
+
+
+
 type AutoCacheService struct {
-	cacheRepo      Repository
-	sessionRepo    auth.SessionRepository
-	apiClientFactory *api_types.APIClientFactory
-	config         api_types.APIConfig
-	interval       time.Duration
-	stopChan       chan struct{}
-	wg             sync.WaitGroup
-}
-
-
-func NewAutoCacheService(
-	cacheRepo Repository,
-	sessionRepo auth.SessionRepository,
-	apiClientFactory *api_types.APIClientFactory,
-	config api_types.APIConfig,
-	interval time.Duration,
-) *AutoCacheService {
+	sessions ActiveSessionLister
+	warmers  []SessionWarmer
+	config   AutoCacheConfig
+	stopChan chan struct{}
+	stopOnce sync.Once
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+
+func NewAutoCacheService(sessions ActiveSessionLister, config AutoCacheConfig, warmers ...SessionWarmer) *AutoCacheService {
+	if config.Interval <= 0 {
+		config.Interval = 15 * time.Minute
+	}
+	if config.ActiveWithin <= 0 {
+		config.ActiveWithin = 7 * 24 * time.Hour
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 4
+	}
+	if config.PerInstance <= 0 {
+		config.PerInstance = 1
+	}
+	if config.EndHour <= 0 || config.EndHour > 24 {
+		config.EndHour = 24
+	}
+
 	return &AutoCacheService{
-		cacheRepo:      cacheRepo,
-		sessionRepo:    sessionRepo,
-		apiClientFactory: apiClientFactory,
-		config:         config,
-		interval:       interval,
-		stopChan:       make(chan struct{}),
+		sessions: sessions,
+		warmers:  warmers,
+		config:   config,
+		stopChan: make(chan struct{}),
 	}
 }
 
 
 func (s *AutoCacheService) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
 	s.wg.Add(1)
 	go func() {
 		defer s.wg.Done()
-		
-		ticker := time.NewTicker(s.interval)
+
+		ticker := time.NewTicker(s.config.Interval)
 		defer ticker.Stop()
-		
-		
-		s.updateCache(ctx)
-		
+
+		s.tick(ctx)
+
 		for {
 			select {
 			case <-ticker.C:
-				s.updateCache(ctx)
+				s.tick(ctx)
 			case <-s.stopChan:
 				return
 			case <-ctx.Done():
@@ -68,151 +105,160 @@ func (s *AutoCacheService) Start(ctx context.Context) {
 
 
 func (s *AutoCacheService) Stop() {
-	close(s.stopChan)
+	s.stopOnce.Do(func() {
+		close(s.stopChan)
+		if s.cancel != nil {
+			s.cancel()
+		}
+	})
 	s.wg.Wait()
 }
 
-
-func (s *AutoCacheService) updateCache(ctx context.Context) {
-	
-	sessions, err := s.getAllActiveSessions(ctx)
-	if err != nil {
-		log.Printf("Failed to get active sessions for cache update: %v", err)
+func (s *AutoCacheService) tick(ctx context.Context) {
+	if !s.inWindow(time.Now()) {
 		return
 	}
 
-	for _, session := range sessions {
-		
-		if err := s.updateSessionCache(ctx, session); err != nil {
-			log.Printf("Failed to update cache for session %s: %v", session.UserID, err)
-		}
+	stats, err := s.RunOnce(ctx)
+	if err != nil {
+		logger.Error("Failed to list active sessions for cache prefetch", "error", err)
+		return
 	}
+	logger.Info("Cache prefetch finished", "sessions", stats.Sessions, "warmed", stats.Warmed, "failed", stats.Failed)
 }
 
 
-func (s *AutoCacheService) getAllActiveSessions(ctx context.Context) ([]*auth.NetSchoolSession, error) {
-	
-	
-	
-	return []*auth.NetSchoolSession{}, nil
+func (s *AutoCacheService) inWindow(now time.Time) bool {
+	hour := now.Hour()
+	if s.config.StartHour <= s.config.EndHour {
+		return hour >= s.config.StartHour && hour < s.config.EndHour
+	}
+	return hour >= s.config.StartHour || hour < s.config.EndHour
 }
 
 
-func (s *AutoCacheService) updateSessionCache(ctx context.Context, session *auth.NetSchoolSession) error {
-	
-	apiMode := api_types.APIMode(session.APIType)
-	clientConfig := s.config
-	clientConfig.Mode = apiMode
 
-	apiClient, err := s.apiClientFactory.NewAPIClient(apiMode, clientConfig)
-	if err != nil {
-		return fmt.Errorf("failed to create API client: %w", err)
-	}
 
-	
-	if err := s.updateWeeklyCache(ctx, apiClient, session); err != nil {
-		return fmt.Errorf("failed to update weekly cache: %w", err)
+func (s *AutoCacheService) RunOnce(ctx context.Context) (AutoCacheStats, error) {
+	sessions, err := s.sessions.ListActive(ctx, time.Now().Add(-s.config.ActiveWithin))
+	if err != nil {
+		return AutoCacheStats{}, err
 	}
 
-	
-	if err := s.updateFinalGradesCache(ctx, apiClient, session); err != nil {
-		return fmt.Errorf("failed to update final grades cache: %w", err)
-	}
+	stats := AutoCacheStats{Sessions: len(sessions)}
+	limiter := newInstanceLimiter(s.config.PerInstance, s.config.InstanceDelay)
+	slots := make(chan struct{}, s.config.Concurrency)
 
-	return nil
-}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
 
+	for _, session := range sessions {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return stats, nil
+		}
 
-func (s *AutoCacheService) updateWeeklyCache(ctx context.Context, apiClient api_types.APIClientInterface, session *auth.NetSchoolSession) error {
-	
-	weekStart := time.Now()
-	scheduleData, err := apiClient.GetSchedule(ctx, session.NetSchoolAccessToken, session.NetSchoolURL, weekStart)
-	if err != nil {
-		
-		return s.saveBackupCache(ctx, session, "schedule_"+weekStart.Format("2006-01-02"))
-	}
+		wg.Add(1)
+		go func(session *auth.NetSchoolSession) {
+			defer wg.Done()
+			defer func() { <-slots }()
 
-	
-	cacheKey := fmt.Sprintf("schedule_week_%s_%s", session.UserID, weekStart.Format("2006-01-02"))
-	jsonData, err := json.Marshal(scheduleData)
-	if err != nil {
-		return fmt.Errorf("failed to marshal schedule data: %w", err)
-	}
+			failed := s.warmSession(ctx, limiter, session)
 
-	if err := s.cacheRepo.Set(ctx, cacheKey, string(jsonData), 24*time.Hour); err != nil {
-		return fmt.Errorf("failed to save schedule to cache: %w", err)
+			mu.Lock()
+			if failed {
+				stats.Failed++
+			} else {
+				stats.Warmed++
+			}
+			mu.Unlock()
+		}(session)
 	}
 
-	return nil
+	wg.Wait()
+	return stats, nil
 }
 
 
-func (s *AutoCacheService) updateFinalGradesCache(ctx context.Context, apiClient api_types.APIClientInterface, session *auth.NetSchoolSession) error {
-	
-	gradesData, err := apiClient.GetGrades(ctx, session.NetSchoolAccessToken, session.StudentID, session.NetSchoolURL)
-	if err != nil {
-		
-		return s.saveBackupCache(ctx, session, "final_grades")
-	}
-
-	
-	cacheKey := fmt.Sprintf("final_grades_%s", session.UserID)
-	jsonData, err := json.Marshal(gradesData)
-	if err != nil {
-		return fmt.Errorf("failed to marshal grades data: %w", err)
-	}
+func (s *AutoCacheService) warmSession(ctx context.Context, limiter *instanceLimiter, session *auth.NetSchoolSession) bool {
+	failed := false
+	for _, warmer := range s.warmers {
+		release, err := limiter.acquire(ctx, instanceHost(session.NetSchoolURL))
+		if err != nil {
+			return true
+		}
+		err = warmer.WarmSession(ctx, session)
+		release()
 
-	if err := s.cacheRepo.Set(ctx, cacheKey, string(jsonData), 24*time.Hour); err != nil {
-		return fmt.Errorf("failed to save grades to cache: %w", err)
+		if err != nil {
+			failed = true
+			logger.Warn("Failed to prefetch session cache", "session_id", session.SessionID, "error", err)
+		}
 	}
-
-	return nil
+	return failed
 }
 
 
-func (s *AutoCacheService) saveBackupCache(ctx context.Context, session *auth.NetSchoolSession, prefix string) error {
-	
-	backupKey := fmt.Sprintf("%s_backup_%s", prefix, session.UserID)
-	currentKey := fmt.Sprintf("%s_%s", prefix, session.UserID)
-	
-	currentData, exists, err := s.cacheRepo.Get(ctx, currentKey)
-	if err != nil || !exists {
-		
-		return nil
-	}
 
-	
-	if err := s.cacheRepo.Set(ctx, backupKey, currentData, 48*time.Hour); err != nil {
-		return fmt.Errorf("failed to save backup cache: %w", err)
-	}
+type instanceLimiter struct {
+	mu          sync.Mutex
+	perInstance int
+	delay       time.Duration
+	slots       map[string]chan struct{}
+	next        map[string]time.Time
+}
 
-	return nil
+func newInstanceLimiter(perInstance int, delay time.Duration) *instanceLimiter {
+	return &instanceLimiter{
+		perInstance: perInstance,
+		delay:       delay,
+		slots:       make(map[string]chan struct{}),
+		next:        make(map[string]time.Time),
+	}
 }
 
+func (l *instanceLimiter) acquire(ctx context.Context, instance string) (func(), error) {
+	l.mu.Lock()
+	slot, exists := l.slots[instance]
+	if !exists {
+		slot = make(chan struct{}, l.perInstance)
+		l.slots[instance] = slot
+	}
+	l.mu.Unlock()
 
-func (s *AutoCacheService) GetCachedData(ctx context.Context, userID, dataType string, weekStart time.Time) (interface{}, bool, error) {
-	var cacheKey string
-	if weekStart.IsZero() {
-		cacheKey = fmt.Sprintf("%s_%s", dataType, userID)
-	} else {
-		cacheKey = fmt.Sprintf("%s_%s_%s", dataType, userID, weekStart.Format("2006-01-02"))
+	select {
+	case slot <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
+	release := func() { <-slot }
 
-	
-	data, exists, err := s.cacheRepo.Get(ctx, cacheKey)
-	if err != nil || !exists {
-		
-		backupKey := fmt.Sprintf("%s_backup_%s", dataType, userID)
-		data, exists, err = s.cacheRepo.Get(ctx, backupKey)
-		if err != nil || !exists {
-			return nil, false, err
+	l.mu.Lock()
+	now := time.Now()
+	start := l.next[instance]
+	if start.Before(now) {
+		start = now
+	}
+	l.next[instance] = start.Add(l.delay)
+	l.mu.Unlock()
+
+	if wait := start.Sub(now); wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
 		}
 	}
+	return release, nil
+}
 
-	var result interface{}
-	if err := json.Unmarshal([]byte(data), &result); err != nil {
-		return nil, false, fmt.Errorf("failed to unmarshal cached data: %w", err)
+func instanceHost(instanceURL string) string {
+	parsed, err := url.Parse(instanceURL)
+	if err != nil || parsed.Host == "" {
+		return strings.ToLower(instanceURL)
 	}
-
-	return result, true, nil
-}
\ No newline at end of file
+	return strings.ToLower(parsed.Host)
+}
@@ -0,0 +1,160 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/domain/auth"
+	"netschool-proxy/api/api/internal/domain/cache"
+)
+
+type fakeSessionLister struct {
+	sessions  []*auth.NetSchoolSession
+	usedSince time.Time
+}
+
+func (f *fakeSessionLister) ListActive(ctx context.Context, usedSince time.Time) ([]*auth.NetSchoolSession, error) {
+	f.usedSince = usedSince
+	return f.sessions, nil
+}
+
+type recordingWarmer struct {
+	mu          sync.Mutex
+	delay       time.Duration
+	fail        map[string]bool
+	warmed      []string
+	active      int
+	maxActive   int
+	perInstance map[string]int
+	maxInstance map[string]int
+}
+
+func newRecordingWarmer(delay time.Duration) *recordingWarmer {
+	return &recordingWarmer{
+		delay:       delay,
+		fail:        make(map[string]bool),
+		perInstance: make(map[string]int),
+		maxInstance: make(map[string]int),
+	}
+}
+
+func (w *recordingWarmer) WarmSession(ctx context.Context, session *auth.NetSchoolSession) error {
+	w.mu.Lock()
+	w.active++
+	w.perInstance[session.NetSchoolURL]++
+	if w.active > w.maxActive {
+		w.maxActive = w.active
+	}
+	if w.perInstance[session.NetSchoolURL] > w.maxInstance[session.NetSchoolURL] {
+		w.maxInstance[session.NetSchoolURL] = w.perInstance[session.NetSchoolURL]
+	}
+	w.mu.Unlock()
+
+	time.Sleep(w.delay)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.active--
+	w.perInstance[session.NetSchoolURL]--
+	w.warmed = append(w.warmed, session.SessionID)
+	if w.fail[session.SessionID] {
+		return errors.New("upstream unavailable")
+	}
+	return nil
+}
+
+func TestAutoCacheService_RunOnceBoundsConcurrency(t *testing.T) {
+	lister := &fakeSessionLister{}
+	for i, instance := range []string{"https://a.example", "https://a.example", "https://a.example", "https://b.example", "https://b.example", "https://c.example"} {
+		lister.sessions = append(lister.sessions, &auth.NetSchoolSession{
+			SessionID:    string(rune('a' + i)),
+			NetSchoolURL: instance,
+		})
+	}
+
+	warmer := newRecordingWarmer(20 * time.Millisecond)
+	warmer.fail["f"] = true
+
+	service := cache.NewAutoCacheService(lister, cache.AutoCacheConfig{
+		ActiveWithin: 24 * time.Hour,
+		Concurrency:  2,
+		PerInstance:  1,
+	}, warmer)
+
+	before := time.Now()
+	stats, err := service.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, cache.AutoCacheStats{Sessions: 6, Warmed: 5, Failed: 1}, stats)
+	assert.Len(t, warmer.warmed, 6)
+	assert.LessOrEqual(t, warmer.maxActive, 2)
+	for instance, max := range warmer.maxInstance {
+		assert.Equal(t, 1, max, instance)
+	}
+	assert.WithinDuration(t, before.Add(-24*time.Hour), lister.usedSince, time.Second)
+}
+
+func TestAutoCacheService_InstanceDelaySpacesRequests(t *testing.T) {
+	lister := &fakeSessionLister{sessions: []*auth.NetSchoolSession{
+		{SessionID: "a", NetSchoolURL: "https://a.example"},
+		{SessionID: "b", NetSchoolURL: "https://a.example"},
+		{SessionID: "c", NetSchoolURL: "https://a.example"},
+	}}
+	warmer := newRecordingWarmer(0)
+
+	service := cache.NewAutoCacheService(lister, cache.AutoCacheConfig{
+		Concurrency:   3,
+		PerInstance:   3,
+		InstanceDelay: 30 * time.Millisecond,
+	}, warmer)
+
+	start := time.Now()
+	_, err := service.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+	assert.Len(t, warmer.warmed, 3)
+}
+
+type blockingWarmer struct {
+	started chan struct{}
+}
+
+func (w *blockingWarmer) WarmSession(ctx context.Context, session *auth.NetSchoolSession) error {
+	close(w.started)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestAutoCacheService_StopCancelsRunningPrefetch(t *testing.T) {
+	lister := &fakeSessionLister{sessions: []*auth.NetSchoolSession{{SessionID: "a", NetSchoolURL: "https://a.example"}}}
+	warmer := &blockingWarmer{started: make(chan struct{})}
+	service := cache.NewAutoCacheService(lister, cache.AutoCacheConfig{}, warmer)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service.Start(ctx)
+
+	select {
+	case <-warmer.started:
+	case <-time.After(time.Second):
+		t.Fatal("prefetch did not start")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		service.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop blocked while the parent context was still active")
+	}
+}
@@ -0,0 +1,17 @@
+package cache
+
+import "net/url"
+
+
+const sessionKeyPrefix = "session:"
+
+
+
+func UserKeyPrefix(userID string) string {
+	return sessionKeyPrefix + url.QueryEscape(userID) + ":"
+}
+
+
+func SessionKeyPrefix(userID, sessionID string) string {
+	return UserKeyPrefix(userID) + url.QueryEscape(sessionID) + ":"
+}
@@ -0,0 +1,255 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"netschool-proxy/api/api/internal/pkg/logger"
+)
+
+
+type Freshness string
+
+const (
+	FreshnessHit   Freshness = "HIT"
+	FreshnessStale Freshness = "STALE"
+	FreshnessMiss  Freshness = "MISS"
+)
+
+
+const revalidateTimeout = 30 * time.Second
+
+
+
+
+
+
+type Policy struct {
+	FreshFor      time.Duration
+	RevalidateFor time.Duration
+	ServeStaleFor time.Duration
+}
+
+func (p Policy) ttl() time.Duration {
+	ttl := p.FreshFor + p.RevalidateFor
+	if p.ServeStaleFor > ttl {
+		ttl = p.ServeStaleFor
+	}
+	return ttl
+}
+
+
+type Result struct {
+	Status Freshness
+	AsOf   time.Time
+}
+
+
+type FetchFunc func(ctx context.Context) (interface{}, error)
+
+
+type policyEntry struct {
+	Value    json.RawMessage `json:"value"`
+	StoredAt time.Time       `json:"stored_at"`
+}
+
+
+
+
+type PolicyCache struct {
+	cache        CacheStrategy
+	mu           sync.Mutex
+	revalidating map[string]bool
+}
+
+func NewPolicyCache(cache CacheStrategy) *PolicyCache {
+	return &PolicyCache{
+		cache:        cache,
+		revalidating: make(map[string]bool),
+	}
+}
+
+
+
+
+func (p *PolicyCache) Fetch(ctx context.Context, key string, policy Policy, target interface{}, fetch FetchFunc) (Result, error) {
+	entry, found := p.load(ctx, key)
+	if !found {
+		result, err := p.refresh(ctx, key, policy, target, fetch)
+		if err != nil {
+			return Result{}, err
+		}
+		recordStatus(ctx, result)
+		return result, nil
+	}
+
+	age := time.Since(entry.StoredAt)
+	switch {
+	case age < policy.FreshFor:
+		if err := json.Unmarshal(entry.Value, target); err != nil {
+			return Result{}, fmt.Errorf("failed to decode cached %s: %w", key, err)
+		}
+		result := Result{Status: FreshnessHit, AsOf: entry.StoredAt}
+		recordStatus(ctx, result)
+		return result, nil
+
+	case age < policy.FreshFor+policy.RevalidateFor:
+		if err := json.Unmarshal(entry.Value, target); err != nil {
+			return Result{}, fmt.Errorf("failed to decode cached %s: %w", key, err)
+		}
+		p.revalidate(ctx, key, policy, fetch)
+		result := Result{Status: FreshnessStale, AsOf: entry.StoredAt}
+		recordStatus(ctx, result)
+		return result, nil
+	}
+
+	result, err := p.refresh(ctx, key, policy, target, fetch)
+	if err == nil {
+		recordStatus(ctx, result)
+		return result, nil
+	}
+	if age >= policy.ServeStaleFor {
+		return Result{}, err
+	}
+
+
+	logger.Warn("Serving stale data after upstream failure", "key", key, "error", err)
+	if decodeErr := json.Unmarshal(entry.Value, target); decodeErr != nil {
+		return Result{}, err
+	}
+	result = Result{Status: FreshnessStale, AsOf: entry.StoredAt}
+	recordStatus(ctx, result)
+	return result, nil
+}
+
+
+
+func (p *PolicyCache) Refresh(ctx context.Context, key string, policy Policy, target interface{}, fetch FetchFunc) (Result, error) {
+	return p.refresh(ctx, key, policy, target, fetch)
+}
+
+func (p *PolicyCache) refresh(ctx context.Context, key string, policy Policy, target interface{}, fetch FetchFunc) (Result, error) {
+	value, err := fetch(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	if target != nil {
+		if err := json.Unmarshal(data, target); err != nil {
+			return Result{}, fmt.Errorf("failed to decode %s: %w", key, err)
+		}
+	}
+
+	now := time.Now()
+	if p.cache != nil {
+		if err := p.cache.Set(ctx, key, policyEntry{Value: data, StoredAt: now}, policy.ttl()); err != nil {
+			logger.Warn("Failed to store cache entry", "key", key, "error", err)
+		}
+	}
+	return Result{Status: FreshnessMiss, AsOf: now}, nil
+}
+
+
+
+func (p *PolicyCache) revalidate(ctx context.Context, key string, policy Policy, fetch FetchFunc) {
+	p.mu.Lock()
+	if p.revalidating[key] {
+		p.mu.Unlock()
+		return
+	}
+	p.revalidating[key] = true
+	p.mu.Unlock()
+
+	go func() {
+		defer func() {
+			p.mu.Lock()
+			delete(p.revalidating, key)
+			p.mu.Unlock()
+		}()
+
+		revalidateCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), revalidateTimeout)
+		defer cancel()
+
+		if _, err := p.refresh(revalidateCtx, key, policy, nil, fetch); err != nil {
+			logger.Warn("Background cache revalidation failed", "key", key, "error", err)
+		}
+	}()
+}
+
+func (p *PolicyCache) load(ctx context.Context, key string) (policyEntry, bool) {
+	if p.cache == nil {
+		return policyEntry{}, false
+	}
+	var entry policyEntry
+	found, err := p.cache.Get(ctx, key, &entry)
+	if err != nil || !found || len(entry.Value) == 0 {
+		return policyEntry{}, false
+	}
+	return entry, true
+}
+
+type statusKey struct{}
+
+
+
+
+type StatusRecorder struct {
+	mu     sync.Mutex
+	result Result
+	set    bool
+}
+
+
+func WithStatusRecorder(ctx context.Context) (context.Context, *StatusRecorder) {
+	recorder := &StatusRecorder{}
+	return context.WithValue(ctx, statusKey{}, recorder), recorder
+}
+
+
+
+func (r *StatusRecorder) Result() (Result, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.result, r.set
+}
+
+func (r *StatusRecorder) record(result Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.set {
+		r.result = result
+		r.set = true
+		return
+	}
+	if freshnessRank(result.Status) > freshnessRank(r.result.Status) {
+		r.result.Status = result.Status
+	}
+	if result.AsOf.Before(r.result.AsOf) {
+		r.result.AsOf = result.AsOf
+	}
+}
+
+func recordStatus(ctx context.Context, result Result) {
+	if recorder, ok := ctx.Value(statusKey{}).(*StatusRecorder); ok {
+		recorder.record(result)
+	}
+}
+
+func freshnessRank(status Freshness) int {
+	switch status {
+	case FreshnessStale:
+		return 2
+	case FreshnessMiss:
+		return 1
+	default:
+		return 0
+	}
+}
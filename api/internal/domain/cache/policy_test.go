@@ -0,0 +1,143 @@
+package cache_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/domain/cache"
+	infraCache "netschool-proxy/api/api/internal/infrastructure/cache"
+)
+
+var testPolicy = cache.Policy{
+	FreshFor:      time.Minute,
+	RevalidateFor: time.Hour,
+	ServeStaleFor: 24 * time.Hour,
+}
+
+func storeEntry(t *testing.T, store cache.CacheStrategy, key string, value interface{}, age time.Duration) {
+	t.Helper()
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+	entry := map[string]interface{}{
+		"value":     json.RawMessage(data),
+		"stored_at": time.Now().Add(-age),
+	}
+	require.NoError(t, store.Set(context.Background(), key, entry, 48*time.Hour))
+}
+
+func TestPolicyCache_MissFetchesAndStores(t *testing.T) {
+	store := infraCache.NewMemoryCacheService(100)
+	policy := cache.NewPolicyCache(store)
+
+	var calls int32
+	fetch := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return map[string]string{"day": "monday"}, nil
+	}
+
+	var got map[string]string
+	result, err := policy.Fetch(context.Background(), "key", testPolicy, &got, fetch)
+	require.NoError(t, err)
+	assert.Equal(t, cache.FreshnessMiss, result.Status)
+	assert.Equal(t, "monday", got["day"])
+
+	got = nil
+	result, err = policy.Fetch(context.Background(), "key", testPolicy, &got, fetch)
+	require.NoError(t, err)
+	assert.Equal(t, cache.FreshnessHit, result.Status)
+	assert.Equal(t, "monday", got["day"])
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestPolicyCache_StaleRevalidatesInBackground(t *testing.T) {
+	store := infraCache.NewMemoryCacheService(100)
+	policy := cache.NewPolicyCache(store)
+	storeEntry(t, store, "key", "old", 10*time.Minute)
+
+	refreshed := make(chan struct{})
+	fetch := func(ctx context.Context) (interface{}, error) {
+		defer close(refreshed)
+		return "new", nil
+	}
+
+	var got string
+	result, err := policy.Fetch(context.Background(), "key", testPolicy, &got, fetch)
+	require.NoError(t, err)
+	assert.Equal(t, cache.FreshnessStale, result.Status)
+	assert.Equal(t, "old", got)
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("background revalidation did not run")
+	}
+
+	assert.Eventually(t, func() bool {
+		var value string
+		result, err := policy.Fetch(context.Background(), "key", testPolicy, &value, fetch)
+		return err == nil && result.Status == cache.FreshnessHit && value == "new"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestPolicyCache_ServesStaleOnUpstreamError(t *testing.T) {
+	store := infraCache.NewMemoryCacheService(100)
+	policy := cache.NewPolicyCache(store)
+	storeEntry(t, store, "key", "old", 2*time.Hour)
+
+	upstreamErr := errors.New("netschool is down")
+	fetch := func(ctx context.Context) (interface{}, error) {
+		return nil, upstreamErr
+	}
+
+	var got string
+	result, err := policy.Fetch(context.Background(), "key", testPolicy, &got, fetch)
+	require.NoError(t, err)
+	assert.Equal(t, cache.FreshnessStale, result.Status)
+	assert.Equal(t, "old", got)
+	assert.WithinDuration(t, time.Now().Add(-2*time.Hour), result.AsOf, time.Minute)
+}
+
+func TestPolicyCache_ReturnsErrorWhenTooOld(t *testing.T) {
+	store := infraCache.NewMemoryCacheService(100)
+	policy := cache.NewPolicyCache(store)
+	storeEntry(t, store, "key", "old", 30*time.Hour)
+
+	upstreamErr := errors.New("netschool is down")
+	var got string
+	_, err := policy.Fetch(context.Background(), "key", testPolicy, &got, func(ctx context.Context) (interface{}, error) {
+		return nil, upstreamErr
+	})
+	assert.ErrorIs(t, err, upstreamErr)
+}
+
+func TestStatusRecorder_KeepsWorstStatusAndOldestData(t *testing.T) {
+	store := infraCache.NewMemoryCacheService(100)
+	policy := cache.NewPolicyCache(store)
+	storeEntry(t, store, "fresh", "a", 0)
+	storeEntry(t, store, "stale", "b", 3*time.Hour)
+
+	ctx, recorder := cache.WithStatusRecorder(context.Background())
+	_, recorded := recorder.Result()
+	assert.False(t, recorded)
+
+	failing := func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("unavailable")
+	}
+
+	var value string
+	_, err := policy.Fetch(ctx, "fresh", testPolicy, &value, failing)
+	require.NoError(t, err)
+	_, err = policy.Fetch(ctx, "stale", testPolicy, &value, failing)
+	require.NoError(t, err)
+
+	result, recorded := recorder.Result()
+	require.True(t, recorded)
+	assert.Equal(t, cache.FreshnessStale, result.Status)
+	assert.WithinDuration(t, time.Now().Add(-3*time.Hour), result.AsOf, time.Minute)
+}
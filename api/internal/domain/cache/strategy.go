@@ -15,6 +15,12 @@ type CacheStrategy interface {
 	Clear(ctx context.Context) error
 }
 
+
+
+type PrefixDeleter interface {
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
 type RedisCache struct {
 	client *redis.Client
 }
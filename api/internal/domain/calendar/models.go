@@ -0,0 +1,48 @@
+package calendar
+
+import (
+	"errors"
+	"time"
+
+	"netschool-proxy/api/api/internal/api_types"
+)
+
+
+var (
+	ErrNoCurrentTerm = errors.New("no term covers the current date")
+	ErrUnknownTerm   = errors.New("term does not belong to the current school year")
+)
+
+func init() {
+	api_types.RegisterErrorCode(ErrNoCurrentTerm, api_types.ErrCodeDataNotFound)
+	api_types.RegisterErrorCode(ErrUnknownTerm, api_types.ErrCodeDataValidationError)
+}
+
+
+type Calendar struct {
+	Year          api_types.SchoolYear   `json:"year"`
+	Years         []api_types.SchoolYear `json:"years"`
+	Terms         []api_types.Term       `json:"terms"`
+	Classes       []api_types.Class      `json:"classes"`
+	CurrentTermID int                    `json:"current_term_id,omitempty"`
+}
+
+
+func (c *Calendar) TermAt(date time.Time) (*api_types.Term, bool) {
+	for i := range c.Terms {
+		if c.Terms[i].Contains(date) {
+			return &c.Terms[i], true
+		}
+	}
+	return nil, false
+}
+
+
+func (c *Calendar) Term(termID int) (*api_types.Term, bool) {
+	for i := range c.Terms {
+		if c.Terms[i].ID == termID {
+			return &c.Terms[i], true
+		}
+	}
+	return nil, false
+}
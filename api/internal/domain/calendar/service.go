@@ -0,0 +1,141 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/domain/auth"
+	"netschool-proxy/api/api/internal/domain/cache"
+)
+
+
+const calendarTTL = 12 * time.Hour
+
+
+
+type Service struct {
+	apiClientFactory *api_types.APIClientFactory
+	sessionRepo      auth.SessionRepository
+	cacheService     cache.CacheStrategy
+	config           api_types.APIConfig
+	refresher        *auth.SessionRefresher
+}
+
+func NewService(apiClientFactory *api_types.APIClientFactory, sessionRepo auth.SessionRepository, cacheService cache.CacheStrategy, config api_types.APIConfig, refresher *auth.SessionRefresher) *Service {
+	return &Service{
+		apiClientFactory: apiClientFactory,
+		sessionRepo:      sessionRepo,
+		cacheService:     cacheService,
+		config:           config,
+		refresher:        refresher,
+	}
+}
+
+
+func CacheKey(userID, sessionID string) string {
+	return cache.SessionKeyPrefix(userID, sessionID) + "calendar"
+}
+
+
+func (s *Service) GetCalendar(ctx context.Context, userID, sessionID string) (*Calendar, error) {
+	cacheKey := CacheKey(userID, sessionID)
+	if s.cacheService != nil {
+		var cached Calendar
+		found, err := s.cacheService.Get(ctx, cacheKey, &cached)
+		if err == nil && found {
+			return &cached, nil
+		}
+	}
+
+	session, err := s.sessionRepo.GetBySessionID(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user session: %w", err)
+	}
+	if session == nil {
+		return nil, auth.ErrSessionNotFound
+	}
+
+	apiMode := api_types.APIMode(session.APIType)
+	clientConfig := s.config
+	clientConfig.Mode = apiMode
+
+	apiClient, err := s.apiClientFactory.NewAPIClient(apiMode, clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	calendar, err := auth.CallWithRefresh(ctx, s.refresher, session, func() (*Calendar, error) {
+		return s.discover(ctx, apiClient, session)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cacheService != nil {
+		s.cacheService.Set(ctx, cacheKey, calendar, calendarTTL)
+	}
+
+	return calendar, nil
+}
+
+
+
+func (s *Service) discover(ctx context.Context, apiClient api_types.APIClientInterface, session *auth.NetSchoolSession) (*Calendar, error) {
+	accessToken := session.NetSchoolAccessToken
+	instanceURL := session.NetSchoolURL
+
+	year, err := apiClient.GetCurrentYear(ctx, accessToken, instanceURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current year: %w", err)
+	}
+
+	years, err := apiClient.GetYears(ctx, accessToken, instanceURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get school years: %w", err)
+	}
+
+	terms, err := apiClient.GetTerms(ctx, accessToken, instanceURL, year.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get terms: %w", err)
+	}
+
+	classes, err := apiClient.GetClasses(ctx, accessToken, instanceURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get classes: %w", err)
+	}
+
+	calendar := &Calendar{
+		Year:    *year,
+		Years:   years,
+		Terms:   terms,
+		Classes: classes,
+	}
+	if term, ok := calendar.TermAt(time.Now()); ok {
+		calendar.CurrentTermID = term.ID
+	}
+
+	return calendar, nil
+}
+
+
+
+
+func (s *Service) ResolveTerm(ctx context.Context, userID, sessionID string, termID int) (*api_types.Term, error) {
+	calendar, err := s.GetCalendar(ctx, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if termID == 0 {
+		if calendar.CurrentTermID == 0 {
+			return nil, ErrNoCurrentTerm
+		}
+		termID = calendar.CurrentTermID
+	}
+	term, ok := calendar.Term(termID)
+	if !ok {
+		return nil, ErrUnknownTerm
+	}
+	return term, nil
+}
@@ -0,0 +1,148 @@
+package calendar_test
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/domain/auth"
+	"netschool-proxy/api/api/internal/domain/calendar"
+	infraCache "netschool-proxy/api/api/internal/infrastructure/cache"
+	"netschool-proxy/api/api/internal/infrastructure/database"
+)
+
+const dateLayout = "2006-01-02T15:04:05"
+
+type termsClient struct {
+	api_types.APIClientInterface
+	terms []api_types.Term
+	calls *int32
+}
+
+func (c termsClient) GetTerms(ctx context.Context, userID, instanceURL string, yearID int) ([]api_types.Term, error) {
+	atomic.AddInt32(c.calls, 1)
+	return c.terms, nil
+}
+
+func term(id int, start, end time.Time) api_types.Term {
+	return api_types.Term{ID: id, Name: "term", StartDate: start.Format(dateLayout), EndDate: end.Format(dateLayout)}
+}
+
+type calendarFixture struct {
+	service *calendar.Service
+	cache   *infraCache.MemoryCacheService
+	calls   *int32
+}
+
+func newCalendarService(t *testing.T, terms []api_types.Term) *calendarFixture {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "calendar.sqlite")), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&auth.NetSchoolSession{}, &auth.SessionStudent{}))
+
+	sessions := database.NewSessionRepository(db)
+	require.NoError(t, sessions.Create(context.Background(), &auth.NetSchoolSession{
+		SessionID:    "session-1",
+		UserID:       "user-1",
+		ExpiresAt:    time.Now().Add(time.Hour),
+		NetSchoolURL: "https://sgo.rso23.ru",
+		APIType:      string(api_types.DevMockAPI),
+	}))
+
+	calls := new(int32)
+	mock, ok := api_types.DefaultRegistry.Lookup(api_types.DevMockAPI)
+	require.True(t, ok)
+	provider := *mock
+	provider.New = func(config api_types.APIConfig, httpClient *http.Client) (api_types.APIClientInterface, error) {
+		client, err := mock.New(config, httpClient)
+		return termsClient{APIClientInterface: client, terms: terms, calls: calls}, err
+	}
+	registry := api_types.NewProviderRegistry()
+	registry.MustRegister(provider)
+	factory, err := api_types.NewAPIClientFactory(registry, api_types.TransportConfig{})
+	require.NoError(t, err)
+
+	cacheService := infraCache.NewMemoryCacheService(100)
+	service := calendar.NewService(factory, sessions, cacheService, api_types.APIConfig{Mode: api_types.DevMockAPI}, nil)
+	return &calendarFixture{service: service, cache: cacheService, calls: calls}
+}
+
+func currentTerms() []api_types.Term {
+	now := time.Now()
+	return []api_types.Term{
+		term(1, now.AddDate(0, -4, 0), now.AddDate(0, -2, 0)),
+		term(2, now.AddDate(0, 0, -10), now.AddDate(0, 0, 10)),
+		term(3, now.AddDate(0, 2, 0), now.AddDate(0, 4, 0)),
+	}
+}
+
+func TestService_GetCalendarCachesPerSession(t *testing.T) {
+	fixture := newCalendarService(t, currentTerms())
+	ctx := context.Background()
+
+	cal, err := fixture.service.GetCalendar(ctx, "user-1", "session-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2025, cal.Year.ID)
+	assert.Len(t, cal.Years, 2)
+	assert.Len(t, cal.Terms, 3)
+	assert.Len(t, cal.Classes, 2)
+	assert.Equal(t, 2, cal.CurrentTermID)
+
+	_, err = fixture.service.GetCalendar(ctx, "user-1", "session-1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(fixture.calls))
+
+	var cached calendar.Calendar
+	found, err := fixture.cache.Get(ctx, calendar.CacheKey("user-1", "session-1"), &cached)
+	require.NoError(t, err)
+	assert.True(t, found)
+}
+
+func TestService_GetCalendarUnknownSession(t *testing.T) {
+	fixture := newCalendarService(t, currentTerms())
+
+	_, err := fixture.service.GetCalendar(context.Background(), "user-1", "missing")
+	assert.ErrorIs(t, err, auth.ErrSessionNotFound)
+}
+
+func TestService_ResolveTerm(t *testing.T) {
+	fixture := newCalendarService(t, currentTerms())
+	ctx := context.Background()
+
+	current, err := fixture.service.ResolveTerm(ctx, "user-1", "session-1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, current.ID)
+
+	explicit, err := fixture.service.ResolveTerm(ctx, "user-1", "session-1", 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, explicit.ID)
+
+	_, err = fixture.service.ResolveTerm(ctx, "user-1", "session-1", 99)
+	assert.ErrorIs(t, err, calendar.ErrUnknownTerm)
+	assert.Equal(t, api_types.ErrCodeDataValidationError, api_types.ErrorCodeOf(err))
+}
+
+func TestService_ResolveTermBetweenTerms(t *testing.T) {
+	now := time.Now()
+	fixture := newCalendarService(t, []api_types.Term{
+		term(1, now.AddDate(0, -4, 0), now.AddDate(0, -2, 0)),
+		term(2, now.AddDate(0, 2, 0), now.AddDate(0, 4, 0)),
+	})
+	ctx := context.Background()
+
+	_, err := fixture.service.ResolveTerm(ctx, "user-1", "session-1", 0)
+	assert.ErrorIs(t, err, calendar.ErrNoCurrentTerm)
+	assert.Equal(t, api_types.ErrCodeDataNotFound, api_types.ErrorCodeOf(err))
+
+	past, err := fixture.service.ResolveTerm(ctx, "user-1", "session-1", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, past.ID)
+}
@@ -0,0 +1,27 @@
+package directory
+
+
+
+
+var knownInstances = []Instance{
+	{Region: "Амурская область", URL: "https://region.obramur.ru"},
+	{Region: "Волгоградская область", URL: "https://sgo.volganet.ru"},
+	{Region: "Калужская область", URL: "https://edu.admoblkaluga.ru:444"},
+	{Region: "Костромская область", URL: "https://netschool.eduportal44.ru"},
+	{Region: "Краснодарский край", URL: "https://sgo.rso23.ru"},
+	{Region: "Приморский край", URL: "https://sgo.prim-edu.ru"},
+	{Region: "Республика Мордовия", URL: "https://sgo.e-mordovia.ru"},
+	{Region: "Томская область", URL: "https://sgo.tomedu.ru"},
+	{Region: "Тульская область", URL: "https://sgo1.edu71.ru"},
+	{Region: "Удмуртская Республика", URL: "https://es.ciur.ru"},
+	{Region: "Ульяновская область", URL: "https://sgo.cit73.ru"},
+	{Region: "Челябинская область", URL: "https://sgo.edu-74.ru"},
+	{Region: "Чувашская Республика", URL: "https://net-school.cap.ru"},
+}
+
+
+func KnownInstances() []Instance {
+	instances := make([]Instance, len(knownInstances))
+	copy(instances, knownInstances)
+	return instances
+}
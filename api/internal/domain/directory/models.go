@@ -0,0 +1,52 @@
+package directory
+
+import "netschool-proxy/api/api/internal/api_types"
+
+
+type Instance struct {
+	Region string `json:"region"`
+	URL    string `json:"url"`
+}
+
+
+type Directory struct {
+	InstanceURL    string                    `json:"instance_url"`
+	Municipalities []api_types.DirectoryItem `json:"municipalities"`
+	Cities         []api_types.DirectoryItem `json:"cities"`
+	SchoolTypes    []api_types.DirectoryItem `json:"school_types"`
+	Schools        []School                  `json:"schools"`
+}
+
+
+type School struct {
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	ShortName      string `json:"short_name,omitempty"`
+	Address        string `json:"address,omitempty"`
+	MunicipalityID int    `json:"municipality_id,omitempty"`
+	CityID         int    `json:"city_id,omitempty"`
+}
+
+
+type Filter struct {
+	MunicipalityID int
+	CityID         int
+	SchoolTypeID   int
+}
+
+
+
+func (d *Directory) Filter(filter Filter) *Directory {
+	filtered := *d
+	filtered.Schools = make([]School, 0, len(d.Schools))
+	for _, school := range d.Schools {
+		if filter.MunicipalityID != 0 && school.MunicipalityID != filter.MunicipalityID {
+			continue
+		}
+		if filter.CityID != 0 && school.CityID != filter.CityID {
+			continue
+		}
+		filtered.Schools = append(filtered.Schools, school)
+	}
+	return &filtered
+}
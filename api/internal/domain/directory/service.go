@@ -0,0 +1,102 @@
+package directory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/domain/cache"
+)
+
+
+const directoryTTL = 24 * time.Hour
+
+type Service struct {
+	apiClientFactory *api_types.APIClientFactory
+	cacheService     cache.CacheStrategy
+	config           api_types.APIConfig
+}
+
+func NewService(apiClientFactory *api_types.APIClientFactory, cacheService cache.CacheStrategy, config api_types.APIConfig) *Service {
+	return &Service{
+		apiClientFactory: apiClientFactory,
+		cacheService:     cacheService,
+		config:           config,
+	}
+}
+
+
+func (s *Service) Instances() []Instance {
+	return KnownInstances()
+}
+
+
+
+func (s *Service) GetDirectory(ctx context.Context, apiMode api_types.APIMode, instanceURL string, filter Filter) (*Directory, error) {
+	if apiMode == "" {
+		apiMode = s.config.Mode
+	}
+	instanceURL = strings.TrimRight(instanceURL, "/")
+	cacheKey := fmt.Sprintf("directory_%s_%s_%d", apiMode, instanceURL, filter.SchoolTypeID)
+
+	if s.cacheService != nil {
+		var cached Directory
+		found, err := s.cacheService.Get(ctx, cacheKey, &cached)
+		if err == nil && found {
+			return cached.Filter(filter), nil
+		}
+	}
+
+	if !s.apiClientFactory.Supports(apiMode, api_types.CapabilityDirectory) {
+		return nil, api_types.ErrDirectoryNotSupported
+	}
+
+	clientConfig := s.config
+	clientConfig.Mode = apiMode
+
+	apiClient, err := s.apiClientFactory.NewAPIClient(apiMode, clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	directoryClient, ok := api_types.UnwrapClient(apiClient).(api_types.SchoolDirectoryClient)
+	if !ok {
+		return nil, api_types.ErrDirectoryNotSupported
+	}
+
+	form, err := directoryClient.GetLoginForm(ctx, instanceURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login form: %w", err)
+	}
+
+	schools, err := directoryClient.GetSchoolDirectory(ctx, instanceURL, filter.SchoolTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schools: %w", err)
+	}
+
+	directory := &Directory{
+		InstanceURL:    instanceURL,
+		Municipalities: form.Provinces,
+		Cities:         form.Cities,
+		SchoolTypes:    form.SchoolTypes,
+		Schools:        make([]School, 0, len(schools)),
+	}
+	for _, school := range schools {
+		directory.Schools = append(directory.Schools, School{
+			ID:             school.ID,
+			Name:           school.Name,
+			ShortName:      school.ShortName,
+			Address:        school.Address,
+			MunicipalityID: school.ProvinceID,
+			CityID:         school.CityID,
+		})
+	}
+
+	if s.cacheService != nil {
+		s.cacheService.Set(ctx, cacheKey, directory, directoryTTL)
+	}
+
+	return directory.Filter(filter), nil
+}
@@ -5,6 +5,7 @@ type Grade struct {
 	ID          string `json:"id"`
 	StudentID   string `json:"student_id"`
 	SubjectID   string `json:"subject_id"`
+	SubjectName string `json:"subject_name,omitempty"`
 	Value       string `json:"value"`
 	Date        string `json:"date"`
 	Description string `json:"description"`
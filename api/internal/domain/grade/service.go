@@ -3,10 +3,12 @@ package grade
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"netschool-proxy/api/api/internal/api_types"
 	"netschool-proxy/api/api/internal/domain/auth"
+	"netschool-proxy/api/api/internal/domain/calendar"
 	"netschool-proxy/api/api/internal/domain/cache"
 )
 
@@ -15,36 +17,103 @@ type Service struct {
 	sessionRepo      auth.SessionRepository
 	cacheService     cache.CacheStrategy
 	config           api_types.APIConfig
+	refresher        *auth.SessionRefresher
+	calendar         *calendar.Service
+	policy           *cache.PolicyCache
 }
 
 
-func NewService(apiClientFactory *api_types.APIClientFactory, sessionRepo auth.SessionRepository, cacheService cache.CacheStrategy, config api_types.APIConfig) *Service {
+var studentGradesPolicy = cache.Policy{
+	FreshFor:      15 * time.Minute,
+	RevalidateFor: time.Hour,
+	ServeStaleFor: 24 * time.Hour,
+}
+
+
+func NewService(apiClientFactory *api_types.APIClientFactory, sessionRepo auth.SessionRepository, cacheService cache.CacheStrategy, config api_types.APIConfig, refresher *auth.SessionRefresher, calendarService *calendar.Service) *Service {
 	return &Service{
 		apiClientFactory: apiClientFactory,
 		sessionRepo:      sessionRepo,
 		cacheService:     cacheService,
 		config:           config,
+		refresher:        refresher,
+		calendar:         calendarService,
+		policy:           cache.NewPolicyCache(cacheService),
 	}
 }
 
-func (s *Service) GetGradesForStudent(ctx context.Context, userID, studentID, instanceURL string) ([]*Grade, error) {
-	
-	cacheKey := fmt.Sprintf("grades_student_%s_%s", userID, studentID)
-	var cachedGrades []*Grade
 
-	if s.cacheService != nil {
-		found, err := s.cacheService.Get(ctx, cacheKey, &cachedGrades)
-		if err == nil && found {
-			return cachedGrades, nil
+func DefaultPeriod(now time.Time) (time.Time, time.Time) {
+	weekStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -((int(now.Weekday())+6)%7))
+	return weekStart.AddDate(0, 0, -14), weekStart.AddDate(0, 0, 6)
+}
+
+
+func StudentGradesCacheKey(userID, sessionID, studentID string, startDate, endDate time.Time) string {
+	return cache.SessionKeyPrefix(userID, sessionID) + fmt.Sprintf("grades_student_%s_%s_%s", studentID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+}
+
+func (s *Service) GetGradesForStudent(ctx context.Context, userID, sessionID, studentID, instanceURL string, startDate, endDate time.Time) ([]*Grade, error) {
+	cacheKey := StudentGradesCacheKey(userID, sessionID, studentID, startDate, endDate)
+	fetch := func(ctx context.Context) (interface{}, error) {
+		session, err := s.sessionRepo.GetBySessionID(ctx, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user session: %w", err)
+		}
+		if session == nil {
+			return nil, auth.ErrSessionNotFound
 		}
+		return s.fetchGradesForStudent(ctx, session, studentID, instanceURL, startDate, endDate)
 	}
 
-	
-	session, err := s.sessionRepo.GetByUserID(ctx, userID)
+	var grades []*Grade
+	if _, err := s.policy.Fetch(ctx, cacheKey, studentGradesPolicy, &grades, fetch); err != nil {
+		return nil, err
+	}
+	return grades, nil
+}
+
+
+
+
+func (s *Service) GetJournal(ctx context.Context, userID, sessionID, studentID, instanceURL string, startDate, endDate time.Time) ([]*Grade, error) {
+	if startDate.IsZero() || endDate.IsZero() {
+		term, err := s.calendar.ResolveTerm(ctx, userID, sessionID, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve term: %w", err)
+		}
+		termStart, termEnd, err := term.Period()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve term: %w", err)
+		}
+		if startDate.IsZero() {
+			startDate = termStart
+		}
+		if endDate.IsZero() {
+			endDate = termEnd
+		}
+	}
+	return s.GetGradesForStudent(ctx, userID, sessionID, studentID, instanceURL, startDate, endDate)
+}
+
+
+
+
+func (s *Service) WarmSession(ctx context.Context, session *auth.NetSchoolSession) error {
+	instanceURL, err := api_types.NormalizeInstanceURL(session.NetSchoolURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user session: %w", err)
+		return err
 	}
 
+	startDate, endDate := DefaultPeriod(time.Now())
+	cacheKey := StudentGradesCacheKey(session.UserID, session.SessionID, session.StudentID, startDate, endDate)
+	_, err = s.policy.Refresh(ctx, cacheKey, studentGradesPolicy, nil, func(ctx context.Context) (interface{}, error) {
+		return s.fetchGradesForStudent(ctx, session, session.StudentID, instanceURL, startDate, endDate)
+	})
+	return err
+}
+
+func (s *Service) fetchGradesForStudent(ctx context.Context, session *auth.NetSchoolSession, studentID, instanceURL string, startDate, endDate time.Time) ([]*Grade, error) {
 	
 	apiMode := api_types.APIMode(session.APIType)
 	clientConfig := s.config
@@ -52,91 +121,19 @@ func (s *Service) GetGradesForStudent(ctx context.Context, userID, studentID, in
 
 	apiClient, err := s.apiClientFactory.NewAPIClient(apiMode, clientConfig)
 	if err != nil {
-		
-		if s.cacheService != nil {
-			var backupGrades []*Grade
-			_, err := s.cacheService.Get(ctx, cacheKey+"_backup", &backupGrades)
-			if err == nil {
-				return backupGrades, nil
-			}
-		}
 		return nil, fmt.Errorf("failed to create API client: %w", err)
 	}
 
 	
-	gradesData, err := apiClient.GetGrades(ctx, session.NetSchoolAccessToken, studentID, instanceURL)
+	ctx = api_types.WithSchoolYear(ctx, session.SchoolYearID())
+	gradesData, err := auth.CallWithRefresh(ctx, s.refresher, session, func() ([]api_types.Grade, error) {
+		return apiClient.GetGrades(ctx, session.NetSchoolAccessToken, studentID, instanceURL, startDate, endDate)
+	})
 	if err != nil {
-		
-		if s.cacheService != nil {
-			var backupGrades []*Grade
-			_, cacheErr := s.cacheService.Get(ctx, cacheKey+"_backup", &backupGrades)
-			if cacheErr == nil {
-				return backupGrades, nil
-			}
-		}
 		return nil, fmt.Errorf("failed to get grades from API: %w", err)
 	}
 
-	
-	gradesMap, ok := gradesData.(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid grades data format")
-	}
-
-	
-	grades := make([]*Grade, 0)
-	if gradesArray, exists := gradesMap["grades"].([]interface{}); exists {
-		for _, item := range gradesArray {
-			if itemMap, ok := item.(map[string]interface{}); ok {
-				grade := &Grade{
-					ID:          getStringValue(itemMap, "id", "unknown"),
-					StudentID:   getStringValue(itemMap, "student_id", studentID),
-					SubjectID:   getStringValue(itemMap, "subject_id", "unknown"),
-					Value:       getStringValue(itemMap, "value", "0"),
-					Date:        getStringValue(itemMap, "date", time.Now().Format("2006-01-02")),
-					Description: getStringValue(itemMap, "description", ""),
-					TeacherID:   getStringValue(itemMap, "teacher_id", ""),
-					Weight:      getIntValue(itemMap, "weight", 0),
-				}
-				grades = append(grades, grade)
-			}
-		}
-	} else {
-		
-		grades = []*Grade{
-			{
-				ID:          "grade_1",
-				StudentID:   studentID,
-				SubjectID:   "math",
-				Value:       "5",
-				Date:        "2023-09-15",
-				Description: "Контрольная работа",
-				TeacherID:   "teacher_1",
-				Weight:      10,
-			},
-			{
-				ID:          "grade_2",
-				StudentID:   studentID,
-				SubjectID:   "math",
-				Value:       "4",
-				Date:        "2023-09-10",
-				Description: "Самостоятельная работа",
-				TeacherID:   "teacher_1",
-				Weight:      5,
-			},
-		}
-	}
-
-	
-	if s.cacheService != nil {
-		
-		s.cacheService.Set(ctx, cacheKey, grades, 15*time.Minute)
-
-		
-		s.cacheService.Set(ctx, cacheKey+"_backup", grades, 24*time.Hour)
-	}
-
-	return grades, nil
+	return toGrades(gradesData, studentID), nil
 }
 
 
@@ -164,12 +161,15 @@ func (s *Service) DeleteGrade(ctx context.Context, gradeID string) error {
 	return nil
 }
 
-func (s *Service) GetGradesForSubject(ctx context.Context, userID, studentID, subjectID, instanceURL string, startDate, endDate time.Time, termID, classID int, transport *int) ([]*Grade, error) {
+func (s *Service) GetGradesForSubject(ctx context.Context, userID, sessionID, studentID, subjectID, instanceURL string, startDate, endDate time.Time, termID, classID int, transport *int) ([]*Grade, error) {
 	
-	session, err := s.sessionRepo.GetByUserID(ctx, userID)
+	session, err := s.sessionRepo.GetBySessionID(ctx, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user session: %w", err)
 	}
+	if session == nil {
+		return nil, auth.ErrSessionNotFound
+	}
 
 	
 	apiMode := api_types.APIMode(session.APIType)
@@ -182,68 +182,84 @@ func (s *Service) GetGradesForSubject(ctx context.Context, userID, studentID, su
 	}
 
 	
-	gradesData, err := apiClient.GetGradesForSubject(ctx, session.NetSchoolAccessToken, studentID, subjectID, instanceURL, startDate, endDate, termID, classID, transport)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get grades for subject from API: %w", err)
+	if termID == 0 || startDate.IsZero() || endDate.IsZero() {
+		term, err := s.calendar.ResolveTerm(ctx, userID, sessionID, termID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve term: %w", err)
+		}
+		termStart, termEnd, err := term.Period()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve term: %w", err)
+		}
+		termID = term.ID
+		if startDate.IsZero() {
+			startDate = termStart
+		}
+		if endDate.IsZero() {
+			endDate = termEnd
+		}
 	}
-
-	
-	gradesArray, ok := gradesData.([]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid grades data format")
+	if classID == 0 {
+		classID = sessionClassID(session, studentID)
 	}
 
-	grades := make([]*Grade, 0, len(gradesArray))
-	for _, item := range gradesArray {
-		itemMap, ok := item.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		grade := &Grade{
-			ID:          getStringValue(itemMap, "id", "unknown"),
-			StudentID:   getStringValue(itemMap, "student_id", studentID),
-			SubjectID:   getStringValue(itemMap, "subject_id", subjectID),
-			Value:       getStringValue(itemMap, "value", "0"),
-			Date:        getStringValue(itemMap, "date", time.Now().Format("2006-01-02")),
-			Description: getStringValue(itemMap, "description", ""),
-			TeacherID:   getStringValue(itemMap, "teacher_id", ""),
-			Weight:      getIntValue(itemMap, "weight", 0),
-		}
-		grades = append(grades, grade)
+	
+	ctx = api_types.WithSchoolYear(ctx, session.SchoolYearID())
+	gradesData, err := auth.CallWithRefresh(ctx, s.refresher, session, func() ([]api_types.Grade, error) {
+		return apiClient.GetGradesForSubject(ctx, session.NetSchoolAccessToken, studentID, subjectID, instanceURL, startDate, endDate, termID, classID, transport)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get grades for subject from API: %w", err)
 	}
 
-	return grades, nil
+	
+	return toGrades(gradesData, studentID), nil
 }
 
 
-func getStringValue(m map[string]interface{}, key, defaultValue string) string {
-	if val, ok := m[key]; ok {
-		if str, ok := val.(string); ok {
-			return str
+func sessionClassID(session *auth.NetSchoolSession, studentID string) int {
+	for _, linked := range session.Students {
+		if linked.StudentID == studentID {
+			classID, err := strconv.Atoi(linked.ClassID)
+			if err == nil {
+				return classID
+			}
 		}
 	}
-	return defaultValue
+	return 0
 }
 
-func getIntValue(m map[string]interface{}, key string, defaultValue int) int {
-	if val, ok := m[key]; ok {
-		if num, ok := val.(float64); ok {
-			return int(num)
-		}
-		if num, ok := val.(int); ok {
-			return num
+
+func toGrades(items []api_types.Grade, studentID string) []*Grade {
+	grades := make([]*Grade, 0, len(items))
+	for _, item := range items {
+		subjectID := item.SubjectName
+		if item.SubjectID != 0 {
+			subjectID = strconv.Itoa(item.SubjectID)
 		}
+		grades = append(grades, &Grade{
+			ID:          strconv.Itoa(item.AssignmentID),
+			StudentID:   studentID,
+			SubjectID:   subjectID,
+			SubjectName: item.SubjectName,
+			Value:       item.Mark,
+			Date:        item.Date,
+			Description: item.AssignmentName,
+			Weight:      item.Weight,
+		})
 	}
-	return defaultValue
+	return grades
 }
 
-func (s *Service) GetAssignmentTypes(ctx context.Context, userID, instanceURL string) ([]interface{}, error) {
+func (s *Service) GetAssignmentTypes(ctx context.Context, sessionID, instanceURL string) ([]api_types.AssignmentType, error) {
 	
-	session, err := s.sessionRepo.GetByUserID(ctx, userID)
+	session, err := s.sessionRepo.GetBySessionID(ctx, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user session: %w", err)
 	}
+	if session == nil {
+		return nil, auth.ErrSessionNotFound
+	}
 
 	
 	apiMode := api_types.APIMode(session.APIType)
@@ -256,25 +272,25 @@ func (s *Service) GetAssignmentTypes(ctx context.Context, userID, instanceURL st
 	}
 
 	
-	assignmentTypesData, err := apiClient.GetAssignmentTypes(ctx, session.NetSchoolAccessToken, instanceURL)
+	assignmentTypesData, err := auth.CallWithRefresh(ctx, s.refresher, session, func() ([]api_types.AssignmentType, error) {
+		return apiClient.GetAssignmentTypes(ctx, session.NetSchoolAccessToken, instanceURL)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get assignment types from API: %w", err)
 	}
 
-	assignmentTypesArray, ok := assignmentTypesData.([]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid assignment types data format")
-	}
-
-	return assignmentTypesArray, nil
+	return assignmentTypesData, nil
 }
 
-func (s *Service) GetAssignment(ctx context.Context, userID, studentID, assignmentID, instanceURL string) (interface{}, error) {
+func (s *Service) GetAssignment(ctx context.Context, sessionID, studentID, assignmentID, instanceURL string) (*api_types.AssignmentDetails, error) {
 	
-	session, err := s.sessionRepo.GetByUserID(ctx, userID)
+	session, err := s.sessionRepo.GetBySessionID(ctx, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user session: %w", err)
 	}
+	if session == nil {
+		return nil, auth.ErrSessionNotFound
+	}
 
 	
 	apiMode := api_types.APIMode(session.APIType)
@@ -287,7 +303,9 @@ func (s *Service) GetAssignment(ctx context.Context, userID, studentID, assignme
 	}
 
 	
-	assignmentData, err := apiClient.GetAssignment(ctx, session.NetSchoolAccessToken, studentID, assignmentID, instanceURL)
+	assignmentData, err := auth.CallWithRefresh(ctx, s.refresher, session, func() (*api_types.AssignmentDetails, error) {
+		return apiClient.GetAssignment(ctx, session.NetSchoolAccessToken, studentID, assignmentID, instanceURL)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get assignment from API: %w", err)
 	}
@@ -15,35 +15,77 @@ type Service struct {
 	sessionRepo      auth.SessionRepository
 	cacheService     cache.CacheStrategy
 	config           api_types.APIConfig
+	refresher        *auth.SessionRefresher
+	policy           *cache.PolicyCache
 }
 
-func NewService(apiClientFactory *api_types.APIClientFactory, sessionRepo auth.SessionRepository, cacheService cache.CacheStrategy, config api_types.APIConfig) *Service {
+
+var weeklyPolicy = cache.Policy{
+	FreshFor:      30 * time.Minute,
+	RevalidateFor: 2 * time.Hour,
+	ServeStaleFor: 24 * time.Hour,
+}
+
+func NewService(apiClientFactory *api_types.APIClientFactory, sessionRepo auth.SessionRepository, cacheService cache.CacheStrategy, config api_types.APIConfig, refresher *auth.SessionRefresher) *Service {
 	return &Service{
 		apiClientFactory: apiClientFactory,
 		sessionRepo:      sessionRepo,
 		cacheService:     cacheService,
 		config:           config,
+		refresher:        refresher,
+		policy:           cache.NewPolicyCache(cacheService),
 	}
 }
 
-func (s *Service) GetWeeklySchedule(ctx context.Context, userID, instanceURL string, weekStart time.Time) (interface{}, error) {
-	
-	cacheKey := fmt.Sprintf("schedule_weekly_%s_%s_%s", userID, instanceURL, weekStart.Format("2006-01-02"))
-	var cachedSchedule interface{}
 
-	if s.cacheService != nil {
-		found, err := s.cacheService.Get(ctx, cacheKey, &cachedSchedule)
-		if err == nil && found {
-			return cachedSchedule, nil
+func WeekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+
+func WeeklyCacheKey(userID, sessionID, studentID, instanceURL string, weekStart time.Time) string {
+	return cache.SessionKeyPrefix(userID, sessionID) + fmt.Sprintf("schedule_weekly_%s_%s_%s", studentID, instanceURL, weekStart.Format("2006-01-02"))
+}
+
+func (s *Service) GetWeeklySchedule(ctx context.Context, userID, sessionID, studentID, instanceURL string, weekStart time.Time) (*api_types.Diary, error) {
+	cacheKey := WeeklyCacheKey(userID, sessionID, studentID, instanceURL, weekStart)
+	fetch := func(ctx context.Context) (interface{}, error) {
+		session, err := s.sessionRepo.GetBySessionID(ctx, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user session: %w", err)
+		}
+		if session == nil {
+			return nil, auth.ErrSessionNotFound
 		}
+		return s.fetchWeeklySchedule(ctx, session, studentID, instanceURL, weekStart)
 	}
 
-	
-	session, err := s.sessionRepo.GetByUserID(ctx, userID)
+	var scheduleData *api_types.Diary
+	if _, err := s.policy.Fetch(ctx, cacheKey, weeklyPolicy, &scheduleData, fetch); err != nil {
+		return nil, err
+	}
+	return scheduleData, nil
+}
+
+
+
+
+func (s *Service) WarmSession(ctx context.Context, session *auth.NetSchoolSession) error {
+	instanceURL, err := api_types.NormalizeInstanceURL(session.NetSchoolURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user session: %w", err)
+		return err
 	}
 
+	weekStart := WeekStart(time.Now())
+	cacheKey := WeeklyCacheKey(session.UserID, session.SessionID, session.StudentID, instanceURL, weekStart)
+	_, err = s.policy.Refresh(ctx, cacheKey, weeklyPolicy, nil, func(ctx context.Context) (interface{}, error) {
+		return s.fetchWeeklySchedule(ctx, session, session.StudentID, instanceURL, weekStart)
+	})
+	return err
+}
+
+func (s *Service) fetchWeeklySchedule(ctx context.Context, session *auth.NetSchoolSession, studentID, instanceURL string, weekStart time.Time) (*api_types.Diary, error) {
 	
 	apiMode := api_types.APIMode(session.APIType)
 	clientConfig := s.config
@@ -51,47 +93,25 @@ func (s *Service) GetWeeklySchedule(ctx context.Context, userID, instanceURL str
 
 	apiClient, err := s.apiClientFactory.NewAPIClient(apiMode, clientConfig)
 	if err != nil {
-		
-		if s.cacheService != nil {
-			var backupSchedule interface{}
-			_, err := s.cacheService.Get(ctx, cacheKey+"_backup", &backupSchedule)
-			if err == nil {
-				return backupSchedule, nil
-			}
-		}
 		return nil, fmt.Errorf("failed to create API client: %w", err)
 	}
 
 	
-	scheduleData, err := apiClient.GetSchedule(ctx, session.NetSchoolAccessToken, instanceURL, weekStart)
+	ctx = api_types.WithSchoolYear(ctx, session.SchoolYearID())
+	scheduleData, err := auth.CallWithRefresh(ctx, s.refresher, session, func() (*api_types.Diary, error) {
+		return apiClient.GetSchedule(ctx, session.NetSchoolAccessToken, studentID, instanceURL, weekStart)
+	})
 	if err != nil {
-		
-		if s.cacheService != nil {
-			var backupSchedule interface{}
-			_, cacheErr := s.cacheService.Get(ctx, cacheKey+"_backup", &backupSchedule)
-			if cacheErr == nil {
-				return backupSchedule, nil
-			}
-		}
 		return nil, fmt.Errorf("failed to get schedule from API: %w", err)
 	}
 
-	
-	if s.cacheService != nil {
-		
-		s.cacheService.Set(ctx, cacheKey, scheduleData, 30*time.Minute)
-
-		
-		s.cacheService.Set(ctx, cacheKey+"_backup", scheduleData, 24*time.Hour)
-	}
-
 	return scheduleData, nil
 }
 
-func (s *Service) GetDailySchedule(ctx context.Context, userID, instanceURL string, date time.Time) (interface{}, error) {
+func (s *Service) GetDailySchedule(ctx context.Context, userID, sessionID, studentID, instanceURL string, date time.Time) (*api_types.Diary, error) {
 	
-	cacheKey := fmt.Sprintf("schedule_daily_%s_%s_%s", userID, instanceURL, date.Format("2006-01-02"))
-	var cachedSchedule interface{}
+	cacheKey := cache.SessionKeyPrefix(userID, sessionID) + fmt.Sprintf("schedule_daily_%s_%s_%s", studentID, instanceURL, date.Format("2006-01-02"))
+	var cachedSchedule *api_types.Diary
 
 	if s.cacheService != nil {
 		found, err := s.cacheService.Get(ctx, cacheKey, &cachedSchedule)
@@ -101,7 +121,7 @@ func (s *Service) GetDailySchedule(ctx context.Context, userID, instanceURL stri
 	}
 
 	
-	weeklySchedule, err := s.GetWeeklySchedule(ctx, userID, instanceURL, date.AddDate(0, 0, -int(date.Weekday())+1))
+	weeklySchedule, err := s.GetWeeklySchedule(ctx, userID, sessionID, studentID, instanceURL, WeekStart(date))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get daily schedule: %w", err)
 	}
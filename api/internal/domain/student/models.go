@@ -1,8 +1,22 @@
 package student
 
+import (
+	"errors"
+
+	"netschool-proxy/api/api/internal/api_types"
+)
+
+
+var ErrClassRosterNotSupported = errors.New("class roster is not available from NetSchool")
+
+func init() {
+	api_types.RegisterErrorCode(ErrClassRosterNotSupported, api_types.ErrCodeNotImplemented)
+}
+
 
 type Student struct {
 	ID        string `json:"id"`
+	Name      string `json:"name,omitempty"`
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	MiddleName string `json:"middle_name"`
@@ -12,6 +26,15 @@ type Student struct {
 }
 
 
+type LinkedStudent struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ClassID   string `json:"class_id,omitempty"`
+	ClassName string `json:"class_name,omitempty"`
+	Current   bool   `json:"current"`
+}
+
+
 type StudentService interface {
 	GetStudentInfo(studentID string) (*Student, error)
 	GetStudentsByClass(classID string) ([]*Student, error)
@@ -12,22 +12,45 @@ type Service struct {
 	apiClientFactory *api_types.APIClientFactory
 	sessionRepo      auth.SessionRepository
 	config           api_types.APIConfig
+	refresher        *auth.SessionRefresher
 }
 
-func NewService(apiClientFactory *api_types.APIClientFactory, sessionRepo auth.SessionRepository, config api_types.APIConfig) *Service {
+func NewService(apiClientFactory *api_types.APIClientFactory, sessionRepo auth.SessionRepository, config api_types.APIConfig, refresher *auth.SessionRefresher) *Service {
 	return &Service{
 		apiClientFactory: apiClientFactory,
 		sessionRepo:      sessionRepo,
 		config:           config,
+		refresher:        refresher,
 	}
 }
 
-func (s *Service) GetStudentInfo(ctx context.Context, userID, instanceURL string) (*Student, error) {
+
+
+
+func (s *Service) GetStudentInfo(ctx context.Context, sessionID, studentID, instanceURL string) (*Student, error) {
 	
-	session, err := s.sessionRepo.GetByUserID(ctx, userID)
+	session, err := s.sessionRepo.GetBySessionID(ctx, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user session: %w", err)
 	}
+	if session == nil {
+		return nil, auth.ErrSessionNotFound
+	}
+
+	
+	if studentID != "" && studentID != session.StudentID {
+		for _, linked := range session.Students {
+			if linked.StudentID == studentID {
+				return &Student{
+					ID:       linked.StudentID,
+					Name:     linked.Name,
+					Class:    linked.ClassName,
+					SchoolID: session.SchoolID,
+				}, nil
+			}
+		}
+		return nil, auth.ErrStudentNotLinked
+	}
 
 	
 	apiMode := api_types.APIMode(session.APIType)
@@ -40,89 +63,68 @@ func (s *Service) GetStudentInfo(ctx context.Context, userID, instanceURL string
 	}
 
 	
-	studentInfo, err := apiClient.GetStudentInfo(ctx, session.NetSchoolAccessToken, instanceURL)
+	studentInfo, err := auth.CallWithRefresh(ctx, s.refresher, session, func() (*api_types.MySettings, error) {
+		return apiClient.GetStudentInfo(ctx, session.NetSchoolAccessToken, instanceURL)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get student info from API: %w", err)
 	}
 
 	
-	studentData, ok := studentInfo.(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid student info format")
-	}
-
 	student := &Student{
-		ID:         getStringValue(studentData, "id", session.StudentID),
-		FirstName:  getStringValue(studentData, "first_name", "Иван"),
-		LastName:   getStringValue(studentData, "last_name", "Иванов"),
-		MiddleName: getStringValue(studentData, "middle_name", "Иванович"),
-		BirthDate:  getStringValue(studentData, "birth_date", "2005-01-01"),
-		Class:      getStringValue(studentData, "class", "9А"),
-		SchoolID:   getIntValue(studentData, "school_id", session.SchoolID),
+		ID:         session.StudentID,
+		FirstName:  studentInfo.FirstName,
+		LastName:   studentInfo.LastName,
+		MiddleName: studentInfo.MiddleName,
+		BirthDate:  studentInfo.BirthDate,
+		SchoolID:   session.SchoolID,
+	}
+	for _, linked := range session.Students {
+		if linked.StudentID == session.StudentID {
+			student.Name = linked.Name
+			student.Class = linked.ClassName
+			break
+		}
 	}
 
 	return student, nil
 }
 
-func (s *Service) GetStudentsByClass(ctx context.Context, userID, classID, instanceURL string) ([]*Student, error) {
-	
-	session, err := s.sessionRepo.GetByUserID(ctx, userID)
+
+func (s *Service) GetLinkedStudents(ctx context.Context, sessionID string) ([]*LinkedStudent, error) {
+	session, err := s.sessionRepo.GetBySessionID(ctx, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user session: %w", err)
 	}
+	if session == nil {
+		return nil, auth.ErrSessionNotFound
+	}
 
 	
-	apiMode := api_types.APIMode(session.APIType)
-	clientConfig := s.config
-	clientConfig.Mode = apiMode
-
-	apiClient, err := s.apiClientFactory.NewAPIClient(apiMode, clientConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create API client: %w", err)
+	if len(session.Students) == 0 {
+		return []*LinkedStudent{{ID: session.StudentID, Current: true}}, nil
 	}
-	_ = apiClient 
 
-	
-	
-	
-	students := []*Student{
-		{
-			ID:         session.StudentID,
-			FirstName:  "Иван",
-			LastName:   "Иванов",
-			MiddleName: "Иванович",
-			BirthDate:  "2005-01-01",
-			Class:      classID,
-			SchoolID:   session.SchoolID,
-		},
+	students := make([]*LinkedStudent, 0, len(session.Students))
+	for _, linked := range session.Students {
+		students = append(students, &LinkedStudent{
+			ID:        linked.StudentID,
+			Name:      linked.Name,
+			ClassID:   linked.ClassID,
+			ClassName: linked.ClassName,
+			Current:   linked.StudentID == session.StudentID,
+		})
 	}
 
 	return students, nil
 }
 
 
-func getStringValue(m map[string]interface{}, key, defaultValue string) string {
-	if val, ok := m[key]; ok {
-		if str, ok := val.(string); ok {
-			return str
-		}
-	}
-	return defaultValue
+func (s *Service) GetStudentsByClass(ctx context.Context, sessionID, classID, instanceURL string) ([]*Student, error) {
+	return nil, ErrClassRosterNotSupported
 }
 
-func getIntValue(m map[string]interface{}, key string, defaultValue int) int {
-	if val, ok := m[key]; ok {
-		if num, ok := val.(float64); ok {
-			return int(num)
-		}
-		if num, ok := val.(int); ok {
-			return num
-		}
-	}
-	return defaultValue
-}
-
-func (s *Service) UpdateStudentProfile(ctx context.Context, userID string, profile *Student) error {
+func (s *Service) UpdateStudentProfile(ctx context.Context, sessionID string, profile *Student) error {
 	
 	
 	
@@ -130,12 +132,15 @@ func (s *Service) UpdateStudentProfile(ctx context.Context, userID string, profi
 	return nil
 }
 
-func (s *Service) GetSchoolInfo(ctx context.Context, userID, instanceURL string) (map[string]interface{}, error) {
+func (s *Service) GetSchoolInfo(ctx context.Context, sessionID, instanceURL string) (*api_types.SchoolInfo, error) {
 	
-	session, err := s.sessionRepo.GetByUserID(ctx, userID)
+	session, err := s.sessionRepo.GetBySessionID(ctx, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user session: %w", err)
 	}
+	if session == nil {
+		return nil, auth.ErrSessionNotFound
+	}
 
 	
 	apiMode := api_types.APIMode(session.APIType)
@@ -148,25 +153,25 @@ func (s *Service) GetSchoolInfo(ctx context.Context, userID, instanceURL string)
 	}
 
 	
-	schoolInfo, err := apiClient.GetSchoolInfo(ctx, session.NetSchoolAccessToken, instanceURL)
+	schoolInfo, err := auth.CallWithRefresh(ctx, s.refresher, session, func() (*api_types.SchoolInfo, error) {
+		return apiClient.GetSchoolInfo(ctx, session.NetSchoolAccessToken, session.SchoolID, instanceURL)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get school info from API: %w", err)
 	}
 
-	schoolData, ok := schoolInfo.(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid school info format")
-	}
-
-	return schoolData, nil
+	return schoolInfo, nil
 }
 
-func (s *Service) GetClasses(ctx context.Context, userID, instanceURL string) ([]interface{}, error) {
+func (s *Service) GetClasses(ctx context.Context, sessionID, instanceURL string) ([]api_types.Class, error) {
 	
-	session, err := s.sessionRepo.GetByUserID(ctx, userID)
+	session, err := s.sessionRepo.GetBySessionID(ctx, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user session: %w", err)
 	}
+	if session == nil {
+		return nil, auth.ErrSessionNotFound
+	}
 
 	
 	apiMode := api_types.APIMode(session.APIType)
@@ -179,25 +184,25 @@ func (s *Service) GetClasses(ctx context.Context, userID, instanceURL string) ([
 	}
 
 	
-	classesData, err := apiClient.GetClasses(ctx, session.NetSchoolAccessToken, instanceURL)
+	classesData, err := auth.CallWithRefresh(ctx, s.refresher, session, func() ([]api_types.Class, error) {
+		return apiClient.GetClasses(ctx, session.NetSchoolAccessToken, instanceURL)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get classes from API: %w", err)
 	}
 
-	classesArray, ok := classesData.([]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid classes data format")
-	}
-
-	return classesArray, nil
+	return classesData, nil
 }
 
-func (s *Service) GetStudentPhoto(ctx context.Context, userID, studentID, instanceURL string) (interface{}, error) {
+func (s *Service) GetStudentPhoto(ctx context.Context, sessionID, studentID, instanceURL string) (interface{}, error) {
 	
-	session, err := s.sessionRepo.GetByUserID(ctx, userID)
+	session, err := s.sessionRepo.GetBySessionID(ctx, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user session: %w", err)
 	}
+	if session == nil {
+		return nil, auth.ErrSessionNotFound
+	}
 
 	
 	apiMode := api_types.APIMode(session.APIType)
@@ -210,7 +215,9 @@ func (s *Service) GetStudentPhoto(ctx context.Context, userID, studentID, instan
 	}
 
 	
-	photoData, err := apiClient.GetPhoto(ctx, session.NetSchoolAccessToken, studentID, instanceURL)
+	photoData, err := auth.CallWithRefresh(ctx, s.refresher, session, func() (interface{}, error) {
+		return apiClient.GetPhoto(ctx, session.NetSchoolAccessToken, studentID, instanceURL)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get student photo from API: %w", err)
 	}
@@ -1,28 +1,27 @@
 package browser
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"net/url"
 	"os/exec"
+	"strconv"
 	"time"
-	
+
 	"github.com/playwright-community/playwright-go"
+	"netschool-proxy/api/api/internal/api_types"
 )
 
 
 type BrowserAuthClientInterface interface {
-	
-	Authenticate(ctx context.Context, instanceURL, username, password string, schoolID int) (string, error)
-	
-	
+
+	Authenticate(ctx context.Context, tokenClient api_types.DeviceFlowClient, instanceURL, username, password string, schoolID int) (*api_types.OAuthToken, error)
+
+
 	IsAvailable() bool
-	
-	
+
+
 	Close() error
 }
 
@@ -30,330 +29,251 @@ type BrowserAuthClientInterface interface {
 var ErrBrowserAutomationNotSupported = errors.New("this instance does not support Playwright browser automation")
 
 
+type Config struct {
+	Headless     bool
+	Timeout      time.Duration
+	PollInterval time.Duration
+}
+
+
+var (
+	usernameSelector = "input[name='lg'], input[name='login'], input[name='username']"
+	passwordSelector = "input[name='pw'], input[name='password']"
+	schoolSelectors  = []string{"select[name='cl']", "select[name='school']", "select[name='schoolId']"}
+	submitSelector   = "button[type='submit'], .login-button, #login-btn"
+)
+
+
 
 type BrowserAuthClient struct {
+	config    Config
 	available bool
 	pw        *playwright.Playwright
 	browser   playwright.Browser
 }
 
 
-func NewBrowserAuthClient() (*BrowserAuthClient, error) {
-	
+func NewBrowserAuthClient(config Config) (*BrowserAuthClient, error) {
+	if config.Timeout <= 0 {
+		config.Timeout = 60 * time.Second
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 5 * time.Second
+	}
+
+
 	available := checkPlaywrightAvailability()
-	
+
 	client := &BrowserAuthClient{
+		config:    config,
 		available: available,
 	}
-	
+
 	if available {
-		
+
 		pw, err := playwright.Run()
 		if err != nil {
-			
+
 			client.available = false
 			return client, nil
 		}
-		
-		
+
+
 		browser, err := pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
-			Headless: playwright.Bool(true), 
+			Headless: playwright.Bool(config.Headless),
 		})
 		if err != nil {
 			pw.Stop()
 			client.available = false
 			return client, nil
 		}
-		
+
 		client.pw = pw
 		client.browser = browser
 	}
-	
+
 	return client, nil
 }
 
 
 func checkPlaywrightAvailability() bool {
-	
+
 	cmd := exec.Command("playwright", "--version")
 	if err := cmd.Run(); err != nil {
-		
+
 		cmd = exec.Command("node", "-e", "require('playwright')")
 		if err := cmd.Run(); err != nil {
 			return false
 		}
 	}
-	
+
 	return true
 }
 
 
-func (c *BrowserAuthClient) Authenticate(ctx context.Context, instanceURL, username, password string, schoolID int) (string, error) {
+
+
+func (c *BrowserAuthClient) Authenticate(ctx context.Context, tokenClient api_types.DeviceFlowClient, instanceURL, username, password string, schoolID int) (*api_types.OAuthToken, error) {
 	if !c.available {
-		return "", ErrBrowserAutomationNotSupported
+		return nil, ErrBrowserAutomationNotSupported
 	}
 
-	
-	page, err := c.browser.NewPage()
+	deviceCode, err := c.captureDeviceCode(ctx, instanceURL, username, password, schoolID)
 	if err != nil {
-		return "", fmt.Errorf("failed to create new page: %w", err)
+		return nil, err
 	}
-	defer page.Close()
 
-	
-	loginURL := fmt.Sprintf("%s/login?mobile", instanceURL)
+	return c.exchangeDeviceCode(ctx, tokenClient, instanceURL, deviceCode)
+}
 
-	
-	if _, err := page.Goto(loginURL, playwright.PageGotoOptions{
-		WaitUntil: playwright.WaitUntilStateNetworkidle,
-	}); err != nil {
-		return "", fmt.Errorf("failed to navigate to login page: %w", err)
+
+
+func (c *BrowserAuthClient) captureDeviceCode(ctx context.Context, instanceURL, username, password string, schoolID int) (string, error) {
+	page, deviceCodeChan, err := c.openLoginPage(instanceURL)
+	if err != nil {
+		return "", err
 	}
+	defer page.Close()
 
 	
-	element, err := page.WaitForSelector("input[name='lg'], input[name='login'], input[name='username']")
-	if err != nil {
+	if _, err := page.WaitForSelector(usernameSelector); err != nil {
 		return "", fmt.Errorf("login form not found: %w", err)
 	}
-	if element == nil {
-		return "", fmt.Errorf("login form element not found")
+
+	if err := page.Locator(usernameSelector).First().Fill(username); err != nil {
+		return "", fmt.Errorf("failed to fill username: %w", err)
+	}
+	if err := page.Locator(passwordSelector).First().Fill(password); err != nil {
+		return "", fmt.Errorf("failed to fill password: %w", err)
 	}
 
 	
-	if errStr := page.Fill("input[name='lg'], input[name='login'], input[name='username']", username).Error(); errStr != "" {
-
-		if errStr := page.Fill("input[name='login'], input[name='username']", username).Error(); errStr != "" {
-			return "", fmt.Errorf("failed to fill username: %s", errStr)
+	
+	schoolValue := []string{strconv.Itoa(schoolID)}
+	for _, selector := range schoolSelectors {
+		count, err := page.Locator(selector).Count()
+		if err != nil || count == 0 {
+			continue
+		}
+		if _, err := page.SelectOption(selector, playwright.SelectOptionValues{Values: &schoolValue}); err == nil {
+			break
 		}
 	}
 
-	if errStr := page.Fill("input[name='pw'], input[name='password']", password).Error(); errStr != "" {
-		return "", fmt.Errorf("failed to fill password: %s", errStr)
+	
+	if err := page.Locator(submitSelector).First().Click(); err != nil {
+		return "", fmt.Errorf("failed to click login button: %w", err)
 	}
 
 	
-	// TODO: Fix Playwright SelectOption API usage
-	// selectors := []string{"select[name='cl']", "select[name='school']", "select[name='schoolId']"}
-	// var selectErr string
-	// for _, selector := range selectors {
-	// 	_, err := page.SelectOption(selector, fmt.Sprintf("%d", schoolID))
-	// 	if err == nil {
-	// 		selectErr = ""
-	// 		break // Successfully selected an option
-	// 	} else {
-	// 		selectErr = err.Error()
-	// 	}
-	// }
-
-	// For now, simulate the action without actual Playwright call
-	var selectErr string = ""
-	// Skip school selection for now
-	if selectErr != "" {
-		// School selection might be optional or not required, so we don't return an error
+	if deviceCode := extractDeviceCodeFromURL(page.URL()); deviceCode != "" {
+		return deviceCode, nil
 	}
 
-	
-	loginButton := page.Locator("button[type='submit'], .login-button, #login-btn")
-	if errStr := loginButton.Click().Error(); errStr != "" {
-		return "", fmt.Errorf("failed to click login button: %s", errStr)
+	select {
+	case code := <-deviceCodeChan:
+		return code, nil
+	case <-time.After(c.config.Timeout):
+		return "", errors.New("timeout waiting for device code")
+	case <-ctx.Done():
+		return "", ctx.Err()
 	}
+}
 
-	
-	deviceCodeChan := make(chan string, 1)
-	errorChan := make(chan error, 1)
+
+
+func (c *BrowserAuthClient) openLoginPage(instanceURL string) (playwright.Page, <-chan string, error) {
+	page, err := c.browser.NewPage()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create new page: %w", err)
+	}
+
+	timeoutMs := float64(c.config.Timeout.Milliseconds())
+	page.SetDefaultTimeout(timeoutMs)
 
 	
+	deviceCodeChan := make(chan string, 1)
 	page.On("framenavigated", func(frame playwright.Frame) {
-		url := frame.URL()
-		
-		if deviceCode := extractDeviceCodeFromURL(url); deviceCode != "" {
-			deviceCodeChan <- deviceCode
+		if deviceCode := extractDeviceCodeFromURL(frame.URL()); deviceCode != "" {
+			select {
+			case deviceCodeChan <- deviceCode:
+			default:
+			}
 		}
 	})
 
 	
-	done := make(chan struct{})
-	var deviceCode string
-	var navErr error
-
-	
-	go func() {
-		defer close(done)
-		select {
-		case code := <-deviceCodeChan:
-			deviceCode = code
-		case err := <-errorChan:
-			navErr = err
-		case <-time.After(30 * time.Second): 
-			navErr = errors.New("timeout waiting for device code")
-		case <-ctx.Done():
-			navErr = ctx.Err()
-		}
-	}()
+	loginURL := fmt.Sprintf("%s/login?mobile", instanceURL)
 
 	
-	<-done
-
-	if navErr != nil {
-		return "", fmt.Errorf("failed to get device code: %w", navErr)
+	if _, err := page.Goto(loginURL, playwright.PageGotoOptions{
+		WaitUntil: playwright.WaitUntilStateNetworkidle,
+		Timeout:   playwright.Float(timeoutMs),
+	}); err != nil {
+		page.Close()
+		return nil, nil, fmt.Errorf("failed to navigate to login page: %w", err)
 	}
 
-	
-	return c.getTokenWithDeviceCode(ctx, deviceCode, instanceURL)
+	return page, deviceCodeChan, nil
 }
 
 
-func extractDeviceCodeFromURL(url string) string {
-	
-	
-	if len(url) < 10 {
+func extractDeviceCodeFromURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
 		return ""
 	}
 
-	
-	deviceCodeStart := "device_code="
-	pinCodeStart := "pincode="
-	
-	if idx := findSubstring(url, deviceCodeStart); idx != -1 {
-		startIdx := idx + len(deviceCodeStart)
-		endIdx := findNextChar(url, startIdx, '&')
-		if endIdx == -1 {
-			endIdx = len(url)
-		}
-		return url[startIdx:endIdx]
+	query := parsed.Query()
+	if code := query.Get("device_code"); code != "" {
+		return code
 	}
-
-	if idx := findSubstring(url, pinCodeStart); idx != -1 {
-		startIdx := idx + len(pinCodeStart)
-		endIdx := findNextChar(url, startIdx, '&')
-		if endIdx == -1 {
-			endIdx = len(url)
-		}
-		return url[startIdx:endIdx]
+	if code := query.Get("pincode"); code != "" {
+		return code
 	}
-	
-	return ""
-}
 
 
-func findSubstring(haystack, needle string) int {
-	for i := 0; i <= len(haystack)-len(needle); i++ {
-		match := true
-		for j := 0; j < len(needle); j++ {
-			if haystack[i+j] != needle[j] {
-				match = false
-				break
-			}
-		}
-		if match {
-			return i
+	if fragment, err := url.ParseQuery(parsed.Fragment); err == nil {
+		if code := fragment.Get("device_code"); code != "" {
+			return code
 		}
 	}
-	return -1
-}
-
-
-func findNextChar(s string, start int, char byte) int {
-	for i := start; i < len(s); i++ {
-		if s[i] == char {
-			return i
-		}
-	}
-	return -1
+	return ""
 }
 
 
-func (c *BrowserAuthClient) getTokenWithDeviceCode(ctx context.Context, deviceCode, instanceURL string) (string, error) {
-	client := &http.Client{Timeout: 30 * time.Second}
 
-	
-	tokenURL := "https://auth.edu.demogk.ru/oauth/token"
 
-	
-	tokenData := map[string]string{
-		"grant_type":    "urn:ietf:params:oauth:grant-type:device_code",
-		"device_code":   deviceCode,
-		"client_id":     "parent-mobile",
-		"client_secret": "04064338-13df-4747-8dea-69849f9ecdf0",
+func (c *BrowserAuthClient) exchangeDeviceCode(ctx context.Context, tokenClient api_types.DeviceFlowClient, instanceURL, deviceCode string) (*api_types.OAuthToken, error) {
+	if tokenClient == nil {
+		return nil, api_types.ErrDeviceFlowNotSupported
 	}
 
-	
-	maxAttempts := 100 
-	interval := 5 * time.Second 
+	interval := c.config.PollInterval
+	deadline := time.Now().Add(c.config.Timeout)
+
+	for {
+		token, err := tokenClient.PollDeviceToken(ctx, instanceURL, deviceCode)
+		switch {
+		case err == nil:
+			return token, nil
+		case errors.Is(err, api_types.ErrDeviceAuthorizationPending):
+		case errors.Is(err, api_types.ErrDeviceSlowDown):
+			interval += 5 * time.Second
+		default:
+			return nil, fmt.Errorf("failed to exchange device code: %w", err)
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			return nil, api_types.ErrDeviceCodeExpired
+		}
 
-	for attempt := 0; attempt < maxAttempts; attempt++ {
 		select {
 		case <-ctx.Done():
-			return "", ctx.Err()
+			return nil, ctx.Err()
 		case <-time.After(interval):
-			jsonData, err := json.Marshal(tokenData)
-			if err != nil {
-				return "", fmt.Errorf("failed to marshal token data: %w", err)
-			}
-
-			req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, bytes.NewBuffer(jsonData))
-			if err != nil {
-				return "", fmt.Errorf("failed to create token request: %w", err)
-			}
-
-			req.Header.Set("Content-Type", "application/json")
-			req.Header.Set("User-Agent", "NetSchoolApp/1.0")
-
-			resp, err := client.Do(req)
-			if err != nil {
-				return "", fmt.Errorf("failed to execute token request: %w", err)
-			}
-
-			body, err := io.ReadAll(resp.Body)
-			resp.Body.Close()
-			if err != nil {
-				return "", fmt.Errorf("failed to read token response: %w", err)
-			}
-
-			if resp.StatusCode == http.StatusOK {
-				
-				var tokenResp struct {
-					AccessToken  string `json:"access_token"`
-					TokenType    string `json:"token_type"`
-					ExpiresIn    int    `json:"expires_in"`
-					RefreshToken string `json:"refresh_token"`
-				}
-
-				if err := json.Unmarshal(body, &tokenResp); err != nil {
-					return "", fmt.Errorf("failed to parse token response: %w", err)
-				}
-
-				return tokenResp.AccessToken, nil
-			} else if resp.StatusCode == http.StatusBadRequest {
-				
-				var errorResp struct {
-					Error            string `json:"error"`
-					ErrorDescription string `json:"error_description"`
-				}
-
-				if err := json.Unmarshal(body, &errorResp); err != nil {
-					return "", fmt.Errorf("failed to parse error response: %w", err)
-				}
-
-				if errorResp.Error == "authorization_pending" {
-					
-					continue
-				} else if errorResp.Error == "slow_down" {
-					
-					interval += 5 * time.Second
-					continue
-				} else if errorResp.Error == "expired_token" {
-					return "", fmt.Errorf("device code expired: %s", errorResp.ErrorDescription)
-				} else {
-					return "", fmt.Errorf("token request failed: %s - %s", errorResp.Error, errorResp.ErrorDescription)
-				}
-			} else {
-				return "", fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, string(body))
-			}
 		}
 	}
-
-	return "", fmt.Errorf("max attempts exceeded for token polling")
 }
 
 
@@ -370,4 +290,4 @@ func (c *BrowserAuthClient) Close() error {
 		c.pw.Stop()
 	}
 	return nil
-}
\ No newline at end of file
+}
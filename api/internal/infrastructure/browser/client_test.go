@@ -0,0 +1,108 @@
+package browser_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/infrastructure/browser"
+)
+
+const loginFixture = `<!DOCTYPE html>
+<html>
+<body>
+	<form action="/login/submit" method="post">
+		<input name="lg" type="text">
+		<input name="pw" type="password">
+		<select name="cl">
+			<option value="1">Школа №1</option>
+			<option value="42">Школа №42</option>
+		</select>
+		<button type="submit">Войти</button>
+	</form>
+</body>
+</html>`
+
+type fakeTokenClient struct {
+	mu          sync.Mutex
+	instanceURL string
+	deviceCode  string
+	polls       int
+}
+
+func (f *fakeTokenClient) StartDeviceAuthorization(ctx context.Context, instanceURL string) (*api_types.DeviceAuthorization, error) {
+	return nil, api_types.ErrDeviceFlowNotSupported
+}
+
+func (f *fakeTokenClient) PollDeviceToken(ctx context.Context, instanceURL, deviceCode string) (*api_types.OAuthToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.instanceURL = instanceURL
+	f.deviceCode = deviceCode
+	f.polls++
+	if f.polls == 1 {
+		return nil, api_types.ErrDeviceAuthorizationPending
+	}
+	return &api_types.OAuthToken{AccessToken: "access", RefreshToken: "refresh", ExpiresIn: 3600}, nil
+}
+
+func TestBrowserAuthClient_AuthenticateAgainstFixture(t *testing.T) {
+	client, err := browser.NewBrowserAuthClient(browser.Config{Headless: true, Timeout: 20 * time.Second, PollInterval: 10 * time.Millisecond})
+	require.NoError(t, err)
+	defer client.Close()
+
+	if !client.IsAvailable() {
+		t.Skip("playwright is not installed")
+	}
+
+	var submitted url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(loginFixture))
+		case "/login/submit":
+			r.ParseForm()
+			submitted = r.PostForm
+			http.Redirect(w, r, "/login/done?device_code=fixture-code", http.StatusFound)
+		case "/login/done":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<html><body>ok</body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tokenClient := &fakeTokenClient{}
+	token, err := client.Authenticate(context.Background(), tokenClient, server.URL, "user", "secret", 42)
+	require.NoError(t, err)
+
+	assert.Equal(t, "access", token.AccessToken)
+	assert.Equal(t, "fixture-code", tokenClient.deviceCode)
+	assert.Equal(t, server.URL, tokenClient.instanceURL)
+	assert.Equal(t, 2, tokenClient.polls)
+	assert.Equal(t, "user", submitted.Get("lg"))
+	assert.Equal(t, "secret", submitted.Get("pw"))
+	assert.Equal(t, "42", submitted.Get("cl"))
+}
+
+func TestBrowserAuthClient_Unavailable(t *testing.T) {
+	client, err := browser.NewBrowserAuthClient(browser.Config{})
+	require.NoError(t, err)
+	defer client.Close()
+
+	if client.IsAvailable() {
+		t.Skip("playwright is installed")
+	}
+
+	_, err = client.Authenticate(context.Background(), &fakeTokenClient{}, "http://127.0.0.1", "user", "secret", 1)
+	assert.ErrorIs(t, err, browser.ErrBrowserAutomationNotSupported)
+}
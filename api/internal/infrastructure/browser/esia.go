@@ -0,0 +1,170 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+	"netschool-proxy/api/api/internal/api_types"
+)
+
+
+var (
+	esiaButtonSelector   = "#esia-login, a[href*='esia'], a[href*='gosuslugi'], button[data-provider='esia']"
+	esiaLoginSelector    = "input#login, input[name='login'], input[name='mobileOrEmail']"
+	esiaPasswordSelector = "input#password, input[name='password']"
+	esiaSubmitSelector   = "button#loginByPwdButton, button[type='submit']"
+	esiaErrorSelector    = ".login-error, .error-message, [data-esia-error]"
+	accountSelector      = "[data-account-id]"
+)
+
+
+const esiaStepInterval = 200 * time.Millisecond
+
+
+
+
+
+func (c *BrowserAuthClient) AuthenticateESIA(ctx context.Context, tokenClient api_types.DeviceFlowClient, instanceURL string, credentials api_types.ESIACredentials) (*api_types.OAuthToken, *api_types.ESIAAccount, error) {
+	if !c.available {
+		return nil, nil, ErrBrowserAutomationNotSupported
+	}
+
+	deviceCode, account, err := c.captureESIADeviceCode(ctx, instanceURL, credentials)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token, err := c.exchangeDeviceCode(ctx, tokenClient, instanceURL, deviceCode)
+	if err != nil {
+		return nil, nil, err
+	}
+	return token, account, nil
+}
+
+
+func (c *BrowserAuthClient) captureESIADeviceCode(ctx context.Context, instanceURL string, credentials api_types.ESIACredentials) (string, *api_types.ESIAAccount, error) {
+	page, deviceCodeChan, err := c.openLoginPage(instanceURL)
+	if err != nil {
+		return "", nil, err
+	}
+	defer page.Close()
+
+
+	if err := page.Locator(esiaButtonSelector).First().Click(); err != nil {
+		return "", nil, fmt.Errorf("ESIA login button not found: %w", err)
+	}
+
+	if _, err := page.WaitForSelector(esiaLoginSelector); err != nil {
+		return "", nil, fmt.Errorf("ESIA login form not found: %w", err)
+	}
+	if err := page.Locator(esiaLoginSelector).First().Fill(credentials.Login); err != nil {
+		return "", nil, fmt.Errorf("failed to fill ESIA login: %w", err)
+	}
+	if err := page.Locator(esiaPasswordSelector).First().Fill(credentials.Password); err != nil {
+		return "", nil, fmt.Errorf("failed to fill ESIA password: %w", err)
+	}
+	if err := page.Locator(esiaSubmitSelector).First().Click(); err != nil {
+		return "", nil, fmt.Errorf("failed to submit ESIA login: %w", err)
+	}
+
+
+
+	var chosen *api_types.ESIAAccount
+	deadline := time.Now().Add(c.config.Timeout)
+	for time.Now().Before(deadline) {
+		if deviceCode := extractDeviceCodeFromURL(page.URL()); deviceCode != "" {
+			return deviceCode, chosen, nil
+		}
+
+		select {
+		case code := <-deviceCodeChan:
+			return code, chosen, nil
+		case <-ctx.Done():
+			return "", nil, ctx.Err()
+		default:
+		}
+
+		if chosen == nil && sameHost(page.URL(), instanceURL) {
+			accounts, elements, err := linkedAccounts(page)
+			if err != nil {
+				return "", nil, err
+			}
+			if len(accounts) > 0 {
+				account, err := api_types.ChooseESIAAccount(accounts, credentials.Account)
+				if err != nil {
+					return "", nil, err
+				}
+				for i := range accounts {
+					if accounts[i].ID == account.ID {
+						if err := elements[i].Click(); err != nil {
+							return "", nil, fmt.Errorf("failed to choose NetSchool account: %w", err)
+						}
+						break
+					}
+				}
+				chosen = &account
+			}
+		} else if !sameHost(page.URL(), instanceURL) {
+			if count, err := page.Locator(esiaErrorSelector).Count(); err == nil && count > 0 {
+				return "", nil, api_types.ErrESIALoginFailed
+			}
+		}
+
+		select {
+		case code := <-deviceCodeChan:
+			return code, chosen, nil
+		case <-ctx.Done():
+			return "", nil, ctx.Err()
+		case <-time.After(esiaStepInterval):
+		}
+	}
+
+	return "", nil, errors.New("timeout waiting for ESIA login to complete")
+}
+
+
+
+func linkedAccounts(page playwright.Page) ([]api_types.ESIAAccount, []playwright.Locator, error) {
+	elements, err := page.Locator(accountSelector).All()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read linked accounts: %w", err)
+	}
+
+	accounts := make([]api_types.ESIAAccount, 0, len(elements))
+	for _, element := range elements {
+		id, _ := element.GetAttribute("data-account-id")
+		name, _ := element.GetAttribute("data-account-name")
+		if name == "" {
+			name, _ = element.InnerText()
+		}
+		school, _ := element.GetAttribute("data-school")
+		schoolID, _ := element.GetAttribute("data-school-id")
+
+		account := api_types.ESIAAccount{
+			ID:     strings.TrimSpace(id),
+			Name:   strings.TrimSpace(name),
+			School: strings.TrimSpace(school),
+		}
+		account.SchoolID, _ = strconv.Atoi(strings.TrimSpace(schoolID))
+		accounts = append(accounts, account)
+	}
+	return accounts, elements, nil
+}
+
+func sameHost(rawURL, instanceURL string) bool {
+	current, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	instance, err := url.Parse(instanceURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(current.Host, instance.Host)
+}
@@ -0,0 +1,137 @@
+package browser_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/infrastructure/browser"
+)
+
+
+
+type fakeESIA struct {
+	netschool *httptest.Server
+	esia      *httptest.Server
+	selected  string
+}
+
+func newFakeESIA(t *testing.T) *fakeESIA {
+	f := &fakeESIA{}
+
+	f.esia = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			if r.Method == http.MethodPost {
+				r.ParseForm()
+				if r.PostForm.Get("login") != "+79990000000" || r.PostForm.Get("password") != "secret" {
+					fmt.Fprint(w, `<html><body><div class="login-error">Неверный логин или пароль</div></body></html>`)
+					return
+				}
+				http.Redirect(w, r, r.URL.Query().Get("redirect_uri")+"?code=esia-code", http.StatusFound)
+				return
+			}
+			fmt.Fprintf(w, `<html><body>
+				<form method="post" action="/login?redirect_uri=%s">
+					<input id="login" name="login">
+					<input id="password" name="password" type="password">
+					<button type="submit">Войти</button>
+				</form>
+			</body></html>`, url.QueryEscape(r.URL.Query().Get("redirect_uri")))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	f.netschool = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch r.URL.Path {
+		case "/login":
+			fmt.Fprintf(w, `<html><body><a id="esia-login" href="%s/login?redirect_uri=%s">Госуслуги</a></body></html>`,
+				f.esia.URL, url.QueryEscape(f.netschool.URL+"/sso/esia/callback"))
+		case "/sso/esia/callback":
+			assert.Equal(t, "esia-code", r.URL.Query().Get("code"))
+			fmt.Fprint(w, `<html><body>
+				<a data-account-id="11" data-school="Школа №1" data-school-id="1" href="/sso/esia/select?account=11">Иванова Мария</a>
+				<a data-account-id="12" data-school="Школа №42" data-school-id="42" href="/sso/esia/select?account=12">Иванов Петр</a>
+			</body></html>`)
+		case "/sso/esia/select":
+			f.selected = r.URL.Query().Get("account")
+			http.Redirect(w, r, "/login/done?device_code=esia-device-"+f.selected, http.StatusFound)
+		case "/login/done":
+			fmt.Fprint(w, `<html><body>ok</body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	t.Cleanup(func() {
+		f.netschool.Close()
+		f.esia.Close()
+	})
+	return f
+}
+
+func newAvailableClient(t *testing.T) *browser.BrowserAuthClient {
+	client, err := browser.NewBrowserAuthClient(browser.Config{Headless: true, Timeout: 20 * time.Second, PollInterval: 10 * time.Millisecond})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	if !client.IsAvailable() {
+		t.Skip("playwright is not installed")
+	}
+	return client
+}
+
+func TestBrowserAuthClient_AuthenticateESIA(t *testing.T) {
+	client := newAvailableClient(t)
+	fake := newFakeESIA(t)
+
+	tokenClient := &fakeTokenClient{}
+	token, account, err := client.AuthenticateESIA(context.Background(), tokenClient, fake.netschool.URL, api_types.ESIACredentials{
+		Login:    "+79990000000",
+		Password: "secret",
+		Account:  "12",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "access", token.AccessToken)
+	assert.Equal(t, "12", fake.selected)
+	assert.Equal(t, "esia-device-12", tokenClient.deviceCode)
+	assert.Equal(t, fake.netschool.URL, tokenClient.instanceURL)
+	assert.Equal(t, api_types.ESIAAccount{ID: "12", Name: "Иванов Петр", School: "Школа №42", SchoolID: 42}, *account)
+}
+
+func TestBrowserAuthClient_AuthenticateESIARequiresAccountChoice(t *testing.T) {
+	client := newAvailableClient(t)
+	fake := newFakeESIA(t)
+
+	_, _, err := client.AuthenticateESIA(context.Background(), &fakeTokenClient{}, fake.netschool.URL, api_types.ESIACredentials{
+		Login:    "+79990000000",
+		Password: "secret",
+	})
+
+	var selection *api_types.ESIAAccountSelectionError
+	require.ErrorAs(t, err, &selection)
+	assert.Len(t, selection.Accounts, 2)
+	assert.Empty(t, fake.selected)
+}
+
+func TestBrowserAuthClient_AuthenticateESIAWrongPassword(t *testing.T) {
+	client := newAvailableClient(t)
+	fake := newFakeESIA(t)
+
+	_, _, err := client.AuthenticateESIA(context.Background(), &fakeTokenClient{}, fake.netschool.URL, api_types.ESIACredentials{
+		Login:    "+79990000000",
+		Password: "wrong",
+	})
+	assert.ErrorIs(t, err, api_types.ErrESIALoginFailed)
+}
@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"sync"
 	"time"
 )
@@ -38,12 +39,9 @@ func (m *MemoryCacheService) Get(ctx context.Context, key string, target interfa
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	
 	item, exists := m.data[key]
 	if !exists || item.IsExpired() {
-		if exists {
-			
-			delete(m.data, key)
-		}
 		return false, nil
 	}
 
@@ -94,6 +92,19 @@ func (m *MemoryCacheService) Delete(ctx context.Context, key string) error {
 }
 
 
+func (m *MemoryCacheService) DeletePrefix(ctx context.Context, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key := range m.data {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.data, key)
+		}
+	}
+	return nil
+}
+
+
 func (m *MemoryCacheService) Clear(ctx context.Context) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
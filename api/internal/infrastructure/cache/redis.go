@@ -59,6 +59,29 @@ func (r *RedisCacheService) Delete(ctx context.Context, key string) error {
 }
 
 
+
+func (r *RedisCacheService) DeletePrefix(ctx context.Context, prefix string) error {
+	iter := r.client.Scan(ctx, 0, prefix+"*", 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == 100 {
+			if err := r.client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		return r.client.Del(ctx, keys...).Err()
+	}
+	return nil
+}
+
+
 func (r *RedisCacheService) Clear(ctx context.Context) error {
 	return r.client.FlushDB(ctx).Err()
 }
@@ -0,0 +1,212 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"netschool-proxy/api/api/internal/domain/auth"
+	"netschool-proxy/api/api/internal/pkg/security"
+)
+
+
+
+
+type EncryptedSessionRepository struct {
+	repo   auth.SessionRepository
+	cipher *security.TokenCipher
+}
+
+func NewEncryptedSessionRepository(repo auth.SessionRepository, cipher *security.TokenCipher) *EncryptedSessionRepository {
+	return &EncryptedSessionRepository{repo: repo, cipher: cipher}
+}
+
+func (r *EncryptedSessionRepository) Create(ctx context.Context, session *auth.NetSchoolSession) error {
+	accessToken, refreshToken := session.NetSchoolAccessToken, session.RefreshToken
+
+	encryptedAccess, encryptedRefresh, err := r.encryptPair(accessToken, refreshToken)
+	if err != nil {
+		return err
+	}
+
+	session.NetSchoolAccessToken, session.RefreshToken = encryptedAccess, encryptedRefresh
+	err = r.repo.Create(ctx, session)
+	
+	session.NetSchoolAccessToken, session.RefreshToken = accessToken, refreshToken
+	return err
+}
+
+func (r *EncryptedSessionRepository) GetBySessionID(ctx context.Context, sessionID string) (*auth.NetSchoolSession, error) {
+	session, err := r.repo.GetBySessionID(ctx, sessionID)
+	if err != nil || session == nil {
+		return session, err
+	}
+	if err := r.decrypt(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (r *EncryptedSessionRepository) ListByUserID(ctx context.Context, userID string) ([]*auth.NetSchoolSession, error) {
+	sessions, err := r.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return sessions, r.decryptAll(sessions)
+}
+
+func (r *EncryptedSessionRepository) UpdateTokens(ctx context.Context, id int, accessToken, refreshToken string, tokenExpiresAt, expiresAt time.Time) error {
+	encryptedAccess, encryptedRefresh, err := r.encryptPair(accessToken, refreshToken)
+	if err != nil {
+		return err
+	}
+	return r.repo.UpdateTokens(ctx, id, encryptedAccess, encryptedRefresh, tokenExpiresAt, expiresAt)
+}
+
+func (r *EncryptedSessionRepository) Touch(ctx context.Context, id int, lastUsedAt time.Time) error {
+	return r.repo.Touch(ctx, id, lastUsedAt)
+}
+
+func (r *EncryptedSessionRepository) UpdateUserID(ctx context.Context, id int, userID string) error {
+	return r.repo.UpdateUserID(ctx, id, userID)
+}
+
+func (r *EncryptedSessionRepository) ListExpiring(ctx context.Context, before time.Time) ([]*auth.NetSchoolSession, error) {
+	sessions, err := r.repo.ListExpiring(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+	return sessions, r.decryptAll(sessions)
+}
+
+func (r *EncryptedSessionRepository) ListActive(ctx context.Context, usedSince time.Time) ([]*auth.NetSchoolSession, error) {
+	sessions, err := r.repo.ListActive(ctx, usedSince)
+	if err != nil {
+		return nil, err
+	}
+	return sessions, r.decryptAll(sessions)
+}
+
+func (r *EncryptedSessionRepository) DeleteBySessionID(ctx context.Context, userID, sessionID string) (bool, error) {
+	return r.repo.DeleteBySessionID(ctx, userID, sessionID)
+}
+
+func (r *EncryptedSessionRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return r.repo.DeleteByUserID(ctx, userID)
+}
+
+func (r *EncryptedSessionRepository) CleanupExpired(ctx context.Context) error {
+	return r.repo.CleanupExpired(ctx)
+}
+
+func (r *EncryptedSessionRepository) encryptPair(accessToken, refreshToken string) (string, string, error) {
+	encryptedAccess, err := r.cipher.Encrypt(accessToken)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt access token: %w", err)
+	}
+	encryptedRefresh, err := r.cipher.Encrypt(refreshToken)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt refresh token: %w", err)
+	}
+	return encryptedAccess, encryptedRefresh, nil
+}
+
+func (r *EncryptedSessionRepository) decrypt(session *auth.NetSchoolSession) error {
+	accessToken, err := r.cipher.Decrypt(session.NetSchoolAccessToken)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt access token of session %d: %w", session.ID, err)
+	}
+	refreshToken, err := r.cipher.Decrypt(session.RefreshToken)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt refresh token of session %d: %w", session.ID, err)
+	}
+	session.NetSchoolAccessToken, session.RefreshToken = accessToken, refreshToken
+	return nil
+}
+
+func (r *EncryptedSessionRepository) decryptAll(sessions []*auth.NetSchoolSession) error {
+	for _, session := range sessions {
+		if err := r.decrypt(session); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+
+type sessionTokenRow struct {
+	ID           int    `gorm:"column:id"`
+	AccessToken  string `gorm:"column:access_token"`
+	RefreshToken string `gorm:"column:refresh_token"`
+}
+
+
+
+
+func ReencryptSessionTokens(ctx context.Context, db *gorm.DB, cipher *security.TokenCipher, batchSize int, dryRun bool) (int, error) {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	updated := 0
+	lastID := 0
+	for {
+		var rows []sessionTokenRow
+		err := db.WithContext(ctx).
+			Table(auth.NetSchoolSession{}.TableName()).
+			Select("id, access_token, refresh_token").
+			Where("id > ?", lastID).
+			Order("id").
+			Limit(batchSize).
+			Find(&rows).Error
+		if err != nil {
+			return updated, fmt.Errorf("failed to read sessions: %w", err)
+		}
+		if len(rows) == 0 {
+			return updated, nil
+		}
+
+		for _, row := range rows {
+			lastID = row.ID
+			if !cipher.NeedsReencrypt(row.AccessToken) && !cipher.NeedsReencrypt(row.RefreshToken) {
+				continue
+			}
+
+			accessToken, err := reencrypt(cipher, row.AccessToken)
+			if err != nil {
+				return updated, fmt.Errorf("session %d access token: %w", row.ID, err)
+			}
+			refreshToken, err := reencrypt(cipher, row.RefreshToken)
+			if err != nil {
+				return updated, fmt.Errorf("session %d refresh token: %w", row.ID, err)
+			}
+
+			if !dryRun {
+				
+				result := db.WithContext(ctx).
+					Table(auth.NetSchoolSession{}.TableName()).
+					Where("id = ? AND access_token = ? AND refresh_token = ?", row.ID, row.AccessToken, row.RefreshToken).
+					Updates(map[string]interface{}{
+						"access_token":  accessToken,
+						"refresh_token": refreshToken,
+					})
+				if result.Error != nil {
+					return updated, fmt.Errorf("failed to update session %d: %w", row.ID, result.Error)
+				}
+				if result.RowsAffected == 0 {
+					continue
+				}
+			}
+			updated++
+		}
+	}
+}
+
+func reencrypt(cipher *security.TokenCipher, value string) (string, error) {
+	plaintext, err := cipher.Decrypt(value)
+	if err != nil {
+		return "", err
+	}
+	return cipher.Encrypt(plaintext)
+}
@@ -0,0 +1,308 @@
+package migrations
+
+import (
+	"bufio"
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sqlite/*.sql postgres/*.sql mysql/*.sql
+var files embed.FS
+
+const versionTable = "goose_db_version"
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      []string
+	Down    []string
+}
+
+type MigrationStatus struct {
+	Version int64
+	Name    string
+	Applied bool
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	dialect    string
+	migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB, dbType string) (*Migrator, error) {
+	dialect, err := dialectFor(dbType)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := Load(dialect)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: dialect, migrations: migrations}, nil
+}
+
+func dialectFor(dbType string) (string, error) {
+	switch dbType {
+	case "postgres":
+		return "postgres", nil
+	case "mysql", "mariadb":
+		return "mysql", nil
+	case "sqlite":
+		return "sqlite", nil
+	default:
+		return "", fmt.Errorf("unsupported database type for migrations: %s", dbType)
+	}
+}
+
+func Load(dialect string) ([]Migration, error) {
+	names, err := fs.Glob(files, dialect+"/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no migrations for dialect %q", dialect)
+	}
+
+	migrations := make([]Migration, 0, len(names))
+	seen := make(map[int64]string, len(names))
+	for _, name := range names {
+		base := strings.TrimSuffix(path.Base(name), ".sql")
+		prefix, title, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: file name must look like 001_name.sql", name)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: invalid version %q", name, prefix)
+		}
+		if other, exists := seen[version]; exists {
+			return nil, fmt.Errorf("migration %s: version %d is already used by %s", name, version, other)
+		}
+		seen[version] = name
+
+		content, err := files.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		up, down, err := parseMigration(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", name, err)
+		}
+		migrations = append(migrations, Migration{Version: version, Name: title, Up: up, Down: down})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func parseMigration(content string) (up, down []string, err error) {
+	var (
+		section *[]string
+		inBlock bool
+		buf     strings.Builder
+		hasSQL  bool
+		sawUp   bool
+	)
+
+	flush := func() {
+		if hasSQL && section != nil {
+			*section = append(*section, strings.TrimSpace(buf.String()))
+		}
+		buf.Reset()
+		hasSQL = false
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if annotation, ok := strings.CutPrefix(trimmed, "-- +goose "); ok {
+			switch strings.TrimSpace(annotation) {
+			case "Up":
+				flush()
+				section, sawUp = &up, true
+			case "Down":
+				flush()
+				section = &down
+			case "StatementBegin":
+				flush()
+				inBlock = true
+			case "StatementEnd":
+				flush()
+				inBlock = false
+			}
+			continue
+		}
+		if section == nil {
+			continue
+		}
+		if !inBlock && !hasSQL && (trimmed == "" || strings.HasPrefix(trimmed, "--")) {
+			continue
+		}
+
+		buf.WriteString(line)
+		buf.WriteString("\n")
+		if trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+			hasSQL = true
+		}
+		if !inBlock && strings.HasSuffix(trimmed, ";") {
+			flush()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if inBlock {
+		return nil, nil, fmt.Errorf("missing StatementEnd")
+	}
+	if !sawUp {
+		return nil, nil, fmt.Errorf("missing -- +goose Up annotation")
+	}
+	flush()
+	return up, down, nil
+}
+
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		statuses = append(statuses, MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+			Applied: applied[migration.Version],
+		})
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var version int64
+	for v := range applied {
+		if v > version {
+			version = v
+		}
+	}
+	return version, nil
+}
+
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if applied[migration.Version] {
+			continue
+		}
+		if err := m.apply(ctx, migration, migration.Up, true); err != nil {
+			return done, fmt.Errorf("migration %03d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+		if !applied[migration.Version] {
+			continue
+		}
+		if err := m.apply(ctx, migration, migration.Down, false); err != nil {
+			return done, fmt.Errorf("migration %03d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, migration Migration, statements []string, up bool) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		if up {
+			return tx.Exec("INSERT INTO "+versionTable+" (version_id, is_applied) VALUES (?, ?)", migration.Version, true).Error
+		}
+		return tx.Exec("DELETE FROM "+versionTable+" WHERE version_id = ?", migration.Version).Error
+	})
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int64]bool, error) {
+	if err := m.ensureVersionTable(ctx); err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		VersionID int64
+		IsApplied bool
+	}
+	if err := m.db.WithContext(ctx).Raw("SELECT version_id, is_applied FROM " + versionTable + " ORDER BY id DESC").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", versionTable, err)
+	}
+
+	applied := make(map[int64]bool)
+	seen := make(map[int64]bool)
+	for _, row := range rows {
+		if row.VersionID == 0 || seen[row.VersionID] {
+			continue
+		}
+		seen[row.VersionID] = true
+		if row.IsApplied {
+			applied[row.VersionID] = true
+		}
+	}
+	return applied, nil
+}
+
+func (m *Migrator) ensureVersionTable(ctx context.Context) error {
+	db := m.db.WithContext(ctx)
+	if db.Migrator().HasTable(versionTable) {
+		return nil
+	}
+
+	var ddl string
+	switch m.dialect {
+	case "sqlite":
+		ddl = "CREATE TABLE " + versionTable + " (id INTEGER PRIMARY KEY AUTOINCREMENT, version_id INTEGER NOT NULL, is_applied INTEGER NOT NULL, tstamp TIMESTAMP DEFAULT (datetime('now')))"
+	default:
+		ddl = "CREATE TABLE " + versionTable + " (id SERIAL NOT NULL, version_id BIGINT NOT NULL, is_applied BOOLEAN NOT NULL, tstamp TIMESTAMP NULL DEFAULT now(), PRIMARY KEY (id))"
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(ddl).Error; err != nil {
+			return fmt.Errorf("failed to create %s: %w", versionTable, err)
+		}
+		return tx.Exec("INSERT INTO "+versionTable+" (version_id, is_applied) VALUES (?, ?)", 0, true).Error
+	})
+}
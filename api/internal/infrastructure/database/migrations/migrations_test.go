@@ -0,0 +1,105 @@
+package migrations_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"netschool-proxy/api/api/internal/domain/auth"
+	"netschool-proxy/api/api/internal/infrastructure/database/migrations"
+)
+
+func TestLoad_DialectsShareVersions(t *testing.T) {
+	sqliteMigrations, err := migrations.Load("sqlite")
+	require.NoError(t, err)
+	require.NotEmpty(t, sqliteMigrations)
+
+	for _, dialect := range []string{"postgres", "mysql"} {
+		dialectMigrations, err := migrations.Load(dialect)
+		require.NoError(t, err, dialect)
+		require.Len(t, dialectMigrations, len(sqliteMigrations), dialect)
+
+		for i, migration := range dialectMigrations {
+			assert.Equal(t, sqliteMigrations[i].Version, migration.Version, dialect)
+			assert.Equal(t, sqliteMigrations[i].Name, migration.Name, dialect)
+			assert.Equal(t, len(sqliteMigrations[i].Up) == 0, len(migration.Up) == 0, "%s %s", dialect, migration.Name)
+			for _, statement := range append(migration.Up, migration.Down...) {
+				assert.NotContains(t, statement, "AUTOINCREMENT", "%s %s", dialect, migration.Name)
+			}
+		}
+	}
+
+	_, err = migrations.NewMigrator(nil, "oracle")
+	assert.Error(t, err)
+}
+
+func newSQLiteMigrator(t *testing.T) (*gorm.DB, *migrations.Migrator) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "migrate.sqlite")), &gorm.Config{})
+	require.NoError(t, err)
+
+	migrator, err := migrations.NewMigrator(db, "sqlite")
+	require.NoError(t, err)
+	return db, migrator
+}
+
+func TestMigrator_UpDownSQLite(t *testing.T) {
+	ctx := context.Background()
+	db, migrator := newSQLiteMigrator(t)
+	latest := migrator.Migrations()[len(migrator.Migrations())-1].Version
+
+	version, err := migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), version)
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(migrator.Migrations()))
+
+	version, err = migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, latest, version)
+
+	session := &auth.NetSchoolSession{
+		SessionID:    "session-1",
+		UserID:       "user-1",
+		ExpiresAt:    time.Now().Add(time.Hour),
+		NetSchoolURL: "https://sgo.rso23.ru",
+		APIType:      "ns-webapi",
+		Students:     []auth.SessionStudent{{StudentID: "1001", Name: "Анна"}},
+	}
+	require.NoError(t, db.Create(session).Error)
+	require.NoError(t, db.Create(&auth.NetSchoolSession{SessionID: "session-2", UserID: "user-1", ExpiresAt: time.Now()}).Error)
+	require.NoError(t, db.Create(&auth.ProxyRefreshToken{TokenHash: "hash", FamilyID: "family", SessionID: "session-1", UserID: "user-1", ExpiresAt: time.Now()}).Error)
+
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	rolledBack, err := migrator.Down(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, rolledBack, 2)
+	assert.Equal(t, latest, rolledBack[0].Version)
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	for _, status := range statuses {
+		assert.Equal(t, status.Version < latest-1, status.Applied, status.Name)
+	}
+
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, 2)
+
+	_, err = migrator.Down(ctx, len(migrator.Migrations()))
+	require.NoError(t, err)
+	version, err = migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), version)
+	assert.False(t, db.Migrator().HasTable("sessions"))
+}
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE sessions (
+    id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    access_token TEXT NOT NULL,
+    refresh_token TEXT NOT NULL,
+    expires_at DATETIME(3) NOT NULL,
+    netschool_url VARCHAR(255) NOT NULL,
+    school_id INT NOT NULL,
+    student_id VARCHAR(64) NOT NULL,
+    year_id VARCHAR(64) NOT NULL,
+    api_type VARCHAR(32) NOT NULL,
+    created_at DATETIME(3) DEFAULT CURRENT_TIMESTAMP(3),
+    updated_at DATETIME(3) DEFAULT CURRENT_TIMESTAMP(3),
+    UNIQUE KEY uq_sessions_user_id (user_id)
+) DEFAULT CHARSET=utf8mb4;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_sessions_user_id ON sessions(user_id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_sessions_expires_at ON sessions(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE sessions;
+-- +goose StatementEnd
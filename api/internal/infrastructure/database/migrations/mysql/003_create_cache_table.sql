@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE cache (
+    id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `key` VARCHAR(255) NOT NULL,
+    value LONGTEXT NOT NULL,
+    expires_at DATETIME(3) NOT NULL,
+    created_at DATETIME(3) DEFAULT CURRENT_TIMESTAMP(3),
+    updated_at DATETIME(3) DEFAULT CURRENT_TIMESTAMP(3),
+    UNIQUE KEY uq_cache_key (`key`)
+) DEFAULT CHARSET=utf8mb4;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_cache_key ON cache(`key`);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_cache_expires_at ON cache(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE cache;
+-- +goose StatementEnd
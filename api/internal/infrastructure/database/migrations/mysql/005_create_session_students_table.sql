@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE session_students (
+    id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    session_id INT NOT NULL,
+    student_id VARCHAR(64) NOT NULL,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    class_id VARCHAR(64) NOT NULL DEFAULT '',
+    class_name VARCHAR(255) NOT NULL DEFAULT '',
+    CONSTRAINT fk_session_students_session FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+) DEFAULT CHARSET=utf8mb4;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE UNIQUE INDEX idx_session_students_session_student ON session_students(session_id, student_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE session_students;
+-- +goose StatementEnd
@@ -0,0 +1,53 @@
+-- +goose Up
+-- Снимаем UNIQUE с user_id: у пользователя может быть несколько сессий (по одной на устройство)
+-- +goose StatementBegin
+ALTER TABLE sessions ADD COLUMN session_id VARCHAR(64) NULL;
+-- +goose StatementEnd
+
+-- Для существующих сессий session_id совпадает с id: так раньше заполнялся claim session_id в JWT
+-- +goose StatementBegin
+UPDATE sessions SET session_id = CAST(id AS CHAR);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions MODIFY session_id VARCHAR(64) NOT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions ADD UNIQUE KEY uq_sessions_session_id (session_id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions DROP INDEX uq_sessions_user_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions
+    ADD COLUMN device_name VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    ADD COLUMN last_used_at DATETIME(3) DEFAULT CURRENT_TIMESTAMP(3);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE sessions SET last_used_at = updated_at;
+-- +goose StatementEnd
+
+-- +goose Down
+-- Оставляем по одной (самой свежей) сессии на пользователя и возвращаем UNIQUE на user_id
+-- +goose StatementBegin
+DELETE FROM sessions WHERE id NOT IN (
+    SELECT latest_id FROM (SELECT MAX(id) AS latest_id FROM sessions GROUP BY user_id) AS latest
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions ADD UNIQUE KEY uq_sessions_user_id (user_id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions
+    DROP COLUMN last_used_at,
+    DROP COLUMN user_agent,
+    DROP COLUMN device_name,
+    DROP COLUMN session_id;
+-- +goose StatementEnd
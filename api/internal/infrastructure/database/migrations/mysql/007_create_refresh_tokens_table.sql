@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE refresh_tokens (
+    id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    token_hash VARCHAR(128) NOT NULL,
+    family_id VARCHAR(64) NOT NULL,
+    session_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    created_at DATETIME(3) DEFAULT CURRENT_TIMESTAMP(3),
+    expires_at DATETIME(3) NOT NULL,
+    used_at DATETIME(3) NULL,
+    revoked_at DATETIME(3) NULL,
+    UNIQUE KEY uq_refresh_tokens_token_hash (token_hash)
+) DEFAULT CHARSET=utf8mb4;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_refresh_tokens_user_session ON refresh_tokens(user_id, session_id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE refresh_tokens;
+-- +goose StatementEnd
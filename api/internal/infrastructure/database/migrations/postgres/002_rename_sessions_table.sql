@@ -0,0 +1,6 @@
+-- +goose Up
+-- Ранние установки создавали таблицу net_school_sessions через AutoMigrate. Начиная с 001 таблица
+-- сразу называется sessions, а переименование падало на чистой базе, поэтому миграция оставлена
+-- пустой только для сохранения нумерации.
+
+-- +goose Down
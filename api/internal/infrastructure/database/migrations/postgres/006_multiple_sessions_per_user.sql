@@ -0,0 +1,64 @@
+-- +goose Up
+-- Снимаем UNIQUE с user_id: у пользователя может быть несколько сессий (по одной на устройство)
+-- +goose StatementBegin
+ALTER TABLE sessions ADD COLUMN session_id TEXT;
+-- +goose StatementEnd
+
+-- Для существующих сессий session_id совпадает с id: так раньше заполнялся claim session_id в JWT
+-- +goose StatementBegin
+UPDATE sessions SET session_id = CAST(id AS TEXT);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions ALTER COLUMN session_id SET NOT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions ADD CONSTRAINT sessions_session_id_key UNIQUE (session_id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions DROP CONSTRAINT sessions_user_id_key;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions ADD COLUMN device_name TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions ADD COLUMN user_agent TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions ADD COLUMN last_used_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE sessions SET last_used_at = updated_at;
+-- +goose StatementEnd
+
+-- +goose Down
+-- Оставляем по одной (самой свежей) сессии на пользователя и возвращаем UNIQUE на user_id
+-- +goose StatementBegin
+DELETE FROM sessions WHERE id NOT IN (SELECT MAX(id) FROM sessions GROUP BY user_id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions ADD CONSTRAINT sessions_user_id_key UNIQUE (user_id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN last_used_at;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN user_agent;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN device_name;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN session_id;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sessions ADD COLUMN token_expires_at TEXT;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE sessions SET token_expires_at = expires_at WHERE token_expires_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_sessions_token_expires_at ON sessions(token_expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_sessions_token_expires_at;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN token_expires_at;
+-- +goose StatementEnd
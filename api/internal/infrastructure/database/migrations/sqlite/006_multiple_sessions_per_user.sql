@@ -0,0 +1,84 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Пересоздаем таблицу без UNIQUE на user_id: у пользователя может быть несколько сессий (по одной на устройство)
+CREATE TABLE sessions_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL UNIQUE,
+    user_id TEXT NOT NULL,
+    access_token TEXT NOT NULL,
+    refresh_token TEXT NOT NULL,
+    expires_at TEXT NOT NULL,
+    token_expires_at TEXT,
+    netschool_url TEXT NOT NULL DEFAULT 'https://sgo.rso23.ru',
+    school_id INTEGER NOT NULL,
+    student_id TEXT NOT NULL,
+    year_id TEXT NOT NULL,
+    api_type TEXT NOT NULL DEFAULT 'ns-webapi',
+    device_name TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP,
+    updated_at TEXT DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TEXT DEFAULT CURRENT_TIMESTAMP
+);
+-- Для существующих сессий session_id совпадает с id: так раньше заполнялся claim session_id в JWT
+INSERT INTO sessions_new (id, session_id, user_id, access_token, refresh_token, expires_at, token_expires_at,
+    netschool_url, school_id, student_id, year_id, api_type, created_at, updated_at, last_used_at)
+SELECT id, CAST(id AS TEXT), user_id, access_token, refresh_token, expires_at, token_expires_at,
+    netschool_url, school_id, student_id, year_id, api_type, created_at, updated_at, updated_at
+FROM sessions;
+DROP TABLE sessions;
+ALTER TABLE sessions_new RENAME TO sessions;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_sessions_user_id ON sessions(user_id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_sessions_expires_at ON sessions(expires_at);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_sessions_token_expires_at ON sessions(token_expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Оставляем по одной (самой свежей) сессии на пользователя и возвращаем UNIQUE на user_id
+CREATE TABLE sessions_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL UNIQUE,
+    access_token TEXT NOT NULL,
+    refresh_token TEXT NOT NULL,
+    expires_at TEXT NOT NULL,
+    token_expires_at TEXT,
+    netschool_url TEXT NOT NULL DEFAULT 'https://sgo.rso23.ru',
+    school_id INTEGER NOT NULL,
+    student_id TEXT NOT NULL,
+    year_id TEXT NOT NULL,
+    api_type TEXT NOT NULL DEFAULT 'ns-webapi',
+    created_at TEXT DEFAULT CURRENT_TIMESTAMP,
+    updated_at TEXT DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO sessions_old (id, user_id, access_token, refresh_token, expires_at, token_expires_at,
+    netschool_url, school_id, student_id, year_id, api_type, created_at, updated_at)
+SELECT id, user_id, access_token, refresh_token, expires_at, token_expires_at,
+    netschool_url, school_id, student_id, year_id, api_type, created_at, updated_at
+FROM sessions
+WHERE id IN (SELECT MAX(id) FROM sessions GROUP BY user_id);
+DELETE FROM session_students WHERE session_id NOT IN (SELECT id FROM sessions_old);
+DROP TABLE sessions;
+ALTER TABLE sessions_old RENAME TO sessions;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_sessions_user_id ON sessions(user_id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_sessions_expires_at ON sessions(expires_at);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_sessions_token_expires_at ON sessions(token_expires_at);
+-- +goose StatementEnd
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"netschool-proxy/api/api/internal/domain/auth"
+)
+
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *auth.ProxyRefreshToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*auth.ProxyRefreshToken, error) {
+	var token auth.ProxyRefreshToken
+	result := r.db.WithContext(ctx).
+		Where("token_hash = ?", tokenHash).
+		First(&token)
+
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+
+	return &token, nil
+}
+
+func (r *RefreshTokenRepository) MarkUsed(ctx context.Context, id int, usedAt time.Time) (bool, error) {
+	
+	result := r.db.WithContext(ctx).
+		Model(&auth.ProxyRefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("used_at", usedAt)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&auth.ProxyRefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", revokedAt).Error
+}
+
+func (r *RefreshTokenRepository) RevokeBySessionID(ctx context.Context, userID, sessionID string, revokedAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&auth.ProxyRefreshToken{}).
+		Where("user_id = ? AND session_id = ? AND revoked_at IS NULL", userID, sessionID).
+		Update("revoked_at", revokedAt).Error
+}
+
+func (r *RefreshTokenRepository) RevokeByUserID(ctx context.Context, userID string, revokedAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&auth.ProxyRefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", revokedAt).Error
+}
+
+func (r *RefreshTokenRepository) ReassignSession(ctx context.Context, sessionID, userID string) error {
+	return r.db.WithContext(ctx).
+		Model(&auth.ProxyRefreshToken{}).
+		Where("session_id = ?", sessionID).
+		Update("user_id", userID).Error
+}
+
+func (r *RefreshTokenRepository) CleanupExpired(ctx context.Context) error {
+	return r.db.WithContext(ctx).
+		Where("expires_at < ?", time.Now()).
+		Delete(&auth.ProxyRefreshToken{}).Error
+}
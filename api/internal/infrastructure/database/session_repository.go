@@ -17,27 +17,28 @@ func NewSessionRepository(db *gorm.DB) *SessionRepository {
 }
 
 func (r *SessionRepository) Create(ctx context.Context, session *auth.NetSchoolSession) error {
-	
-	var existingSession auth.NetSchoolSession
-	result := r.db.Where("user_id = ?", session.UserID).First(&existingSession)
-
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			
-			return r.db.WithContext(ctx).Create(session).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		
+		if err := tx.Omit("Students").Create(session).Error; err != nil {
+			return err
 		}
-		return result.Error
-	}
 
-	
-	session.ID = existingSession.ID 
-	return r.db.WithContext(ctx).Save(session).Error
+		for i := range session.Students {
+			session.Students[i].ID = 0
+			session.Students[i].SessionID = session.ID
+		}
+		if len(session.Students) > 0 {
+			return tx.Create(&session.Students).Error
+		}
+		return nil
+	})
 }
 
-func (r *SessionRepository) GetByUserID(ctx context.Context, userID string) (*auth.NetSchoolSession, error) {
+func (r *SessionRepository) GetBySessionID(ctx context.Context, sessionID string) (*auth.NetSchoolSession, error) {
 	var session auth.NetSchoolSession
 	result := r.db.WithContext(ctx).
-		Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Preload("Students").
+		Where("session_id = ? AND expires_at > ?", sessionID, time.Now()).
 		First(&session)
 
 	if result.Error != nil {
@@ -50,14 +51,93 @@ func (r *SessionRepository) GetByUserID(ctx context.Context, userID string) (*au
 	return &session, nil
 }
 
-func (r *SessionRepository) Delete(ctx context.Context, userID string) error {
+func (r *SessionRepository) ListByUserID(ctx context.Context, userID string) ([]*auth.NetSchoolSession, error) {
+	var sessions []*auth.NetSchoolSession
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Order("created_at").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *SessionRepository) UpdateTokens(ctx context.Context, id int, accessToken, refreshToken string, tokenExpiresAt, expiresAt time.Time) error {
 	return r.db.WithContext(ctx).
-		Where("user_id = ?", userID).
-		Delete(&auth.NetSchoolSession{}).Error
+		Model(&auth.NetSchoolSession{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"access_token":     accessToken,
+			"refresh_token":    refreshToken,
+			"token_expires_at": tokenExpiresAt,
+			"expires_at":       expiresAt,
+			"updated_at":       time.Now(),
+		}).Error
 }
 
-func (r *SessionRepository) CleanupExpired(ctx context.Context) error {
+func (r *SessionRepository) Touch(ctx context.Context, id int, lastUsedAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&auth.NetSchoolSession{}).
+		Where("id = ?", id).
+		Update("last_used_at", lastUsedAt).Error
+}
+
+func (r *SessionRepository) UpdateUserID(ctx context.Context, id int, userID string) error {
 	return r.db.WithContext(ctx).
-		Where("expires_at < ?", time.Now()).
-		Delete(&auth.NetSchoolSession{}).Error
-}
\ No newline at end of file
+		Model(&auth.NetSchoolSession{}).
+		Where("id = ?", id).
+		Update("user_id", userID).Error
+}
+
+func (r *SessionRepository) ListExpiring(ctx context.Context, before time.Time) ([]*auth.NetSchoolSession, error) {
+	var sessions []*auth.NetSchoolSession
+	err := r.db.WithContext(ctx).
+		Where("refresh_token <> '' AND token_expires_at < ? AND expires_at > ?", before, time.Now()).
+		Find(&sessions).Error
+	return sessions, err
+}
+
+
+
+func (r *SessionRepository) ListActive(ctx context.Context, usedSince time.Time) ([]*auth.NetSchoolSession, error) {
+	var sessions []*auth.NetSchoolSession
+	err := r.db.WithContext(ctx).
+		Where("last_used_at >= ? AND expires_at > ?", usedSince, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+
+func (r *SessionRepository) DeleteBySessionID(ctx context.Context, userID, sessionID string) (bool, error) {
+	var deleted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		scope := tx.Model(&auth.NetSchoolSession{}).Select("id").Where("user_id = ? AND session_id = ?", userID, sessionID)
+		if err := tx.Where("session_id IN (?)", scope).Delete(&auth.SessionStudent{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("user_id = ? AND session_id = ?", userID, sessionID).Delete(&auth.NetSchoolSession{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted > 0, err
+}
+
+func (r *SessionRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id IN (?)", tx.Model(&auth.NetSchoolSession{}).Select("id").Where("user_id = ?", userID)).
+			Delete(&auth.SessionStudent{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&auth.NetSchoolSession{}).Error
+	})
+}
+
+func (r *SessionRepository) CleanupExpired(ctx context.Context) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id IN (?)", tx.Model(&auth.NetSchoolSession{}).Select("id").Where("expires_at < ?", now)).
+			Delete(&auth.SessionStudent{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("expires_at < ?", now).Delete(&auth.NetSchoolSession{}).Error
+	})
+}
@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/domain/auth"
+)
+
+type AdminHandler struct {
+	authService *auth.Service
+}
+
+func NewAdminHandler(authService *auth.Service) *AdminHandler {
+	return &AdminHandler{authService: authService}
+}
+
+type RevokeTokenRequest struct {
+	TokenID   string    `json:"jti" binding:"required"`
+	ExpiresAt time.Time `json:"expires_at"` 
+}
+
+
+
+
+
+
+
+func (h *AdminHandler) RevokeToken(c *gin.Context) {
+	var req RevokeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, api_types.WithDefaultCode(err, api_types.ErrCodeBadRequest))
+		return
+	}
+
+	if err := h.authService.RevokeAccessToken(c.Request.Context(), req.TokenID, req.ExpiresAt); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token revoked"})
+}
+
+
+
+
+
+
+func (h *AdminHandler) RevokeSession(c *gin.Context) {
+	if err := h.authService.RevokeSessionByID(c.Request.Context(), c.Param("session_id")); err != nil {
+		if errors.Is(err, auth.ErrSessionNotFound) {
+			respondError(c, api_types.WithDefaultCode(err, api_types.ErrCodeNotFound))
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+
+
+
+
+
+
+func (h *AdminHandler) RevokeUser(c *gin.Context) {
+	if err := h.authService.LogoutAll(c.Request.Context(), c.Param("user_id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "All user tokens revoked"})
+}
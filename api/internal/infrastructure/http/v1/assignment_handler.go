@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"netschool-proxy/api/api/internal/api_types"
 	"netschool-proxy/api/api/internal/domain/grade"
 )
 
@@ -34,42 +35,28 @@ func NewAssignmentHandler(gradeService *grade.Service) *AssignmentHandler {
 func (h *AssignmentHandler) GetAssignment(c *gin.Context) {
 	assignmentID := c.Query("assignment_id")
 	if assignmentID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "assignment_id is required"})
+		respondError(c, api_types.Errorf(api_types.ErrCodeBadRequest, "assignment_id is required"))
 		return
 	}
 
 	
-	studentID := c.Query("student_id")
-	if studentID == "" {
-		
-		userID, exists := c.Get("userID")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-			return
-		}
-		studentID = userID.(string)
+	studentID, ok := resolveStudentID(c)
+	if !ok {
+		return
 	}
 
-	userID, exists := c.Get("userID")
+	sessionID, exists := c.Get("sessionID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "User not authenticated"))
 		return
 	}
 
 	
-	instanceURL := c.Query("instance_url")
-	if instanceURL == "" {
-		
-		instanceURL = c.GetHeader("X-Instance-URL")
-		if instanceURL == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "instance_url is required"})
-			return
-		}
-	}
+	instanceURL := c.GetString("instanceURL")
 
-	assignment, err := h.gradeService.GetAssignment(c.Request.Context(), userID.(string), studentID, assignmentID, instanceURL)
+	assignment, err := h.gradeService.GetAssignment(c.Request.Context(), sessionID.(string), studentID, assignmentID, instanceURL)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -89,26 +76,18 @@ func (h *AssignmentHandler) GetAssignment(c *gin.Context) {
 
 
 func (h *AssignmentHandler) GetAssignmentTypes(c *gin.Context) {
-	userID, exists := c.Get("userID")
+	sessionID, exists := c.Get("sessionID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "User not authenticated"))
 		return
 	}
 
 	
-	instanceURL := c.Query("instance_url")
-	if instanceURL == "" {
-		
-		instanceURL = c.GetHeader("X-Instance-URL")
-		if instanceURL == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "instance_url is required"})
-			return
-		}
-	}
+	instanceURL := c.GetString("instanceURL")
 
-	assignmentTypes, err := h.gradeService.GetAssignmentTypes(c.Request.Context(), userID.(string), instanceURL)
+	assignmentTypes, err := h.gradeService.GetAssignmentTypes(c.Request.Context(), sessionID.(string), instanceURL)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
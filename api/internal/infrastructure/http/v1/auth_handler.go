@@ -1,12 +1,18 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"netschool-proxy/api/api/internal/api_types"
 	"netschool-proxy/api/api/internal/domain/auth"
 )
 
+const maxDeviceLoginWait = 8 * time.Second
+
 type AuthHandler struct {
 	authService *auth.Service
 }
@@ -17,14 +23,36 @@ func NewAuthHandler(authService *auth.Service) *AuthHandler {
 
 type LoginRequest struct {
 	Username    string `json:"username" binding:"required"`
-	Password    string `json:"password" binding:"required"`
+	Password    string `json:"password"`
 	SchoolID    int    `json:"school_id" binding:"required"`
 	InstanceURL string `json:"instance_url" binding:"required"`
 	APIType     string `json:"api_type" binding:"required"` 
+	DeviceName  string `json:"device_name"`
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type ESIALoginRequest struct {
+	Login       string `json:"login" binding:"required"`
+	Password    string `json:"password" binding:"required"`
+	InstanceURL string `json:"instance_url" binding:"required"`
+	Account     string `json:"account"` 
+	DeviceName  string `json:"device_name"`
+}
+
+type DeviceLoginRequest struct {
+	SchoolID    int    `json:"school_id" binding:"required"`
+	InstanceURL string `json:"instance_url" binding:"required"`
+	APIType     string `json:"api_type"`
+	DeviceName  string `json:"device_name"`
 }
 
 
@@ -42,17 +70,185 @@ type LoginResponse struct {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, api_types.WithDefaultCode(err, api_types.ErrCodeBadRequest))
+		return
+	}
+
+	provider, ok := h.authService.Providers().Lookup(api_types.APIMode(req.APIType))
+	if !ok {
+		respondError(c, api_types.Errorf(api_types.ErrCodeBadRequest, "Unsupported api_type, see GET /providers"))
+		return
+	}
+
+	
+	if !provider.Login.Supports(api_types.LoginMethodPassword) {
+		h.startDeviceLogin(c, req.SchoolID, req.InstanceURL, req.APIType, req.DeviceName)
+		return
+	}
+
+	if provider.Login.RequiresPassword && req.Password == "" {
+		respondError(c, api_types.Errorf(api_types.ErrCodeBadRequest, "password is required"))
+		return
+	}
+
+	tokens, err := h.authService.LoginWithAPIType(c.Request.Context(), req.Username, req.Password, req.SchoolID, req.InstanceURL, req.APIType, deviceInfo(c, req.DeviceName))
+	if err != nil {
+		respondError(c, api_types.WithDefaultCode(err, api_types.ErrCodeAuthenticationFailed))
+		return
+	}
+
+	c.JSON(http.StatusOK, loginResponse(tokens))
+}
+
+
+
+
+
+
+
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, api_types.WithDefaultCode(err, api_types.ErrCodeBadRequest))
+		return
+	}
+
+	tokens, err := h.authService.RefreshTokens(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidRefreshToken) || errors.Is(err, auth.ErrRefreshTokenReused) {
+			respondError(c, api_types.WithDefaultCode(err, api_types.ErrCodeAuthenticationFailed))
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loginResponse(tokens))
+}
+
+
+
+
+
+
+
+
+
+
+func (h *AuthHandler) StartDeviceLogin(c *gin.Context) {
+	var req DeviceLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, api_types.WithDefaultCode(err, api_types.ErrCodeBadRequest))
+		return
+	}
+
+	if req.APIType == "" {
+		req.APIType = string(api_types.NSMobileAPI)
+	}
+	if _, ok := h.authService.Providers().Lookup(api_types.APIMode(req.APIType)); !ok {
+		respondError(c, api_types.Errorf(api_types.ErrCodeBadRequest, "Unsupported api_type, see GET /providers"))
+		return
+	}
+
+	h.startDeviceLogin(c, req.SchoolID, req.InstanceURL, req.APIType, req.DeviceName)
+}
+
+
+
+
+
+
+
+
+
+
+func (h *AuthHandler) GetDeviceLoginStatus(c *gin.Context) {
+	wait := time.Duration(0)
+	if waitStr := c.Query("wait"); waitStr != "" {
+		seconds, err := strconv.Atoi(waitStr)
+		if err != nil || seconds < 0 {
+			respondError(c, api_types.Errorf(api_types.ErrCodeBadRequest, "Invalid wait, use number of seconds"))
+			return
+		}
+		wait = time.Duration(seconds) * time.Second
+		if wait > maxDeviceLoginWait {
+			wait = maxDeviceLoginWait
+		}
+	}
+
+	login, err := h.authService.WaitDeviceLogin(c.Request.Context(), c.Param("login_id"), wait)
+	if err != nil {
+		if errors.Is(err, auth.ErrDeviceLoginNotFound) {
+			respondError(c, api_types.WithDefaultCode(err, api_types.ErrCodeNotFound))
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	switch login.Status {
+	case auth.DeviceLoginPending:
+		c.JSON(http.StatusAccepted, login)
+	case auth.DeviceLoginCompleted:
+		c.JSON(http.StatusOK, login)
+	default:
+		respondError(c, api_types.NewError(login.ErrorCode, nil).WithData(gin.H{"login_id": login.ID, "status": login.Status}))
+	}
+}
+
+
+
+
+
+
+
+
+func (h *AuthHandler) ESIALogin(c *gin.Context) {
+	var req ESIALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, api_types.WithDefaultCode(err, api_types.ErrCodeBadRequest))
+		return
+	}
+
+	credentials := api_types.ESIACredentials{
+		Login:    req.Login,
+		Password: req.Password,
+		Account:  req.Account,
+	}
+	tokens, err := h.authService.LoginWithESIA(c.Request.Context(), credentials, req.InstanceURL, deviceInfo(c, req.DeviceName))
+	if err != nil {
+		var selection *api_types.ESIAAccountSelectionError
+		switch {
+		case errors.As(err, &selection):
+			respondError(c, api_types.NewError(api_types.ErrCodeAccountSelectionRequired, err).WithData(gin.H{"accounts": selection.Accounts}))
+		case errors.Is(err, api_types.ErrESIAAccountNotFound):
+			respondError(c, err)
+		case errors.Is(err, api_types.ErrESIANotSupported):
+			respondError(c, api_types.WithDefaultCode(err, api_types.ErrCodeNotImplemented))
+		default:
+			respondError(c, api_types.WithDefaultCode(err, api_types.ErrCodeAuthenticationFailed))
+		}
 		return
 	}
 
-	token, err := h.authService.LoginWithAPIType(c.Request.Context(), req.Username, req.Password, req.SchoolID, req.InstanceURL, req.APIType)
+	c.JSON(http.StatusOK, loginResponse(tokens))
+}
+
+
+func (h *AuthHandler) startDeviceLogin(c *gin.Context, schoolID int, instanceURL, apiType, deviceName string) {
+	login, err := h.authService.StartDeviceLogin(c.Request.Context(), schoolID, instanceURL, apiType, deviceInfo(c, deviceName))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		if errors.Is(err, api_types.ErrDeviceFlowNotSupported) {
+			respondError(c, api_types.WithDefaultCode(err, api_types.ErrCodeBadRequest))
+			return
+		}
+		respondError(c, api_types.WithDefaultCode(err, api_types.ErrCodeNetSchoolAPIError))
 		return
 	}
 
-	c.JSON(http.StatusOK, LoginResponse{Token: token})
+	c.Header("Location", "/auth/login/device/"+login.ID)
+	c.JSON(http.StatusAccepted, login)
 }
 
 
@@ -65,17 +261,112 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 
 func (h *AuthHandler) Logout(c *gin.Context) {
-	
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+	userID, sessionID, ok := currentSession(c)
+	if !ok {
 		return
 	}
 
-	if err := h.authService.Logout(c.Request.Context(), userID.(string)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.authService.Logout(c.Request.Context(), userID, sessionID); err != nil && !errors.Is(err, auth.ErrSessionNotFound) {
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
-}
\ No newline at end of file
+}
+
+
+
+
+
+
+
+
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, _, ok := currentSession(c)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.LogoutAll(c.Request.Context(), userID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out from all devices"})
+}
+
+
+
+
+
+
+
+
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	userID, sessionID, ok := currentSession(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(c.Request.Context(), userID, sessionID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+
+
+
+
+
+
+
+
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, _, ok := currentSession(c)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.RevokeSession(c.Request.Context(), userID, c.Param("session_id")); err != nil {
+		if errors.Is(err, auth.ErrSessionNotFound) {
+			respondError(c, api_types.WithDefaultCode(err, api_types.ErrCodeNotFound))
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+
+func currentSession(c *gin.Context) (string, string, bool) {
+	userID, userExists := c.Get("userID")
+	sessionID, sessionExists := c.Get("sessionID")
+	if !userExists || !sessionExists {
+		respondError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "User not authenticated"))
+		return "", "", false
+	}
+	return userID.(string), sessionID.(string), true
+}
+
+
+func loginResponse(tokens *auth.TokenPair) LoginResponse {
+	return LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}
+}
+
+
+func deviceInfo(c *gin.Context, deviceName string) auth.DeviceInfo {
+	return auth.DeviceInfo{
+		Name:      deviceName,
+		UserAgent: c.Request.UserAgent(),
+	}
+}
@@ -0,0 +1,71 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/domain/calendar"
+)
+
+type CalendarHandler struct {
+	calendarService *calendar.Service
+}
+
+func NewCalendarHandler(calendarService *calendar.Service) *CalendarHandler {
+	return &CalendarHandler{calendarService: calendarService}
+}
+
+
+
+
+
+
+
+
+func (h *CalendarHandler) GetYears(c *gin.Context) {
+	sessionID, exists := c.Get("sessionID")
+	if !exists {
+		respondError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "User not authenticated"))
+		return
+	}
+
+	cal, err := h.calendarService.GetCalendar(c.Request.Context(), c.GetString("userID"), sessionID.(string))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"current": cal.Year,
+		"years":   cal.Years,
+		"classes": cal.Classes,
+	})
+}
+
+
+
+
+
+
+
+
+func (h *CalendarHandler) GetTerms(c *gin.Context) {
+	sessionID, exists := c.Get("sessionID")
+	if !exists {
+		respondError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "User not authenticated"))
+		return
+	}
+
+	cal, err := h.calendarService.GetCalendar(c.Request.Context(), c.GetString("userID"), sessionID.(string))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"year_id":         cal.Year.ID,
+		"current_term_id": cal.CurrentTermID,
+		"terms":           cal.Terms,
+	})
+}
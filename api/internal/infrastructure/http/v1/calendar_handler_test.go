@@ -0,0 +1,170 @@
+package v1_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/domain/auth"
+	"netschool-proxy/api/api/internal/domain/calendar"
+	"netschool-proxy/api/api/internal/domain/grade"
+	infraCache "netschool-proxy/api/api/internal/infrastructure/cache"
+	"netschool-proxy/api/api/internal/infrastructure/database"
+	"netschool-proxy/api/api/internal/infrastructure/http/v1"
+	"netschool-proxy/api/api/internal/infrastructure/http/v1/middleware"
+)
+
+type journalClient struct {
+	api_types.APIClientInterface
+	terms []api_types.Term
+
+	mu     sync.Mutex
+	period [2]time.Time
+}
+
+func (c *journalClient) GetTerms(ctx context.Context, userID, instanceURL string, yearID int) ([]api_types.Term, error) {
+	return c.terms, nil
+}
+
+func (c *journalClient) GetGrades(ctx context.Context, userID, studentID, instanceURL string, start, end time.Time) ([]api_types.Grade, error) {
+	c.mu.Lock()
+	c.period = [2]time.Time{start, end}
+	c.mu.Unlock()
+	return nil, nil
+}
+
+func (c *journalClient) requested() [2]time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.period
+}
+
+func newCalendarRouter(t *testing.T, terms []api_types.Term) (*gin.Engine, *journalClient) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "calendar.sqlite")), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&auth.NetSchoolSession{}, &auth.SessionStudent{}))
+
+	session := &auth.NetSchoolSession{
+		SessionID:    "session-1",
+		UserID:       "user-1",
+		StudentID:    "1001",
+		ExpiresAt:    time.Now().Add(time.Hour),
+		NetSchoolURL: "https://sgo.rso23.ru",
+		APIType:      string(api_types.DevMockAPI),
+	}
+	sessionRepo := database.NewSessionRepository(db)
+	require.NoError(t, sessionRepo.Create(context.Background(), session))
+
+	client := &journalClient{terms: terms}
+	mock, ok := api_types.DefaultRegistry.Lookup(api_types.DevMockAPI)
+	require.True(t, ok)
+	provider := *mock
+	provider.New = func(config api_types.APIConfig, httpClient *http.Client) (api_types.APIClientInterface, error) {
+		inner, err := mock.New(config, httpClient)
+		client.APIClientInterface = inner
+		return client, err
+	}
+	registry := api_types.NewProviderRegistry()
+	registry.MustRegister(provider)
+	factory, err := api_types.NewAPIClientFactory(registry, api_types.TransportConfig{})
+	require.NoError(t, err)
+
+	config := api_types.APIConfig{Mode: api_types.DevMockAPI}
+	cacheService := infraCache.NewMemoryCacheService(100)
+	calendarService := calendar.NewService(factory, sessionRepo, cacheService, config, nil)
+	calendarHandler := v1.NewCalendarHandler(calendarService)
+	gradeHandler := v1.NewGradeHandler(grade.NewService(factory, sessionRepo, cacheService, config, nil, calendarService))
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler(), func(c *gin.Context) {
+		c.Set("session", session)
+		c.Set("userID", session.UserID)
+		c.Set("sessionID", session.SessionID)
+		c.Set("instanceURL", session.NetSchoolURL)
+	})
+	router.GET("/years", calendarHandler.GetYears)
+	router.GET("/terms", calendarHandler.GetTerms)
+	router.GET("/journal", gradeHandler.GetJournal)
+	return router, client
+}
+
+func getJSON(t *testing.T, router *gin.Engine, target string, body interface{}) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	if rec.Code == http.StatusOK && body != nil {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), body))
+	}
+	return rec.Code
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func calendarTerms(now time.Time) []api_types.Term {
+	format := func(t time.Time) string { return day(t).Format("2006-01-02T15:04:05") }
+	return []api_types.Term{
+		{ID: 1, Name: "1 четверть", StartDate: format(now.AddDate(0, -3, 0)), EndDate: format(now.AddDate(0, -1, 0))},
+		{ID: 2, Name: "2 четверть", StartDate: format(now.AddDate(0, 0, -7)), EndDate: format(now.AddDate(0, 0, 7))},
+	}
+}
+
+func TestCalendarHandler_YearsAndTerms(t *testing.T) {
+	router, _ := newCalendarRouter(t, calendarTerms(time.Now()))
+
+	var years struct {
+		Current api_types.SchoolYear   `json:"current"`
+		Years   []api_types.SchoolYear `json:"years"`
+		Classes []api_types.Class      `json:"classes"`
+	}
+	require.Equal(t, http.StatusOK, getJSON(t, router, "/years", &years))
+	assert.Equal(t, 2025, years.Current.ID)
+	assert.Len(t, years.Years, 2)
+	assert.Len(t, years.Classes, 2)
+
+	var terms struct {
+		YearID        int              `json:"year_id"`
+		CurrentTermID int              `json:"current_term_id"`
+		Terms         []api_types.Term `json:"terms"`
+	}
+	require.Equal(t, http.StatusOK, getJSON(t, router, "/terms", &terms))
+	assert.Equal(t, 2025, terms.YearID)
+	assert.Equal(t, 2, terms.CurrentTermID)
+	assert.Len(t, terms.Terms, 2)
+}
+
+func TestGradeHandler_JournalDefaultsToCurrentTerm(t *testing.T) {
+	now := time.Now()
+	router, client := newCalendarRouter(t, calendarTerms(now))
+
+	require.Equal(t, http.StatusOK, getJSON(t, router, "/journal", nil))
+	period := client.requested()
+	assert.Equal(t, day(now.AddDate(0, 0, -7)), day(period[0]))
+	assert.Equal(t, day(now.AddDate(0, 0, 7)), day(period[1]))
+
+	require.Equal(t, http.StatusOK, getJSON(t, router, "/journal?start_date=2025-09-01&end_date=2025-09-30", nil))
+	period = client.requested()
+	assert.Equal(t, "2025-09-01", period[0].Format("2006-01-02"))
+	assert.Equal(t, "2025-09-30", period[1].Format("2006-01-02"))
+}
+
+func TestGradeHandler_JournalBetweenTerms(t *testing.T) {
+	router, _ := newCalendarRouter(t, calendarTerms(time.Now().AddDate(1, 0, 0)))
+
+	assert.Equal(t, http.StatusNotFound, getJSON(t, router, "/journal", nil))
+}
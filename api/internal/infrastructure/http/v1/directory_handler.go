@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/domain/directory"
+)
+
+type DirectoryHandler struct {
+	directoryService *directory.Service
+	registry         *api_types.ProviderRegistry
+}
+
+func NewDirectoryHandler(directoryService *directory.Service, registry *api_types.ProviderRegistry) *DirectoryHandler {
+	return &DirectoryHandler{
+		directoryService: directoryService,
+		registry:         registry,
+	}
+}
+
+
+
+
+
+
+
+func (h *DirectoryHandler) GetInstances(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, gin.H{"instances": h.directoryService.Instances()})
+}
+
+
+
+
+
+
+
+
+
+
+
+
+
+
+func (h *DirectoryHandler) GetSchools(c *gin.Context) {
+	instanceURL := c.Query("instance_url")
+	if instanceURL == "" {
+		respondError(c, api_types.Errorf(api_types.ErrCodeBadRequest, "instance_url is required"))
+		return
+	}
+
+	apiMode := api_types.APIMode(c.Query("api_type"))
+	if apiMode != "" {
+		if _, ok := h.registry.Lookup(apiMode); !ok {
+			respondError(c, api_types.Errorf(api_types.ErrCodeBadRequest, "Unsupported api_type, see GET /providers"))
+			return
+		}
+	}
+
+	var filter directory.Filter
+	for param, target := range map[string]*int{
+		"municipality_id": &filter.MunicipalityID,
+		"city_id":         &filter.CityID,
+		"school_type_id":  &filter.SchoolTypeID,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			respondError(c, api_types.Errorf(api_types.ErrCodeBadRequest, "Invalid %s", param))
+			return
+		}
+		*target = parsed
+	}
+
+	dir, err := h.directoryService.GetDirectory(c.Request.Context(), apiMode, instanceURL, filter)
+	if err != nil {
+		if errors.Is(err, api_types.ErrDirectoryNotSupported) {
+			respondError(c, api_types.WithDefaultCode(err, api_types.ErrCodeBadRequest))
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dir)
+}
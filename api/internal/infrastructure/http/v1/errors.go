@@ -0,0 +1,13 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+
+
+
+func respondError(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
@@ -1,11 +1,14 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/domain/calendar"
 	"netschool-proxy/api/api/internal/domain/grade"
 )
 
@@ -32,37 +35,77 @@ func NewGradeHandler(gradeService *grade.Service) *GradeHandler {
 
 func (h *GradeHandler) GetGradesForStudent(c *gin.Context) {
 	
-	studentID := c.Query("student_id")
-	if studentID == "" {
-		
-		userID, exists := c.Get("userID")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-			return
-		}
-		studentID = userID.(string)
+	studentID, ok := resolveStudentID(c)
+	if !ok {
+		return
 	}
 
-	userID, exists := c.Get("userID")
+	sessionID, exists := c.Get("sessionID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "User not authenticated"))
 		return
 	}
 
 	
-	instanceURL := c.Query("instance_url")
-	if instanceURL == "" {
-		
-		instanceURL = c.GetHeader("X-Instance-URL")
-		if instanceURL == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "instance_url is required"})
-			return
-		}
+	instanceURL := c.GetString("instanceURL")
+
+	
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	defaultStart, defaultEnd := grade.DefaultPeriod(time.Now())
+	if startDate.IsZero() {
+		startDate = defaultStart
+	}
+	if endDate.IsZero() {
+		endDate = defaultEnd
 	}
 
-	grades, err := h.gradeService.GetGradesForStudent(c.Request.Context(), userID.(string), studentID, instanceURL)
+	grades, err := h.gradeService.GetGradesForStudent(c.Request.Context(), c.GetString("userID"), sessionID.(string), studentID, instanceURL, startDate, endDate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, grades)
+}
+
+
+
+
+
+
+
+
+
+
+
+
+func (h *GradeHandler) GetJournal(c *gin.Context) {
+	studentID, ok := resolveStudentID(c)
+	if !ok {
+		return
+	}
+
+	sessionID, exists := c.Get("sessionID")
+	if !exists {
+		respondError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "User not authenticated"))
+		return
+	}
+
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	grades, err := h.gradeService.GetJournal(c.Request.Context(), c.GetString("userID"), sessionID.(string), studentID, c.GetString("instanceURL"), startDate, endDate)
+	if err != nil {
+		if errors.Is(err, calendar.ErrNoCurrentTerm) || errors.Is(err, calendar.ErrUnknownTerm) {
+			respondError(c, api_types.WithDefaultCode(err, api_types.ErrCodeBadRequest))
+			return
+		}
+		respondError(c, err)
 		return
 	}
 
@@ -91,68 +134,44 @@ func (h *GradeHandler) GetGradesForStudent(c *gin.Context) {
 func (h *GradeHandler) GetGradesForSubject(c *gin.Context) {
 	subjectID := c.Query("subject_id")
 	if subjectID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "subject_id is required"})
+		respondError(c, api_types.Errorf(api_types.ErrCodeBadRequest, "subject_id is required"))
 		return
 	}
 
 	
-	studentID := c.Query("student_id")
-	if studentID == "" {
-		
-		userID, exists := c.Get("userID")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-			return
-		}
-		studentID = userID.(string)
+	studentID, ok := resolveStudentID(c)
+	if !ok {
+		return
 	}
 
-	userID, exists := c.Get("userID")
+	sessionID, exists := c.Get("sessionID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "User not authenticated"))
 		return
 	}
 
 	
-	startDateStr := c.Query("start_date")
-	endDateStr := c.Query("end_date")
-
-	
-	if startDateStr == "" {
-		startDateStr = time.Now().Format("2006-01-02")
-	}
-	if endDateStr == "" {
-		endDateStr = time.Now().AddDate(0, 0, 7).Format("2006-01-02")
-	}
-
-	startDate, err := time.Parse("2006-01-02", startDateStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format, use YYYY-MM-DD"})
-		return
-	}
-
-	endDate, err := time.Parse("2006-01-02", endDateStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format, use YYYY-MM-DD"})
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
 		return
 	}
 
 	
-	termID := 1
+	termID := 0
 	if termIDStr := c.Query("term_id"); termIDStr != "" {
 		parsed, err := strconv.ParseInt(termIDStr, 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid term_id"})
+			respondError(c, api_types.Errorf(api_types.ErrCodeBadRequest, "Invalid term_id"))
 			return
 		}
 		termID = int(parsed)
 	}
 
-	classID := 1
+	classID := 0
 	if classIDStr := c.Query("class_id"); classIDStr != "" {
 		parsed, err := strconv.ParseInt(classIDStr, 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class_id"})
+			respondError(c, api_types.Errorf(api_types.ErrCodeBadRequest, "Invalid class_id"))
 			return
 		}
 		classID = int(parsed)
@@ -163,7 +182,7 @@ func (h *GradeHandler) GetGradesForSubject(c *gin.Context) {
 	if transportStr := c.Query("transport"); transportStr != "" {
 		parsed, err := strconv.ParseInt(transportStr, 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transport"})
+			respondError(c, api_types.Errorf(api_types.ErrCodeBadRequest, "Invalid transport"))
 			return
 		}
 		transportVal := int(parsed)
@@ -171,21 +190,40 @@ func (h *GradeHandler) GetGradesForSubject(c *gin.Context) {
 	}
 
 	
-	instanceURL := c.Query("instance_url")
-	if instanceURL == "" {
-		
-		instanceURL = c.GetHeader("X-Instance-URL")
-		if instanceURL == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "instance_url is required"})
-			return
-		}
-	}
+	instanceURL := c.GetString("instanceURL")
 
-	grades, err := h.gradeService.GetGradesForSubject(c.Request.Context(), userID.(string), studentID, subjectID, instanceURL, startDate, endDate, termID, classID, transport)
+	grades, err := h.gradeService.GetGradesForSubject(c.Request.Context(), c.GetString("userID"), sessionID.(string), studentID, subjectID, instanceURL, startDate, endDate, termID, classID, transport)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if errors.Is(err, calendar.ErrNoCurrentTerm) || errors.Is(err, calendar.ErrUnknownTerm) {
+			respondError(c, api_types.WithDefaultCode(err, api_types.ErrCodeBadRequest))
+			return
+		}
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, grades)
-}
\ No newline at end of file
+}
+
+
+
+func parseDateRange(c *gin.Context) (time.Time, time.Time, bool) {
+	var startDate, endDate time.Time
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		parsed, err := time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			respondError(c, api_types.Errorf(api_types.ErrCodeBadRequest, "Invalid start date format, use YYYY-MM-DD"))
+			return time.Time{}, time.Time{}, false
+		}
+		startDate = parsed
+	}
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		parsed, err := time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			respondError(c, api_types.Errorf(api_types.ErrCodeBadRequest, "Invalid end date format, use YYYY-MM-DD"))
+			return time.Time{}, time.Time{}, false
+		}
+		endDate = parsed
+	}
+	return startDate, endDate, true
+}
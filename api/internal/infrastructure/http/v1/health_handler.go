@@ -14,12 +14,18 @@ import (
 type HealthHandler struct {
 	apiClient   api_types.APIClientInterface
 	sessionRepo auth.SessionRepository
+	breakers    *api_types.BreakerRegistry
+	coalescer   *api_types.Coalescer
+	browserStatus func() string
 }
 
-func NewHealthHandler(apiClient api_types.APIClientInterface, sessionRepo auth.SessionRepository) *HealthHandler {
+func NewHealthHandler(apiClient api_types.APIClientInterface, sessionRepo auth.SessionRepository, breakers *api_types.BreakerRegistry, coalescer *api_types.Coalescer, browserStatus func() string) *HealthHandler {
 	return &HealthHandler{
 		apiClient:   apiClient,
 		sessionRepo: sessionRepo,
+		breakers:    breakers,
+		coalescer:   coalescer,
+		browserStatus: browserStatus,
 	}
 }
 
@@ -72,7 +78,7 @@ func (h *HealthHandler) IntPing(c *gin.Context) {
 		
 		instanceURL = c.GetHeader("X-Instance-URL")
 		if instanceURL == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "instance_url is required"})
+			respondError(c, api_types.Errorf(api_types.ErrCodeBadRequest, "instance_url is required"))
 			return
 		}
 	}
@@ -119,7 +125,7 @@ func (h *HealthHandler) FullHealth(c *gin.Context) {
 		
 		instanceURL = c.GetHeader("X-Instance-URL")
 		if instanceURL == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "instance_url is required"})
+			respondError(c, api_types.Errorf(api_types.ErrCodeBadRequest, "instance_url is required"))
 			return
 		}
 	}
@@ -131,10 +137,28 @@ func (h *HealthHandler) FullHealth(c *gin.Context) {
 
 	
 	dbStart := time.Now()
-	_, dbErr := h.sessionRepo.GetByUserID(ctx, "health_check") 
+	_, dbErr := h.sessionRepo.GetBySessionID(ctx, "health_check") 
 	dbDuration := time.Since(dbStart).Milliseconds()
 
 	
+	var breakers []api_types.BreakerStatus
+	openBreakers := 0
+	if h.breakers != nil {
+		breakers = h.breakers.States()
+		for _, breaker := range breakers {
+			if breaker.State != api_types.CircuitClosed {
+				openBreakers++
+			}
+		}
+	}
+
+	
+	browserStatus := auth.BrowserLoginDisabled
+	if h.browserStatus != nil {
+		browserStatus = h.browserStatus()
+	}
+
+	
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
 
@@ -156,6 +180,10 @@ func (h *HealthHandler) FullHealth(c *gin.Context) {
 				"response_time":  dbDuration,
 				"last_checked":   time.Now().UTC().Format(time.RFC3339),
 			},
+			"circuit_breakers": breakers,
+			"browser_auth": gin.H{
+				"status": browserStatus,
+			},
 		},
 		"metrics": gin.H{
 			"goroutines": runtime.NumGoroutine(),
@@ -167,7 +195,12 @@ func (h *HealthHandler) FullHealth(c *gin.Context) {
 	}
 
 	
-	if apiErr != nil || dbErr != nil {
+	if h.coalescer != nil {
+		response["metrics"].(gin.H)["netschool_calls"] = h.coalescer.Stats()
+	}
+
+	
+	if apiErr != nil || dbErr != nil || openBreakers > 0 {
 		response["status"] = "warning"
 		if apiErr != nil {
 			response["api_error"] = apiErr.Error()
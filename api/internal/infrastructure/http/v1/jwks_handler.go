@@ -0,0 +1,28 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"netschool-proxy/api/api/internal/pkg/security"
+)
+
+type JWKSHandler struct {
+	jwtService *security.JWTService
+}
+
+func NewJWKSHandler(jwtService *security.JWTService) *JWKSHandler {
+	return &JWKSHandler{jwtService: jwtService}
+}
+
+
+
+
+
+
+
+
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwtService.JWKS())
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"netschool-proxy/api/api/internal/api_types"
+)
+
+
+
+type AdminMiddleware struct {
+	userIDs map[string]struct{}
+}
+
+func NewAdminMiddleware(userIDs []string) *AdminMiddleware {
+	allowed := make(map[string]struct{}, len(userIDs))
+	for _, id := range userIDs {
+		if id != "" {
+			allowed[id] = struct{}{}
+		}
+	}
+	return &AdminMiddleware{userIDs: allowed}
+}
+
+func (m *AdminMiddleware) AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("userID")
+		if !exists {
+			abortWithError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "User not authenticated"))
+			return
+		}
+
+		if _, ok := m.userIDs[userID.(string)]; !ok {
+			abortWithError(c, api_types.Errorf(api_types.ErrCodeForbidden, "Admin access required"))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"netschool-proxy/api/api/internal/api_types"
 	"netschool-proxy/api/api/internal/domain/auth"
 	"netschool-proxy/api/api/internal/pkg/security"
 )
@@ -24,40 +25,29 @@ func (m *AuthMiddleware) AuthRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(401, gin.H{"error": "Authorization header required"})
-			c.Abort()
+			abortWithError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "Authorization header required"))
 			return
 		}
 
 		
 		authParts := strings.SplitN(authHeader, " ", 2)
 		if len(authParts) != 2 || authParts[0] != "Bearer" {
-			c.JSON(401, gin.H{"error": "Invalid authorization header format"})
-			c.Abort()
+			abortWithError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "Invalid authorization header format"))
 			return
 		}
 
 		tokenString := authParts[1]
 
 		
-		claims, err := m.authService.ValidateToken(c.Request.Context(), tokenString)
+		claims, session, err := m.authService.ValidateTokenWithSession(c.Request.Context(), tokenString)
 		if err != nil {
-			c.JSON(401, gin.H{"error": "Invalid or expired token"})
-			c.Abort()
-			return
-		}
-
-		
-		session, err := m.authService.GetSessionByUserID(c.Request.Context(), claims.UserID)
-		if err != nil {
-			c.JSON(401, gin.H{"error": "Session not found"})
-			c.Abort()
+			abortWithError(c, api_types.WithDefaultCode(err, api_types.ErrCodeUnauthorized))
 			return
 		}
 
 		
 		c.Set("userID", claims.UserID)
-		c.Set("sessionID", claims.SessionID)
+		c.Set("sessionID", session.SessionID)
 		c.Set("schoolID", claims.SchoolID)
 		c.Set("session", session) 
 
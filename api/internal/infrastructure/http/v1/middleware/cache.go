@@ -5,13 +5,29 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"netschool-proxy/api/api/internal/domain/cache"
+	"netschool-proxy/api/api/internal/pkg/logger"
 )
 
 
+const responseCacheSegment = "response:"
+
+const (
+	cacheStatusHeader = "X-Cache"
+	dataAsOfHeader    = "X-Data-As-Of"
+)
+
+
+
+var DefaultVaryHeaders = []string{"X-Instance-URL"}
+
+
 type ResponseBodyWriter struct {
 	gin.ResponseWriter
 	body *bytes.Buffer
@@ -22,107 +38,211 @@ func (r *ResponseBodyWriter) Write(b []byte) (int, error) {
 	return r.ResponseWriter.Write(b)
 }
 
+
+func (r *ResponseBodyWriter) WriteHeader(code int) {
+	if code != http.StatusOK {
+		r.Header().Set("Cache-Control", "no-store")
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+
+type cachedResponse struct {
+	ContentType string          `json:"content_type"`
+	Body        json.RawMessage `json:"body"`
+	DataAsOf    time.Time       `json:"data_as_of"`
+}
+
 type CacheMiddleware struct {
-	cache cache.CacheStrategy
+	cache       cache.CacheStrategy
+	varyHeaders []string
 }
 
-func NewCacheMiddleware(cache cache.CacheStrategy) *CacheMiddleware {
-	return &CacheMiddleware{cache: cache}
+
+
+func NewCacheMiddleware(cache cache.CacheStrategy, varyHeaders ...string) *CacheMiddleware {
+	if len(varyHeaders) == 0 {
+		varyHeaders = DefaultVaryHeaders
+	}
+	return &CacheMiddleware{cache: cache, varyHeaders: varyHeaders}
 }
 
 
 func (cm *CacheMiddleware) CacheResponse(ttl time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		
-		cacheKey := generateCacheKey(c)
-
-		
-		var cachedResponse map[string]interface{}
-		found, err := cm.cache.Get(c.Request.Context(), cacheKey, &cachedResponse)
-		if err == nil && found {
-			
-			c.JSON(200, cachedResponse)
-			c.Abort()
+		cm.serve(c, ttl)
+	}
+}
+
+
+func (cm *CacheMiddleware) CacheMiddlewareWithCondition(ttl time.Duration, conditionFunc func(*gin.Context) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !conditionFunc(c) {
+			c.Next()
 			return
 		}
+		cm.serve(c, ttl)
+	}
+}
+
 
-		
-		bodyWriter := &ResponseBodyWriter{
-			ResponseWriter: c.Writer,
-			body:          &bytes.Buffer{},
-		}
-		c.Writer = bodyWriter
 
-		
+
+func (cm *CacheMiddleware) serve(c *gin.Context, ttl time.Duration) {
+	cacheKey, ok := cm.generateCacheKey(c)
+	if !ok {
+		c.Header("Cache-Control", "no-store")
 		c.Next()
+		return
+	}
 
-		
-		if c.Writer.Status() == 200 && bodyWriter.body.Len() > 0 {
-			
-			var responseBody interface{}
-			if err := json.Unmarshal(bodyWriter.body.Bytes(), &responseBody); err == nil {
-				
-				go func() {
-					ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-					defer cancel()
-					
-					cm.cache.Set(ctx, cacheKey, responseBody, ttl)
-				}()
-			}
-		}
+	cm.setCacheHeaders(c, ttl)
+
+
+	var cached cachedResponse
+	found, err := cm.cache.Get(c.Request.Context(), cacheKey, &cached)
+	if err == nil && found && len(cached.Body) > 0 {
+		setCacheStatusHeaders(c.Writer.Header(), cache.Result{Status: cache.FreshnessHit, AsOf: cached.DataAsOf})
+		c.Data(http.StatusOK, cached.ContentType, cached.Body)
+		c.Abort()
+		return
+	}
+
+
+	bodyWriter := &ResponseBodyWriter{
+		ResponseWriter: c.Writer,
+		body:           &bytes.Buffer{},
+	}
+	c.Writer = bodyWriter
+
+	c.Next()
+
+
+	contentType := c.Writer.Header().Get("Content-Type")
+	if c.Writer.Status() != http.StatusOK || bodyWriter.body.Len() == 0 || !strings.Contains(contentType, "json") {
+		return
+	}
+	if !json.Valid(bodyWriter.body.Bytes()) {
+		return
+	}
+
+	
+	if c.Writer.Header().Get(cacheStatusHeader) == string(cache.FreshnessStale) {
+		return
+	}
+	dataAsOf, err := time.Parse(time.RFC3339, c.Writer.Header().Get(dataAsOfHeader))
+	if err != nil {
+		dataAsOf = time.Now()
 	}
+
+	response := cachedResponse{ContentType: contentType, Body: bodyWriter.body.Bytes(), DataAsOf: dataAsOf}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		cm.cache.Set(ctx, cacheKey, response, ttl)
+	}()
+}
+
+func (cm *CacheMiddleware) setCacheHeaders(c *gin.Context, ttl time.Duration) {
+	vary := append([]string{"Authorization"}, cm.varyHeaders...)
+	c.Header("Vary", strings.Join(vary, ", "))
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(ttl.Seconds())))
 }
 
 
-func generateCacheKey(c *gin.Context) string {
-	return fmt.Sprintf("%s:%s?%s", c.Request.Method, c.Request.URL.Path, c.Request.URL.RawQuery)
+
+
+func (cm *CacheMiddleware) generateCacheKey(c *gin.Context) (string, bool) {
+	userID := c.GetString("userID")
+	sessionID := c.GetString("sessionID")
+	if userID == "" || sessionID == "" {
+		return "", false
+	}
+
+	var key strings.Builder
+	key.WriteString(cache.SessionKeyPrefix(userID, sessionID))
+	key.WriteString(responseCacheSegment)
+	key.WriteString(c.Request.Method)
+	key.WriteString(":")
+	key.WriteString(c.Request.URL.Path)
+	key.WriteString("?")
+	key.WriteString(c.Request.URL.Query().Encode())
+	for _, header := range cm.varyHeaders {
+		key.WriteString("|")
+		key.WriteString(http.CanonicalHeaderKey(header))
+		key.WriteString("=")
+		key.WriteString(url.QueryEscape(c.GetHeader(header)))
+	}
+	return key.String(), true
 }
 
 
-func (cm *CacheMiddleware) CacheMiddlewareWithCondition(ttl time.Duration, conditionFunc func(*gin.Context) bool) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		
-		if !conditionFunc(c) {
-			c.Next()
-			return
-		}
+func (cm *CacheMiddleware) PurgeSession(ctx context.Context, userID, sessionID string) error {
+	return cm.purge(ctx, cache.SessionKeyPrefix(userID, sessionID))
+}
 
-		
-		cacheKey := generateCacheKey(c)
 
-		
-		var cachedResponse map[string]interface{}
-		found, err := cm.cache.Get(c.Request.Context(), cacheKey, &cachedResponse)
-		if err == nil && found {
-			
-			c.JSON(200, cachedResponse)
-			c.Abort()
-			return
-		}
+func (cm *CacheMiddleware) PurgeUser(ctx context.Context, userID string) error {
+	return cm.purge(ctx, cache.UserKeyPrefix(userID))
+}
 
-		
-		bodyWriter := &ResponseBodyWriter{
-			ResponseWriter: c.Writer,
-			body:          &bytes.Buffer{},
-		}
-		c.Writer = bodyWriter
+func (cm *CacheMiddleware) purge(ctx context.Context, prefix string) error {
+	deleter, ok := cm.cache.(cache.PrefixDeleter)
+	if !ok {
+		logger.Warn("Response cache backend cannot delete by prefix, entries expire by TTL", "prefix", prefix)
+		return nil
+	}
+	return deleter.DeletePrefix(ctx, prefix)
+}
 
-		
+
+
+
+func CacheStatus() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, recorder := cache.WithStatusRecorder(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = &cacheStatusWriter{ResponseWriter: c.Writer, recorder: recorder}
 		c.Next()
+	}
+}
 
-		
-		if c.Writer.Status() == 200 && bodyWriter.body.Len() > 0 {
-			
-			var responseBody interface{}
-			if err := json.Unmarshal(bodyWriter.body.Bytes(), &responseBody); err == nil {
-				
-				go func() {
-					ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-					defer cancel()
-					
-					cm.cache.Set(ctx, cacheKey, responseBody, ttl)
-				}()
-			}
-		}
+
+type cacheStatusWriter struct {
+	gin.ResponseWriter
+	recorder *cache.StatusRecorder
+	applied  bool
+}
+
+func (w *cacheStatusWriter) WriteHeader(code int) {
+	w.apply()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *cacheStatusWriter) Write(b []byte) (int, error) {
+	w.apply()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *cacheStatusWriter) WriteString(s string) (int, error) {
+	w.apply()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *cacheStatusWriter) apply() {
+	if w.applied {
+		return
 	}
-}
\ No newline at end of file
+	w.applied = true
+	if result, ok := w.recorder.Result(); ok && w.Header().Get(cacheStatusHeader) == "" {
+		setCacheStatusHeaders(w.Header(), result)
+	}
+}
+
+func setCacheStatusHeaders(header http.Header, result cache.Result) {
+	header.Set(cacheStatusHeader, string(result.Status))
+	if !result.AsOf.IsZero() {
+		header.Set(dataAsOfHeader, result.AsOf.UTC().Format(time.RFC3339))
+	}
+}
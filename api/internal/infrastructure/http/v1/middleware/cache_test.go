@@ -0,0 +1,129 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/domain/cache"
+	infraCache "netschool-proxy/api/api/internal/infrastructure/cache"
+	"netschool-proxy/api/api/internal/infrastructure/http/v1/middleware"
+)
+
+func newCachedRouter(cacheMiddleware *middleware.CacheMiddleware, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/me", func(c *gin.Context) {
+		c.Set("userID", c.GetHeader("X-Test-User"))
+		c.Set("sessionID", c.GetHeader("X-Test-Session"))
+		c.Next()
+	}, cacheMiddleware.CacheResponse(time.Minute), func(c *gin.Context) {
+		*calls++
+		c.JSON(http.StatusOK, gin.H{"user": c.GetString("userID"), "instance": c.GetHeader("X-Instance-URL")})
+	})
+	return router
+}
+
+func get(router *gin.Engine, user, session, instance string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/me?b=2&a=1", nil)
+	req.Header.Set("X-Test-User", user)
+	req.Header.Set("X-Test-Session", session)
+	req.Header.Set("X-Instance-URL", instance)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCacheMiddleware_ScopesEntriesToSession(t *testing.T) {
+	store := infraCache.NewMemoryCacheService(100)
+	cacheMiddleware := middleware.NewCacheMiddleware(store)
+	calls := 0
+	router := newCachedRouter(cacheMiddleware, &calls)
+
+	first := get(router, "alice", "s1", "https://a.example")
+	assert.JSONEq(t, `{"user": "alice", "instance": "https://a.example"}`, first.Body.String())
+	assert.Equal(t, "Authorization, X-Instance-URL", first.Header().Get("Vary"))
+	assert.Equal(t, "private, max-age=60", first.Header().Get("Cache-Control"))
+	require.Eventually(t, func() bool { return store.Size() == 1 }, time.Second, 5*time.Millisecond)
+
+	cached := get(router, "alice", "s1", "https://a.example")
+	assert.JSONEq(t, first.Body.String(), cached.Body.String())
+	assert.Equal(t, "HIT", cached.Header().Get("X-Cache"))
+	assert.NotEmpty(t, cached.Header().Get("X-Data-As-Of"))
+	assert.Equal(t, 1, calls)
+
+	other := get(router, "bob", "s2", "https://a.example")
+	assert.JSONEq(t, `{"user": "bob", "instance": "https://a.example"}`, other.Body.String())
+	assert.Equal(t, 2, calls)
+
+	otherInstance := get(router, "alice", "s1", "https://b.example")
+	assert.JSONEq(t, `{"user": "alice", "instance": "https://b.example"}`, otherInstance.Body.String())
+	assert.Equal(t, 3, calls)
+}
+
+func TestCacheMiddleware_SkipsAnonymousRequests(t *testing.T) {
+	store := infraCache.NewMemoryCacheService(100)
+	calls := 0
+	router := newCachedRouter(middleware.NewCacheMiddleware(store), &calls)
+
+	rec := get(router, "", "", "https://a.example")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	get(router, "", "", "https://a.example")
+	assert.Equal(t, 2, calls)
+}
+
+func TestCacheMiddleware_PurgeUser(t *testing.T) {
+	store := infraCache.NewMemoryCacheService(100)
+	cacheMiddleware := middleware.NewCacheMiddleware(store)
+	calls := 0
+	router := newCachedRouter(cacheMiddleware, &calls)
+
+	get(router, "alice", "s1", "https://a.example")
+	get(router, "alice", "s2", "https://a.example")
+	get(router, "bob", "s3", "https://a.example")
+	require.Eventually(t, func() bool { return store.Size() == 3 }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, cacheMiddleware.PurgeSession(context.Background(), "alice", "s1"))
+	assert.Equal(t, 2, store.Size())
+
+	require.NoError(t, cacheMiddleware.PurgeUser(context.Background(), "alice"))
+	assert.Equal(t, 1, store.Size())
+
+	get(router, "bob", "s3", "https://a.example")
+	assert.Equal(t, 3, calls)
+}
+
+func TestCacheStatus_ReportsStaleData(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := infraCache.NewMemoryCacheService(100)
+	policy := cache.NewPolicyCache(store)
+	storedAt := time.Now().Add(-3 * time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, store.Set(context.Background(), "diary", map[string]interface{}{
+		"value":     json.RawMessage(`"cached"`),
+		"stored_at": storedAt,
+	}, time.Hour))
+
+	router := gin.New()
+	router.GET("/diary", middleware.CacheStatus(), func(c *gin.Context) {
+		var diary string
+		_, err := policy.Fetch(c.Request.Context(), "diary", cache.Policy{FreshFor: time.Minute, ServeStaleFor: 24 * time.Hour}, &diary,
+			func(ctx context.Context) (interface{}, error) {
+				return nil, context.DeadlineExceeded
+			})
+		require.NoError(t, err)
+		c.JSON(http.StatusOK, gin.H{"diary": diary})
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/diary", nil))
+	assert.JSONEq(t, `{"diary": "cached"}`, rec.Body.String())
+	assert.Equal(t, "STALE", rec.Header().Get("X-Cache"))
+	assert.Equal(t, storedAt.Format(time.RFC3339), rec.Header().Get("X-Data-As-Of"))
+}
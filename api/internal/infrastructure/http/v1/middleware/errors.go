@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/pkg/logger"
+	"netschool-proxy/api/api/pkg/types"
+)
+
+
+const RequestIDHeader = "X-Request-ID"
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+
+
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+		c.Set("requestID", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
+
+
+
+
+
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logger.Error("Panic while handling request", "request_id", c.GetString("requestID"), "path", c.Request.URL.Path, "panic", recovered)
+				if !c.Writer.Written() {
+					abortWithError(c, api_types.Errorf(api_types.ErrCodeInternalError, "panic: %v", recovered))
+				}
+			}
+		}()
+
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		abortWithError(c, c.Errors.Last().Err)
+	}
+}
+
+
+
+
+func ErrorResponse(c *gin.Context, err error) (int, types.Response) {
+	info := api_types.GetErrorInfo(api_types.ErrorCodeOf(err))
+	response := types.Response{
+		Status:     "error",
+		Error:      info.Description,
+		Code:       int(info.Code),
+		PrettyName: info.PrettyName,
+		RequestID:  c.GetString("requestID"),
+	}
+
+	var coded *api_types.Error
+	if errors.As(err, &coded) {
+		response.Data = coded.Data
+		if info.Code == api_types.ErrCodeBadRequest || info.Code == api_types.ErrCodeDataValidationError {
+			response.Message = coded.Error()
+		}
+	}
+	return info.HTTPStatus, response
+}
+
+func abortWithError(c *gin.Context, err error) {
+	status, response := ErrorResponse(c, err)
+	if status >= 500 {
+		logger.Error("Request failed",
+			"request_id", response.RequestID,
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"code", response.Code,
+			"error", err)
+	}
+	c.AbortWithStatusJSON(status, response)
+}
@@ -0,0 +1,101 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/infrastructure/http/v1/middleware"
+	"netschool-proxy/api/api/pkg/types"
+)
+
+func newErrorRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestID(), middleware.ErrorHandler())
+	router.GET("/not-found", func(c *gin.Context) {
+		c.Error(api_types.Errorf(api_types.ErrCodeNotFound, "student 42 not found"))
+	})
+	router.GET("/bad-request", func(c *gin.Context) {
+		c.Error(api_types.Errorf(api_types.ErrCodeBadRequest, "invalid date format"))
+	})
+	router.GET("/internal", func(c *gin.Context) {
+		c.Error(errors.New("dial tcp 10.0.0.5:5432: connection refused"))
+	})
+	router.GET("/sentinel", func(c *gin.Context) {
+		c.Error(fmt.Errorf("login: %w", api_types.ErrCircuitOpen))
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	return router
+}
+
+func serveError(t *testing.T, router *gin.Engine, req *http.Request) (*httptest.ResponseRecorder, types.Response) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var response types.Response
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	return rec, response
+}
+
+func TestErrorHandler_RendersEnvelope(t *testing.T) {
+	router := newErrorRouter()
+
+	rec, response := serveError(t, router, httptest.NewRequest(http.MethodGet, "/not-found", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "error", response.Status)
+	assert.Equal(t, int(api_types.ErrCodeNotFound), response.Code)
+	assert.Equal(t, "Not Found", response.PrettyName)
+	assert.Equal(t, "Ресурс не найден", response.Error)
+	assert.Empty(t, response.Message)
+	assert.NotEmpty(t, response.RequestID)
+	assert.Equal(t, response.RequestID, rec.Header().Get(middleware.RequestIDHeader))
+
+	rec, response = serveError(t, router, httptest.NewRequest(http.MethodGet, "/bad-request", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "invalid date format", response.Message)
+
+	rec, response = serveError(t, router, httptest.NewRequest(http.MethodGet, "/sentinel", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, int(api_types.ErrCodeNetSchoolTemporarilyUnavailable), response.Code)
+}
+
+func TestErrorHandler_HidesInternalDetails(t *testing.T) {
+	router := newErrorRouter()
+
+	rec, response := serveError(t, router, httptest.NewRequest(http.MethodGet, "/internal", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, int(api_types.ErrCodeInternalError), response.Code)
+	assert.NotContains(t, rec.Body.String(), "10.0.0.5")
+
+	rec, response = serveError(t, router, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, int(api_types.ErrCodeInternalError), response.Code)
+	assert.NotContains(t, rec.Body.String(), "boom")
+}
+
+func TestRequestID_EchoesValidHeader(t *testing.T) {
+	router := newErrorRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/not-found", nil)
+	req.Header.Set(middleware.RequestIDHeader, "client-req.1")
+	rec, response := serveError(t, router, req)
+	assert.Equal(t, "client-req.1", response.RequestID)
+	assert.Equal(t, "client-req.1", rec.Header().Get(middleware.RequestIDHeader))
+
+	req = httptest.NewRequest(http.MethodGet, "/not-found", nil)
+	req.Header.Set(middleware.RequestIDHeader, "bad id\r\nX-Injected: 1")
+	_, response = serveError(t, router, req)
+	assert.NotEqual(t, "bad id\r\nX-Injected: 1", response.RequestID)
+	assert.Len(t, response.RequestID, 32)
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/domain/auth"
+)
+
+
+
+
+
+func InstanceGuard(allowlist *api_types.InstanceAllowlist) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		if rawURL := query.Get("instance_url"); rawURL != "" {
+			instanceURL, err := allowlist.Check(rawURL)
+			if err != nil {
+				abortWithError(c, err)
+				return
+			}
+			query.Set("instance_url", instanceURL)
+			c.Request.URL.RawQuery = query.Encode()
+		}
+
+		if rawURL := c.GetHeader("X-Instance-URL"); rawURL != "" {
+			instanceURL, err := allowlist.Check(rawURL)
+			if err != nil {
+				abortWithError(c, err)
+				return
+			}
+			c.Request.Header.Set("X-Instance-URL", instanceURL)
+		}
+
+		c.Next()
+	}
+}
+
+
+
+
+
+
+
+func SessionInstance() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("session")
+		session, ok := value.(*auth.NetSchoolSession)
+		if !ok || session == nil {
+			abortWithError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "Session required"))
+			return
+		}
+
+		sessionURL, err := api_types.NormalizeInstanceURL(session.NetSchoolURL)
+		if err != nil {
+			sessionURL = strings.TrimRight(session.NetSchoolURL, "/")
+		}
+
+		query := c.Request.URL.Query()
+		for _, requested := range []string{query.Get("instance_url"), c.GetHeader("X-Instance-URL")} {
+			if requested == "" {
+				continue
+			}
+			instanceURL, err := api_types.NormalizeInstanceURL(requested)
+			if err == nil && instanceURL != sessionURL {
+				err = fmt.Errorf("%w: %s", api_types.ErrInstanceMismatch, instanceURL)
+			}
+			if err != nil {
+				abortWithError(c, err)
+				return
+			}
+		}
+
+		if query.Has("instance_url") {
+			query.Set("instance_url", sessionURL)
+			c.Request.URL.RawQuery = query.Encode()
+		}
+		c.Request.Header.Set("X-Instance-URL", sessionURL)
+		c.Set("instanceURL", sessionURL)
+		c.Next()
+	}
+}
@@ -0,0 +1,96 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/domain/auth"
+	"netschool-proxy/api/api/internal/infrastructure/http/v1/middleware"
+)
+
+func newGuardedRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	allowlist, err := api_types.NewInstanceAllowlist([]string{"https://sgo.rso23.ru"}, false)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(middleware.InstanceGuard(allowlist))
+	router.GET("/health/intping", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"query": c.Query("instance_url"), "header": c.GetHeader("X-Instance-URL")})
+	})
+	return router
+}
+
+func TestInstanceGuard_NormalizesAllowedInstance(t *testing.T) {
+	router := newGuardedRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/health/intping?instance_url=HTTPS://sgo.rso23.ru/", nil)
+	req.Header.Set("X-Instance-URL", "sgo.rso23.ru")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"query": "https://sgo.rso23.ru", "header": "https://sgo.rso23.ru"}`, rec.Body.String())
+}
+
+func TestInstanceGuard_RejectsUnknownAndInternalInstances(t *testing.T) {
+	router := newGuardedRouter(t)
+
+	for _, target := range []string{
+		"/health/intping?instance_url=http://169.254.169.254",
+		"/health/intping?instance_url=https://evil.example",
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusForbidden, rec.Code, target)
+		assert.Contains(t, rec.Body.String(), `"code":4005`, target)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/health/intping", nil)
+	req.Header.Set("X-Instance-URL", "file:///etc/passwd")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":4006`)
+}
+
+func newSessionRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/grades", func(c *gin.Context) {
+		c.Set("session", &auth.NetSchoolSession{SessionID: "s1", NetSchoolURL: "https://sgo.rso23.ru/"})
+		c.Next()
+	}, middleware.SessionInstance(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"instance": c.GetString("instanceURL"), "header": c.GetHeader("X-Instance-URL")})
+	})
+	return router
+}
+
+func TestSessionInstance_DefaultsToSessionInstance(t *testing.T) {
+	router := newSessionRouter()
+
+	for _, target := range []string{"/api/v1/grades", "/api/v1/grades?instance_url=SGO.rso23.ru"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, target)
+		assert.JSONEq(t, `{"instance": "https://sgo.rso23.ru", "header": "https://sgo.rso23.ru"}`, rec.Body.String(), target)
+	}
+}
+
+func TestSessionInstance_RejectsMismatch(t *testing.T) {
+	router := newSessionRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/grades", nil)
+	req.Header.Set("X-Instance-URL", "https://attacker.example")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":4007`)
+}
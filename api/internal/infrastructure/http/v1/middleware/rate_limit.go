@@ -1,12 +1,12 @@
 package middleware
 
 import (
-	"net/http"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
+	"netschool-proxy/api/api/internal/api_types"
 )
 
 
@@ -48,11 +48,7 @@ func (rl *RateLimiter) RateLimitMiddleware() gin.HandlerFunc {
 		limiter := rl.GetLimiter(ip)
 
 		if !limiter.Allow() {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "Rate limit exceeded",
-				"message": "Too many requests, please try again later",
-			})
-			c.Abort()
+			abortWithError(c, api_types.Errorf(api_types.ErrCodeTooManyRequests, "Rate limit exceeded"))
 			return
 		}
 
@@ -65,22 +61,14 @@ func (rl *RateLimiter) RateLimitMiddlewareWithKey(keyFunc func(*gin.Context) str
 	return func(c *gin.Context) {
 		key := keyFunc(c)
 		if key == "" {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Internal server error",
-				"message": "Could not determine rate limit key",
-			})
-			c.Abort()
+			abortWithError(c, api_types.Errorf(api_types.ErrCodeInternalError, "could not determine rate limit key"))
 			return
 		}
 
 		limiter := rl.GetLimiter(key)
 
 		if !limiter.Allow() {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "Rate limit exceeded",
-				"message": "Too many requests, please try again later",
-			})
-			c.Abort()
+			abortWithError(c, api_types.Errorf(api_types.ErrCodeTooManyRequests, "Rate limit exceeded"))
 			return
 		}
 
@@ -174,20 +162,12 @@ func (tb *TokenBucketRateLimiter) TokenBucketRateLimitMiddleware(keyFunc func(*g
 	return func(c *gin.Context) {
 		key := keyFunc(c)
 		if key == "" {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Internal server error",
-				"message": "Could not determine rate limit key",
-			})
-			c.Abort()
+			abortWithError(c, api_types.Errorf(api_types.ErrCodeInternalError, "could not determine rate limit key"))
 			return
 		}
 
 		if !tb.Allow(key) {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "Rate limit exceeded",
-				"message": "Too many requests, please try again later",
-			})
-			c.Abort()
+			abortWithError(c, api_types.Errorf(api_types.ErrCodeTooManyRequests, "Rate limit exceeded"))
 			return
 		}
 
@@ -0,0 +1,29 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"netschool-proxy/api/api/internal/api_types"
+)
+
+type ProviderHandler struct {
+	registry *api_types.ProviderRegistry
+}
+
+func NewProviderHandler(registry *api_types.ProviderRegistry) *ProviderHandler {
+	return &ProviderHandler{registry: registry}
+}
+
+
+
+
+
+
+
+
+
+func (h *ProviderHandler) GetProviders(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"providers": h.registry.Providers()})
+}
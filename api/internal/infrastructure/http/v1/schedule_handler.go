@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"netschool-proxy/api/api/internal/api_types"
 	"netschool-proxy/api/api/internal/domain/schedule"
 	"netschool-proxy/api/api/internal/domain/student"
 )
@@ -64,35 +65,32 @@ func (h *ScheduleHandler) GetWeeklySchedule(c *gin.Context) {
 	if weekStartStr != "" {
 		weekStart, err = time.Parse("2006-01-02", weekStartStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, use YYYY-MM-DD"})
+			respondError(c, api_types.Errorf(api_types.ErrCodeBadRequest, "Invalid date format, use YYYY-MM-DD"))
 			return
 		}
 	} else {
 		
-		now := time.Now()
-		weekStart = now.AddDate(0, 0, -int(now.Weekday())+1) 
+		weekStart = schedule.WeekStart(time.Now())
 	}
 
 	
-	userID, exists := c.Get("userID")
+	sessionID, exists := c.Get("sessionID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "User not authenticated"))
 		return
 	}
 
-	instanceURL := c.Query("instance_url")
-	if instanceURL == "" {
-		instanceURL = c.GetHeader("X-Instance-URL")
-		if instanceURL == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "instance_url is required"})
-			return
-		}
+	studentID, ok := resolveStudentID(c)
+	if !ok {
+		return
 	}
 
+	instanceURL := c.GetString("instanceURL")
+
 	
-	scheduleData, err := h.scheduleService.GetWeeklySchedule(c.Request.Context(), userID.(string), instanceURL, weekStart)
+	scheduleData, err := h.scheduleService.GetWeeklySchedule(c.Request.Context(), c.GetString("userID"), sessionID.(string), studentID, instanceURL, weekStart)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -120,7 +118,7 @@ func (h *ScheduleHandler) GetDailySchedule(c *gin.Context) {
 	if dateStr != "" {
 		date, err = time.Parse("2006-01-02", dateStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, use YYYY-MM-DD"})
+			respondError(c, api_types.Errorf(api_types.ErrCodeBadRequest, "Invalid date format, use YYYY-MM-DD"))
 			return
 		}
 	} else {
@@ -129,25 +127,23 @@ func (h *ScheduleHandler) GetDailySchedule(c *gin.Context) {
 	}
 
 	
-	userID, exists := c.Get("userID")
+	sessionID, exists := c.Get("sessionID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "User not authenticated"))
 		return
 	}
 
-	instanceURL := c.Query("instance_url")
-	if instanceURL == "" {
-		instanceURL = c.GetHeader("X-Instance-URL")
-		if instanceURL == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "instance_url is required"})
-			return
-		}
+	studentID, ok := resolveStudentID(c)
+	if !ok {
+		return
 	}
 
+	instanceURL := c.GetString("instanceURL")
+
 	
-	scheduleData, err := h.scheduleService.GetDailySchedule(c.Request.Context(), userID.(string), instanceURL, date)
+	scheduleData, err := h.scheduleService.GetDailySchedule(c.Request.Context(), c.GetString("userID"), sessionID.(string), studentID, instanceURL, date)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"netschool-proxy/api/api/internal/api_types"
 	"netschool-proxy/api/api/internal/domain/student"
 )
 
@@ -49,25 +50,18 @@ type ClassInfo struct {
 
 func (h *SchoolHandler) GetSchoolInfo(c *gin.Context) {
 	
-	userID, exists := c.Get("userID")
+	sessionID, exists := c.Get("sessionID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "User not authenticated"))
 		return
 	}
 
-	instanceURL := c.Query("instance_url")
-	if instanceURL == "" {
-		instanceURL = c.GetHeader("X-Instance-URL")
-		if instanceURL == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "instance_url is required"})
-			return
-		}
-	}
+	instanceURL := c.GetString("instanceURL")
 
 	
-	schoolInfo, err := h.studentService.GetSchoolInfo(c.Request.Context(), userID.(string), instanceURL)
+	schoolInfo, err := h.studentService.GetSchoolInfo(c.Request.Context(), sessionID.(string), instanceURL)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -86,25 +80,18 @@ func (h *SchoolHandler) GetSchoolInfo(c *gin.Context) {
 
 func (h *SchoolHandler) GetClasses(c *gin.Context) {
 	
-	userID, exists := c.Get("userID")
+	sessionID, exists := c.Get("sessionID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "User not authenticated"))
 		return
 	}
 
-	instanceURL := c.Query("instance_url")
-	if instanceURL == "" {
-		instanceURL = c.GetHeader("X-Instance-URL")
-		if instanceURL == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "instance_url is required"})
-			return
-		}
-	}
+	instanceURL := c.GetString("instanceURL")
 
 	
-	classes, err := h.studentService.GetClasses(c.Request.Context(), userID.(string), instanceURL)
+	classes, err := h.studentService.GetClasses(c.Request.Context(), sessionID.(string), instanceURL)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -1,9 +1,11 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"netschool-proxy/api/api/internal/api_types"
 	"netschool-proxy/api/api/internal/domain/student"
 )
 
@@ -25,28 +27,42 @@ func NewStudentHandler(studentService *student.Service) *StudentHandler {
 
 
 
+func (h *StudentHandler) ListStudents(c *gin.Context) {
+	sessionID, exists := c.Get("sessionID")
+	if !exists {
+		respondError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "User not authenticated"))
+		return
+	}
+
+	students, err := h.studentService.GetLinkedStudents(c.Request.Context(), sessionID.(string))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"students": students})
+}
+
+
 func (h *StudentHandler) GetStudentInfo(c *gin.Context) {
 	
-	userID, exists := c.Get("userID")
+	sessionID, exists := c.Get("sessionID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "User not authenticated"))
 		return
 	}
 
-	
-	instanceURL := c.Query("instance_url")
-	if instanceURL == "" {
-		
-		instanceURL = c.GetHeader("X-Instance-URL")
-		if instanceURL == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "instance_url is required"})
-			return
-		}
+	studentID, ok := resolveStudentID(c)
+	if !ok {
+		return
 	}
 
-	student, err := h.studentService.GetStudentInfo(c.Request.Context(), userID.(string), instanceURL)
+	
+	instanceURL := c.GetString("instanceURL")
+
+	student, err := h.studentService.GetStudentInfo(c.Request.Context(), sessionID.(string), studentID, instanceURL)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -69,31 +85,27 @@ func (h *StudentHandler) GetStudentInfo(c *gin.Context) {
 func (h *StudentHandler) GetStudentsByClass(c *gin.Context) {
 	classID := c.Query("class_id")
 	if classID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "class_id is required"})
+		respondError(c, api_types.Errorf(api_types.ErrCodeBadRequest, "class_id is required"))
 		return
 	}
 
 	
-	userID, exists := c.Get("userID")
+	sessionID, exists := c.Get("sessionID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "User not authenticated"))
 		return
 	}
 
 	
-	instanceURL := c.Query("instance_url")
-	if instanceURL == "" {
-		
-		instanceURL = c.GetHeader("X-Instance-URL")
-		if instanceURL == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "instance_url is required"})
-			return
-		}
-	}
+	instanceURL := c.GetString("instanceURL")
 
-	students, err := h.studentService.GetStudentsByClass(c.Request.Context(), userID.(string), classID, instanceURL)
+	students, err := h.studentService.GetStudentsByClass(c.Request.Context(), sessionID.(string), classID, instanceURL)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if errors.Is(err, student.ErrClassRosterNotSupported) {
+			respondError(c, api_types.WithDefaultCode(err, api_types.ErrCodeNotImplemented))
+			return
+		}
+		respondError(c, err)
 		return
 	}
 
@@ -115,37 +127,23 @@ func (h *StudentHandler) GetStudentsByClass(c *gin.Context) {
 
 func (h *StudentHandler) GetStudentPhoto(c *gin.Context) {
 	
-	studentID := c.Query("student_id")
-	if studentID == "" {
-		
-		userID, exists := c.Get("userID")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-			return
-		}
-		studentID = userID.(string)
+	studentID, ok := resolveStudentID(c)
+	if !ok {
+		return
 	}
 
-	userID, exists := c.Get("userID")
+	sessionID, exists := c.Get("sessionID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "User not authenticated"))
 		return
 	}
 
 	
-	instanceURL := c.Query("instance_url")
-	if instanceURL == "" {
-		
-		instanceURL = c.GetHeader("X-Instance-URL")
-		if instanceURL == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "instance_url is required"})
-			return
-		}
-	}
+	instanceURL := c.GetString("instanceURL")
 
-	photo, err := h.studentService.GetStudentPhoto(c.Request.Context(), userID.(string), studentID, instanceURL)
+	photo, err := h.studentService.GetStudentPhoto(c.Request.Context(), sessionID.(string), studentID, instanceURL)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -0,0 +1,92 @@
+package v1_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/domain/auth"
+	"netschool-proxy/api/api/internal/domain/student"
+	"netschool-proxy/api/api/internal/infrastructure/database"
+	"netschool-proxy/api/api/internal/infrastructure/http/v1"
+	"netschool-proxy/api/api/internal/infrastructure/http/v1/middleware"
+)
+
+func newStudentRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "students.sqlite")), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&auth.NetSchoolSession{}, &auth.SessionStudent{}))
+
+	session := &auth.NetSchoolSession{
+		SessionID:    "session-1",
+		UserID:       "user-1",
+		SchoolID:     7,
+		StudentID:    "1001",
+		ExpiresAt:    time.Now().Add(time.Hour),
+		NetSchoolURL: "https://sgo.rso23.ru",
+		APIType:      string(api_types.DevMockAPI),
+		Students: []auth.SessionStudent{
+			{StudentID: "1001", Name: "Анна Иванова", ClassName: "9А"},
+			{StudentID: "1002", Name: "Борис Иванов", ClassName: "5Б"},
+		},
+	}
+	sessionRepo := database.NewSessionRepository(db)
+	require.NoError(t, sessionRepo.Create(context.Background(), session))
+
+	factory, err := api_types.NewAPIClientFactory(api_types.DefaultRegistry, api_types.TransportConfig{})
+	require.NoError(t, err)
+	handler := v1.NewStudentHandler(student.NewService(factory, sessionRepo, api_types.APIConfig{Mode: api_types.DevMockAPI}, nil))
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler(), func(c *gin.Context) {
+		c.Set("session", session)
+		c.Set("sessionID", session.SessionID)
+		c.Set("instanceURL", session.NetSchoolURL)
+	})
+	router.GET("/students/me", handler.GetStudentInfo)
+	return router
+}
+
+func getStudent(t *testing.T, router *gin.Engine, target string) (int, student.Student) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+	var info student.Student
+	if rec.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &info))
+	}
+	return rec.Code, info
+}
+
+func TestStudentHandler_GetStudentInfoSelectsChild(t *testing.T) {
+	router := newStudentRouter(t)
+
+	status, info := getStudent(t, router, "/students/me")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "1001", info.ID)
+	assert.Equal(t, "9А", info.Class)
+
+	status, info = getStudent(t, router, "/students/me?student_id=1002")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "1002", info.ID)
+	assert.Equal(t, "Борис Иванов", info.Name)
+	assert.Equal(t, "5Б", info.Class)
+	assert.Equal(t, 7, info.SchoolID)
+
+	status, _ = getStudent(t, router, "/students/me?student_id=2002")
+	assert.Equal(t, http.StatusForbidden, status)
+}
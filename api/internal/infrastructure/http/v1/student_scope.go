@@ -0,0 +1,33 @@
+package v1
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"netschool-proxy/api/api/internal/api_types"
+	"netschool-proxy/api/api/internal/domain/auth"
+)
+
+
+
+
+func resolveStudentID(c *gin.Context) (string, bool) {
+	value, _ := c.Get("session")
+	session, ok := value.(*auth.NetSchoolSession)
+	if !ok || session == nil {
+		respondError(c, api_types.Errorf(api_types.ErrCodeUnauthorized, "User not authenticated"))
+		return "", false
+	}
+
+	studentID, err := session.ResolveStudentID(c.Query("student_id"))
+	if err != nil {
+		if errors.Is(err, auth.ErrStudentNotLinked) {
+			respondError(c, api_types.WithDefaultCode(err, api_types.ErrCodeForbidden))
+			return "", false
+		}
+		respondError(c, err)
+		return "", false
+	}
+
+	return studentID, true
+}
@@ -1,15 +1,37 @@
 package security
 
 import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
 	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+var (
+	ErrUnknownSigningKey = errors.New("unknown signing key")
+	ErrRetiredSigningKey = errors.New("signing key is retired")
+)
+
 type JWTService struct {
 	secretKey []byte
 	expiresIn time.Duration
+
+	
+	signingKey   *JWTKey
+	keys         map[string]*JWTKey
+	retiredGrace time.Duration
 }
 
 type Claims struct {
@@ -19,6 +41,32 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
+
+
+type JWTKey struct {
+	ID         string
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+	RetiredAt  time.Time
+}
+
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
 func NewJWTService(secret string, expiresIn time.Duration) *JWTService {
 	return &JWTService{
 		secretKey: []byte(secret),
@@ -26,9 +74,65 @@ func NewJWTService(secret string, expiresIn time.Duration) *JWTService {
 	}
 }
 
+
+
+
+
+func NewKeySetJWTService(keys []*JWTKey, signingKeyID string, retiredGrace, expiresIn time.Duration, legacySecret string) (*JWTService, error) {
+	s := &JWTService{
+		expiresIn:    expiresIn,
+		keys:         make(map[string]*JWTKey, len(keys)),
+		retiredGrace: retiredGrace,
+	}
+	if legacySecret != "" {
+		s.secretKey = []byte(legacySecret)
+	}
+
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, errors.New("jwt key id is required")
+		}
+		if _, exists := s.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate jwt key id %s", key.ID)
+		}
+		if _, err := signingMethod(key.PublicKey); err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", key.ID, err)
+		}
+		s.keys[key.ID] = key
+	}
+
+	for _, key := range keys {
+		if key.PrivateKey == nil || !key.RetiredAt.IsZero() {
+			continue
+		}
+		if signingKeyID == "" || key.ID == signingKeyID {
+			s.signingKey = key
+			break
+		}
+	}
+	if s.signingKey == nil {
+		if signingKeyID != "" {
+			return nil, fmt.Errorf("%w: %s has no private key or is retired", ErrUnknownSigningKey, signingKeyID)
+		}
+		return nil, fmt.Errorf("%w: no active key with a private key", ErrUnknownSigningKey)
+	}
+
+	return s, nil
+}
+
+
+func (s *JWTService) ExpiresIn() time.Duration {
+	return s.expiresIn
+}
+
 func (s *JWTService) GenerateToken(userID, sessionID string, schoolID int) (string, error) {
 	expiresAt := time.Now().Add(s.expiresIn)
 
+	tokenID, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+
 	claims := &Claims{
 		UserID:    userID,
 		SessionID: sessionID,
@@ -39,20 +143,26 @@ func (s *JWTService) GenerateToken(userID, sessionID string, schoolID int) (stri
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "netschool-proxy/api",
 			Subject:   userID,
+			ID:        tokenID,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.secretKey)
+	if s.signingKey == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString(s.secretKey)
+	}
+
+	method, err := signingMethod(s.signingKey.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = s.signingKey.ID
+	return token.SignedString(s.signingKey.PrivateKey)
 }
 
 func (s *JWTService) ParseToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return s.secretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, s.verificationKey)
 
 	if err != nil {
 		return nil, err
@@ -63,4 +173,180 @@ func (s *JWTService) ParseToken(tokenString string) (*Claims, error) {
 	}
 
 	return nil, errors.New("invalid token claims")
-}
\ No newline at end of file
+}
+
+func (s *JWTService) verificationKey(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if len(s.secretKey) == 0 {
+			return nil, errors.New("unexpected signing method")
+		}
+		return s.secretKey, nil
+	}
+
+	if s.keys == nil {
+		return nil, errors.New("unexpected signing method")
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownSigningKey, kid)
+	}
+	if !s.keyUsable(key, time.Now()) {
+		return nil, fmt.Errorf("%w: %s", ErrRetiredSigningKey, kid)
+	}
+
+	method, err := signingMethod(key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+
+	return key.PublicKey, nil
+}
+
+
+func (s *JWTService) keyUsable(key *JWTKey, now time.Time) bool {
+	return key.RetiredAt.IsZero() || now.Before(key.RetiredAt.Add(s.retiredGrace))
+}
+
+
+
+func (s *JWTService) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	now := time.Now()
+
+	for _, key := range s.keys {
+		if !s.keyUsable(key, now) {
+			continue
+		}
+
+		switch publicKey := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: jwt.SigningMethodRS256.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: jwt.SigningMethodEdDSA.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(publicKey),
+			})
+		}
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+
+
+
+func LoadJWTKey(id, privateKeyFile, publicKeyFile string, retiredAt time.Time) (*JWTKey, error) {
+	key := &JWTKey{ID: id, RetiredAt: retiredAt}
+
+	switch {
+	case privateKeyFile != "":
+		data, err := os.ReadFile(privateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key: %w", err)
+		}
+		privateKey, err := ParsePrivateKeyPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		key.PrivateKey = privateKey
+		key.PublicKey = privateKey.Public()
+	case publicKeyFile != "":
+		data, err := os.ReadFile(publicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read public key: %w", err)
+		}
+		publicKey, err := ParsePublicKeyPEM(data)
+		if err != nil {
+			return nil, err
+		}
+		key.PublicKey = publicKey
+	default:
+		return nil, fmt.Errorf("jwt key %s: private_key_file or public_key_file is required", id)
+	}
+
+	return key, nil
+}
+
+
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found in private key")
+	}
+
+	if rsaKey, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return rsaKey, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+	if _, err := signingMethod(signer.Public()); err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found in public key")
+	}
+
+	if rsaKey, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return rsaKey, nil
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	if _, err := signingMethod(publicKey); err != nil {
+		return nil, err
+	}
+	return publicKey, nil
+}
+
+
+func signingMethod(publicKey crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", publicKey)
+	}
+}
+
+
+func newTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -1,10 +1,17 @@
 package security_test
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"netschool-proxy/api/api/internal/pkg/security"
 )
 
@@ -29,6 +36,23 @@ func TestJWTService_GenerateAndParseToken(t *testing.T) {
 	assert.Equal(t, schoolID, parsedClaims.SchoolID)
 }
 
+func TestJWTService_UniqueTokenID(t *testing.T) {
+	jwtService := security.NewJWTService("test_secret", time.Hour)
+
+	first, err := jwtService.GenerateToken("test_user", "test_session", 123)
+	require.NoError(t, err)
+	second, err := jwtService.GenerateToken("test_user", "test_session", 123)
+	require.NoError(t, err)
+
+	firstClaims, err := jwtService.ParseToken(first)
+	require.NoError(t, err)
+	secondClaims, err := jwtService.ParseToken(second)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, firstClaims.ID)
+	assert.NotEqual(t, firstClaims.ID, secondClaims.ID)
+}
+
 func TestJWTService_InvalidToken(t *testing.T) {
 	secret := "test_secret"
 	differentSecret := "different_secret"
@@ -67,4 +91,108 @@ func TestJWTService_ExpiredToken(t *testing.T) {
 	
 	_, err = jwtService.ParseToken(token)
 	assert.Error(t, err)
-}
\ No newline at end of file
+}
+func newRSAKey(t *testing.T, id string) *security.JWTKey {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return &security.JWTKey{ID: id, PrivateKey: privateKey, PublicKey: privateKey.Public()}
+}
+
+func newEd25519Key(t *testing.T, id string) *security.JWTKey {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return &security.JWTKey{ID: id, PrivateKey: privateKey, PublicKey: publicKey}
+}
+
+func TestJWTService_KeySetSigning(t *testing.T) {
+	for _, key := range []*security.JWTKey{newRSAKey(t, "rsa-1"), newEd25519Key(t, "ed-1")} {
+		jwtService, err := security.NewKeySetJWTService([]*security.JWTKey{key}, key.ID, time.Hour, time.Hour, "")
+		require.NoError(t, err)
+
+		token, err := jwtService.GenerateToken("test_user", "test_session", 123)
+		require.NoError(t, err)
+
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, &security.Claims{})
+		require.NoError(t, err)
+		assert.Equal(t, key.ID, parsed.Header["kid"])
+
+		claims, err := jwtService.ParseToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, "test_user", claims.UserID)
+	}
+}
+
+func TestJWTService_KeyRotation(t *testing.T) {
+	oldKey := newRSAKey(t, "2024-03")
+	newKey := newEd25519Key(t, "2024-09")
+
+	before, err := security.NewKeySetJWTService([]*security.JWTKey{oldKey}, "", time.Hour, time.Hour, "")
+	require.NoError(t, err)
+	oldToken, err := before.GenerateToken("test_user", "test_session", 123)
+	require.NoError(t, err)
+
+	
+	oldKey.RetiredAt = time.Now()
+	rotated, err := security.NewKeySetJWTService([]*security.JWTKey{oldKey, newKey}, "", time.Hour, time.Hour, "")
+	require.NoError(t, err)
+
+	_, err = rotated.ParseToken(oldToken)
+	assert.NoError(t, err)
+
+	newToken, err := rotated.GenerateToken("test_user", "test_session", 123)
+	require.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &security.Claims{})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-09", parsed.Header["kid"])
+
+	assert.Len(t, rotated.JWKS().Keys, 2)
+
+	
+	oldKey.RetiredAt = time.Now().Add(-2 * time.Hour)
+	_, err = rotated.ParseToken(oldToken)
+	assert.ErrorIs(t, err, security.ErrRetiredSigningKey)
+
+	jwks := rotated.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "2024-09", jwks.Keys[0].Kid)
+	assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+	assert.Equal(t, "EdDSA", jwks.Keys[0].Alg)
+}
+
+func TestJWTService_LegacyHS256(t *testing.T) {
+	legacy := security.NewJWTService("test_secret", time.Hour)
+	legacyToken, err := legacy.GenerateToken("test_user", "test_session", 123)
+	require.NoError(t, err)
+
+	key := newRSAKey(t, "rsa-1")
+
+	strict, err := security.NewKeySetJWTService([]*security.JWTKey{key}, "", time.Hour, time.Hour, "")
+	require.NoError(t, err)
+	_, err = strict.ParseToken(legacyToken)
+	assert.Error(t, err)
+
+	compatible, err := security.NewKeySetJWTService([]*security.JWTKey{key}, "", time.Hour, time.Hour, "test_secret")
+	require.NoError(t, err)
+	_, err = compatible.ParseToken(legacyToken)
+	assert.NoError(t, err)
+
+	
+	rsaToken, err := compatible.GenerateToken("test_user", "test_session", 123)
+	require.NoError(t, err)
+	_, err = legacy.ParseToken(rsaToken)
+	assert.Error(t, err)
+}
+
+func TestParsePrivateKeyPEM(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	signer, err := security.ParsePrivateKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	require.NoError(t, err)
+	assert.Equal(t, privateKey.Public(), signer.Public())
+
+	_, err = security.ParsePrivateKeyPEM([]byte("not a pem"))
+	assert.Error(t, err)
+}
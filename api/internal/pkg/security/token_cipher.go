@@ -0,0 +1,249 @@
+package security
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+
+
+const encryptedPrefix = "enc:v1:"
+
+const dataKeySize = 32
+
+var (
+	ErrUnknownKeyID     = errors.New("unknown encryption key id")
+	ErrMalformedCipher  = errors.New("malformed encrypted value")
+	ErrInvalidKeyLength = errors.New("encryption key must be 32 bytes")
+)
+
+
+
+
+type TokenCipher struct {
+	activeKeyID string
+	keys        map[string]cipher.AEAD
+}
+
+
+func NewTokenCipher(activeKeyID string, keys map[string][]byte) (*TokenCipher, error) {
+	if activeKeyID == "" {
+		return nil, errors.New("active key id is required")
+	}
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKeyID, activeKeyID)
+	}
+
+	aeads := make(map[string]cipher.AEAD, len(keys))
+	for keyID, key := range keys {
+		if keyID == "" || strings.Contains(keyID, ":") {
+			return nil, fmt.Errorf("invalid key id %q", keyID)
+		}
+		if len(key) != dataKeySize {
+			return nil, fmt.Errorf("%w: key %s", ErrInvalidKeyLength, keyID)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		aeads[keyID] = aead
+	}
+
+	return &TokenCipher{activeKeyID: activeKeyID, keys: aeads}, nil
+}
+
+
+func (c *TokenCipher) ActiveKeyID() string {
+	return c.activeKeyID
+}
+
+
+
+func (c *TokenCipher) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	
+	wrappedKey, err := seal(c.keys[c.activeKeyID], dataKey, []byte(c.activeKeyID))
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(dataAEAD, []byte(plaintext), wrappedKey)
+	if err != nil {
+		return "", err
+	}
+
+	return encryptedPrefix + c.activeKeyID + ":" +
+		base64.RawURLEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.RawURLEncoding.EncodeToString(ciphertext), nil
+}
+
+
+
+func (c *TokenCipher) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	keyID, wrappedKey, ciphertext, err := splitEncrypted(value)
+	if err != nil {
+		return "", err
+	}
+
+	keyAEAD, ok := c.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKeyID, keyID)
+	}
+
+	dataKey, err := open(keyAEAD, wrappedKey, []byte(keyID))
+	if err != nil {
+		return "", fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := open(dataAEAD, ciphertext, wrappedKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+
+
+func (c *TokenCipher) NeedsReencrypt(value string) bool {
+	if value == "" {
+		return false
+	}
+	if !IsEncrypted(value) {
+		return true
+	}
+	keyID, _, _, err := splitEncrypted(value)
+	return err != nil || keyID != c.activeKeyID
+}
+
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+
+
+
+func LoadKeys(encoded map[string]string, keyFile string) (map[string][]byte, error) {
+	keys := make(map[string][]byte, len(encoded))
+
+	if keyFile != "" {
+		file, err := os.Open(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open key file: %w", err)
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			keyID, value, found := strings.Cut(line, "=")
+			if !found {
+				return nil, fmt.Errorf("invalid key file line %q, expected <key_id>=<base64 key>", line)
+			}
+			key, err := decodeKey(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("key %s: %w", strings.TrimSpace(keyID), err)
+			}
+			keys[strings.TrimSpace(keyID)] = key
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read key file: %w", err)
+		}
+	}
+
+	for keyID, value := range encoded {
+		key, err := decodeKey(value)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", keyID, err)
+		}
+		keys[keyID] = key
+	}
+
+	return keys, nil
+}
+
+func decodeKey(value string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+	if len(key) != dataKeySize {
+		return nil, ErrInvalidKeyLength
+	}
+	return key, nil
+}
+
+func splitEncrypted(value string) (string, []byte, []byte, error) {
+	parts := strings.Split(strings.TrimPrefix(value, encryptedPrefix), ":")
+	if len(parts) != 3 {
+		return "", nil, nil, ErrMalformedCipher
+	}
+
+	wrappedKey, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", nil, nil, ErrMalformedCipher
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, nil, ErrMalformedCipher
+	}
+
+	return parts[0], wrappedKey, ciphertext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformedCipher
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
@@ -0,0 +1,111 @@
+package security_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"netschool-proxy/api/api/internal/pkg/security"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func TestTokenCipher_EncryptDecrypt(t *testing.T) {
+	tokenCipher, err := security.NewTokenCipher("k1", map[string][]byte{"k1": testKey(1)})
+	require.NoError(t, err)
+
+	encrypted, err := tokenCipher.Encrypt("netschool-access-token")
+	require.NoError(t, err)
+	assert.True(t, security.IsEncrypted(encrypted))
+	assert.True(t, strings.HasPrefix(encrypted, "enc:v1:k1:"))
+	assert.NotContains(t, encrypted, "netschool-access-token")
+
+	again, err := tokenCipher.Encrypt("netschool-access-token")
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, again)
+
+	decrypted, err := tokenCipher.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "netschool-access-token", decrypted)
+
+	
+	plain, err := tokenCipher.Decrypt("legacy-plaintext")
+	require.NoError(t, err)
+	assert.Equal(t, "legacy-plaintext", plain)
+
+	empty, err := tokenCipher.Encrypt("")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestTokenCipher_Rotation(t *testing.T) {
+	oldCipher, err := security.NewTokenCipher("k1", map[string][]byte{"k1": testKey(1)})
+	require.NoError(t, err)
+	encrypted, err := oldCipher.Encrypt("token")
+	require.NoError(t, err)
+
+	rotated, err := security.NewTokenCipher("k2", map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+	require.NoError(t, err)
+
+	assert.True(t, rotated.NeedsReencrypt(encrypted))
+	assert.True(t, rotated.NeedsReencrypt("plaintext"))
+	assert.False(t, rotated.NeedsReencrypt(""))
+
+	decrypted, err := rotated.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "token", decrypted)
+
+	reencrypted, err := rotated.Encrypt(decrypted)
+	require.NoError(t, err)
+	assert.False(t, rotated.NeedsReencrypt(reencrypted))
+
+	
+	newOnly, err := security.NewTokenCipher("k2", map[string][]byte{"k2": testKey(2)})
+	require.NoError(t, err)
+	_, err = newOnly.Decrypt(encrypted)
+	assert.ErrorIs(t, err, security.ErrUnknownKeyID)
+}
+
+func TestTokenCipher_Tampering(t *testing.T) {
+	tokenCipher, err := security.NewTokenCipher("k1", map[string][]byte{"k1": testKey(1)})
+	require.NoError(t, err)
+
+	encrypted, err := tokenCipher.Encrypt("token")
+	require.NoError(t, err)
+
+	parts := strings.Split(encrypted, ":")
+	payload, err := base64.RawURLEncoding.DecodeString(parts[len(parts)-1])
+	require.NoError(t, err)
+	payload[len(payload)-1] ^= 0xff
+	parts[len(parts)-1] = base64.RawURLEncoding.EncodeToString(payload)
+
+	_, err = tokenCipher.Decrypt(strings.Join(parts, ":"))
+	assert.Error(t, err)
+
+	_, err = tokenCipher.Decrypt("enc:v1:k1:broken")
+	assert.ErrorIs(t, err, security.ErrMalformedCipher)
+}
+
+func TestLoadKeys(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "keys")
+	content := "# rotated 2024-09\nk1=" + base64.StdEncoding.EncodeToString(testKey(1)) + "\n\n"
+	require.NoError(t, os.WriteFile(keyFile, []byte(content), 0o600))
+
+	keys, err := security.LoadKeys(map[string]string{"k2": base64.StdEncoding.EncodeToString(testKey(2))}, keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, testKey(1), keys["k1"])
+	assert.Equal(t, testKey(2), keys["k2"])
+
+	_, err = security.LoadKeys(map[string]string{"short": base64.StdEncoding.EncodeToString([]byte("short"))}, "")
+	assert.ErrorIs(t, err, security.ErrInvalidKeyLength)
+
+	_, err = security.NewTokenCipher("missing", keys)
+	assert.ErrorIs(t, err, security.ErrUnknownKeyID)
+}
@@ -3,10 +3,13 @@ package types
 
 
 type Response struct {
-	Status  string      `json:"status"`
-	Data    interface{} `json:"data,omitempty"`
-	Message string      `json:"message,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Status     string      `json:"status"`
+	Data       interface{} `json:"data,omitempty"`
+	Message    string      `json:"message,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	Code       int         `json:"code,omitempty"`
+	PrettyName string      `json:"pretty_name,omitempty"`
+	RequestID  string      `json:"request_id,omitempty"`
 }
 
 type Pagination struct {
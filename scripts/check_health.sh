@@ -28,4 +28,4 @@ echo "Количество файлов Go в проекте:"
 find . -name "*.go" | wc -l
 echo ""
 echo "Количество миграций:"
-ls -la migrations/ | grep ".sql" | wc -l
+ls -la api/internal/infrastructure/database/migrations/postgres/ | grep ".sql" | wc -l